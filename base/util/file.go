@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"github.com/coreos/butane/config/common"
+	cutil "github.com/coreos/butane/config/util"
 )
 
 func EnsurePathWithinFilesDir(path, filesDir string) error {
@@ -50,6 +51,30 @@ func ReadLocalFile(configPath, filesDir string) ([]byte, error) {
 	return os.ReadFile(filePath)
 }
 
+// ReadLocalFileWithOptions reads a local file like ReadLocalFile, and
+// records it in options.LocalFiles.
+func ReadLocalFileWithOptions(configPath string, options common.TranslateOptions) ([]byte, error) {
+	contents, err := ReadLocalFile(configPath, options.FilesDir)
+	if err == nil {
+		RecordLocalFile(filepath.Join(options.FilesDir, filepath.FromSlash(configPath)), contents, options)
+	}
+	return contents, err
+}
+
+// RecordLocalFile records the hash of a file read from filePath, which
+// is within options.FilesDir, if the caller requested it.
+func RecordLocalFile(filePath string, contents []byte, options common.TranslateOptions) {
+	if options.LocalFiles == nil {
+		return
+	}
+	rel, err := filepath.Rel(options.FilesDir, filePath)
+	if err != nil {
+		// can't happen for paths checked by EnsurePathWithinFilesDir
+		rel = filePath
+	}
+	options.LocalFiles[filepath.ToSlash(rel)] = cutil.Sha256(contents)
+}
+
 // CheckForDecimalMode fails if the specified mode appears to have been
 // incorrectly specified in decimal instead of octal.
 func CheckForDecimalMode(mode int, directory bool) error {
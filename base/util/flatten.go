@@ -0,0 +1,251 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/coreos/butane/config/common"
+	"github.com/coreos/butane/translate"
+
+	"github.com/coreos/ignition/v2/config/merge"
+	"github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	"github.com/vincent-petithory/dataurl"
+)
+
+// FlattenConfig resolves the ignition.config.replace and
+// ignition.config.merge directives in the Ignition config cfg whose
+// sources are data URLs, and merges the referenced configs into cfg the
+// way Ignition would at boot.  Referenced configs are flattened
+// recursively.  cfg can be a Config from any Ignition 3.x spec version;
+// referenced configs must have the same version.  Since merges are
+// order-dependent, directives following one that can't be resolved
+// locally are left in place.  FlattenConfig returns the flattened config
+// and its translations, and a report including the fields overridden by
+// merged configs.
+func FlattenConfig(cfg interface{}, ts translate.TranslationSet) (interface{}, translate.TranslationSet, report.Report) {
+	var r report.Report
+	configPath := path.New("json", "ignition", "config")
+
+	// replace discards the rest of the config
+	replace := ignitionConfig(cfg).FieldByName("Replace")
+	if replace.FieldByName("Source").IsNil() {
+		// nothing to replace
+	} else if child, err := resolveConfig(replace, cfg); err != nil {
+		r.AddOnWarn(configPath.Append("replace"), err)
+		return cfg, ts, r
+	} else {
+		childTs := translate.NewTranslationSet(ts.FromTag, ts.ToTag)
+		childTs.AddFromCommonSource(sourceOf(ts, configPath.Append("replace", "source")), path.New("json"), child)
+		return child, childTs, r
+	}
+
+	for ignitionConfig(cfg).FieldByName("Merge").Len() > 0 {
+		entryPath := configPath.Append("merge", 0)
+		child, err := resolveConfig(ignitionConfig(cfg).FieldByName("Merge").Index(0), cfg)
+		if err != nil {
+			r.AddOnWarn(entryPath, err)
+			break
+		}
+		childTs := translate.NewTranslationSet(ts.FromTag, ts.ToTag)
+		childTs.AddFromCommonSource(sourceOf(ts, entryPath.Append("source")), path.New("json"), child)
+
+		// drop the directive from the parent
+		parent := reflect.New(reflect.TypeOf(cfg)).Elem()
+		parent.Set(reflect.ValueOf(cfg))
+		merges := parent.FieldByName("Ignition").FieldByName("Config").FieldByName("Merge")
+		merges.Set(merges.Slice(1, merges.Len()))
+		parentTs := shiftMergeTranslations(ts)
+
+		var transcript merge.Transcript
+		cfg, ts, transcript = mergeTranslatedConfigs(parent.Interface(), parentTs, child, childTs)
+		r.Merge(reportOverrides(parent.Interface(), parentTs, transcript, sourceOf(childTs, path.New("json"))))
+	}
+	return cfg, ts, r
+}
+
+// resolveConfig returns the flattened config referenced by the Ignition
+// resource res.  The config must be of the same type and version as
+// parent.
+func resolveConfig(res reflect.Value, parent interface{}) (interface{}, error) {
+	source := res.FieldByName("Source").Interface().(*string)
+	if source == nil || !strings.HasPrefix(*source, "data:") {
+		return nil, common.ErrFlattenRemote
+	}
+	url, err := dataurl.DecodeString(*source)
+	if err != nil {
+		return nil, common.ErrFlattenInvalid
+	}
+	contents := url.Data
+	// spec 3.0.0 doesn't support compression
+	if compression := res.FieldByName("Compression"); compression.IsValid() && !compression.IsNil() && compression.Elem().String() == "gzip" {
+		reader, err := gzip.NewReader(bytes.NewReader(contents))
+		if err != nil {
+			return nil, common.ErrFlattenInvalid
+		}
+		if contents, err = io.ReadAll(reader); err != nil {
+			return nil, common.ErrFlattenInvalid
+		}
+	}
+
+	// check the version before parsing, since the schema could differ
+	var version struct {
+		Ignition struct {
+			Version string `json:"version"`
+		} `json:"ignition"`
+	}
+	if err := json.Unmarshal(contents, &version); err != nil {
+		return nil, common.ErrFlattenInvalid
+	}
+	parentVersion := reflect.ValueOf(parent).FieldByName("Ignition").FieldByName("Version").String()
+	if version.Ignition.Version != parentVersion {
+		return nil, common.ErrFlattenVersion
+	}
+	child := reflect.New(reflect.TypeOf(parent))
+	if _, err := util.HandleParseErrors(contents, child.Interface()); err != nil {
+		return nil, common.ErrFlattenInvalid
+	}
+
+	// flatten recursively; a child left with its own directives would
+	// be merged out of order
+	flattened, _, _ := FlattenConfig(child.Elem().Interface(), translate.NewTranslationSet("json", "json"))
+	config := ignitionConfig(flattened)
+	if !config.FieldByName("Replace").FieldByName("Source").IsNil() || config.FieldByName("Merge").Len() > 0 {
+		return nil, common.ErrFlattenNested
+	}
+	return flattened, nil
+}
+
+// reportOverrides reports the fields in parent that were replaced by the
+// child in a merge.
+func reportOverrides(parent interface{}, parentTs translate.TranslationSet, transcript merge.Transcript, childSource path.ContextPath) report.Report {
+	var r report.Report
+	kept := map[string]bool{}
+	for _, m := range transcript.Mappings {
+		if m.From.Tag == merge.TAG_PARENT {
+			kept[m.From.String()] = true
+		}
+	}
+	var overridden []string
+	for key, t := range parentTs.Set {
+		if t.To.Len() == 0 || kept[key] || !isSetLeaf(reflect.ValueOf(parent), t.To) {
+			continue
+		}
+		overridden = append(overridden, key)
+	}
+	sort.Strings(overridden)
+	for _, key := range overridden {
+		r.AddOnInfo(parentTs.Set[key].From, common.ErrFieldOverridden{
+			By: childSource.String(),
+		})
+	}
+	return r
+}
+
+// isSetLeaf returns true if p refers to a set optional scalar in the
+// Ignition config v.  Non-pointer scalars are the keys and versions that
+// are always taken from the child, so they aren't considered.
+func isSetLeaf(v reflect.Value, p path.ContextPath) bool {
+	for _, e := range p.Path {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return false
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Slice:
+			i, ok := e.(int)
+			if !ok || i >= v.Len() {
+				return false
+			}
+			v = v.Index(i)
+		case reflect.Struct:
+			field, ok := fieldByTag(v, e)
+			if !ok {
+				return false
+			}
+			v = field
+		default:
+			return false
+		}
+	}
+	return v.Kind() == reflect.Ptr && !v.IsNil() && util.IsPrimitive(v.Elem().Kind())
+}
+
+// fieldByTag returns the field of struct v with JSON name name, searching
+// embedded structs.
+func fieldByTag(v reflect.Value, name interface{}) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Anonymous {
+			if ret, ok := fieldByTag(v.Field(i), name); ok {
+				return ret, true
+			}
+			continue
+		}
+		if strings.Split(field.Tag.Get("json"), ",")[0] == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// shiftMergeTranslations returns a copy of ts with the translations for
+// the first ignition.config.merge entry removed and later entries
+// renumbered.
+func shiftMergeTranslations(ts translate.TranslationSet) translate.TranslationSet {
+	ret := translate.NewTranslationSet(ts.FromTag, ts.ToTag)
+	prefix := []interface{}{"ignition", "config", "merge"}
+	for _, t := range ts.Set {
+		to := t.To.Copy()
+		if to.Len() > len(prefix) && reflect.DeepEqual(to.Path[:len(prefix)], prefix) {
+			index := to.Path[len(prefix)].(int)
+			if index == 0 {
+				continue
+			}
+			to.Path[len(prefix)] = index - 1
+		}
+		ret.AddTranslation(t.From, to)
+	}
+	return ret
+}
+
+// sourceOf returns the source path of the output path p, falling back to
+// the root of the source.
+func sourceOf(ts translate.TranslationSet, p path.ContextPath) path.ContextPath {
+	for key := p.String(); ; {
+		if t, ok := ts.Set[key]; ok {
+			return t.From
+		}
+		if p.Len() == 0 {
+			return path.New(ts.FromTag)
+		}
+		p = p.Pop()
+		key = p.String()
+	}
+}
+
+func ignitionConfig(cfg interface{}) reflect.Value {
+	return reflect.ValueOf(cfg).FieldByName("Ignition").FieldByName("Config")
+}
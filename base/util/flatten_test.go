@@ -0,0 +1,205 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/coreos/butane/config/common"
+	"github.com/coreos/butane/translate"
+
+	"github.com/coreos/ignition/v2/config/util"
+	// config version doesn't matter; just pick one
+	"github.com/coreos/ignition/v2/config/v3_4/types"
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestFlattenConfig tests resolving merged and replacement configs.
+func TestFlattenConfig(t *testing.T) {
+	child := `{"ignition":{"version":"3.4.0"},"systemd":{"units":[{"name":"a.service","contents":"child"},{"name":"b.service"}]}}`
+	grandchild := `{"ignition":{"version":"3.4.0"},"storage":{"files":[{"path":"/etc/grandchild"}]}}`
+	nested := fmt.Sprintf(`{"ignition":{"version":"3.4.0","config":{"merge":[{"source":"data:,%s"}]}}}`, url.PathEscape(grandchild))
+	remote := `{"ignition":{"version":"3.4.0","config":{"merge":[{"source":"https://example.com/c.ign"}]}}}`
+	oldVersion := `{"ignition":{"version":"3.3.0"}}`
+	dataURL := func(config string) *string {
+		return util.StrToPtr("data:," + url.PathEscape(config))
+	}
+	parentUnits := []types.Unit{
+		{
+			Name:     "a.service",
+			Contents: util.StrToPtr("parent"),
+			Enabled:  util.BoolToPtr(true),
+		},
+	}
+	parentTranslations := []translate.Translation{
+		{From: path.New("yaml", "ignition", "config", "merge", 0, "inline"), To: path.New("json", "ignition", "config", "merge", 0, "source")},
+		{From: path.New("yaml", "ignition", "config", "merge", 1, "inline"), To: path.New("json", "ignition", "config", "merge", 1, "source")},
+		{From: path.New("yaml", "systemd", "units", 0, "name"), To: path.New("json", "systemd", "units", 0, "name")},
+		{From: path.New("yaml", "systemd", "units", 0, "contents"), To: path.New("json", "systemd", "units", 0, "contents")},
+		{From: path.New("yaml", "systemd", "units", 0, "enabled"), To: path.New("json", "systemd", "units", 0, "enabled")},
+	}
+
+	tests := []struct {
+		in           types.Config
+		out          types.Config
+		translations []translate.Translation
+		report       report.Report
+	}{
+		// merged configs, including nested ones
+		{
+			types.Config{
+				Ignition: types.Ignition{
+					Version: "3.4.0",
+					Config: types.IgnitionConfig{
+						Merge: []types.Resource{
+							{Source: dataURL(child)},
+							{Source: dataURL(nested)},
+						},
+					},
+				},
+				Systemd: types.Systemd{
+					Units: parentUnits,
+				},
+			},
+			types.Config{
+				Ignition: types.Ignition{
+					Version: "3.4.0",
+				},
+				Storage: types.Storage{
+					Files: []types.File{
+						{Node: types.Node{Path: "/etc/grandchild"}},
+					},
+				},
+				Systemd: types.Systemd{
+					Units: []types.Unit{
+						{
+							Name:     "a.service",
+							Contents: util.StrToPtr("child"),
+							Enabled:  util.BoolToPtr(true),
+						},
+						{
+							Name: "b.service",
+						},
+					},
+				},
+			},
+			[]translate.Translation{
+				{From: path.New("yaml", "systemd", "units", 0, "enabled"), To: path.New("json", "systemd", "units", 0, "enabled")},
+				{From: path.New("yaml", "ignition", "config", "merge", 0, "inline"), To: path.New("json", "systemd", "units", 0, "contents")},
+				{From: path.New("yaml", "ignition", "config", "merge", 0, "inline"), To: path.New("json", "systemd", "units", 1, "name")},
+				{From: path.New("yaml", "ignition", "config", "merge", 1, "inline"), To: path.New("json", "storage", "files", 0, "path")},
+			},
+			report.Report{
+				Entries: []report.Entry{
+					{
+						Kind:    report.Info,
+						Message: common.ErrFieldOverridden{By: "$.ignition.config.merge.0.inline"}.Error(),
+						Context: path.New("yaml", "systemd", "units", 0, "contents"),
+					},
+				},
+			},
+		},
+		// merges stop at the first config that can't be resolved
+		{
+			types.Config{
+				Ignition: types.Ignition{
+					Version: "3.4.0",
+					Config: types.IgnitionConfig{
+						Merge: []types.Resource{
+							{Source: dataURL(remote)},
+							{Source: dataURL(child)},
+						},
+					},
+				},
+			},
+			types.Config{
+				Ignition: types.Ignition{
+					Version: "3.4.0",
+					Config: types.IgnitionConfig{
+						Merge: []types.Resource{
+							{Source: dataURL(remote)},
+							{Source: dataURL(child)},
+						},
+					},
+				},
+			},
+			nil,
+			report.Report{
+				Entries: []report.Entry{
+					{
+						Kind:    report.Warn,
+						Message: common.ErrFlattenNested.Error(),
+						Context: path.New("json", "ignition", "config", "merge", 0),
+					},
+				},
+			},
+		},
+		// replacement configs, which must have the same version
+		{
+			types.Config{
+				Ignition: types.Ignition{
+					Version: "3.4.0",
+					Config: types.IgnitionConfig{
+						Replace: types.Resource{
+							Source: dataURL(oldVersion),
+						},
+					},
+				},
+			},
+			types.Config{
+				Ignition: types.Ignition{
+					Version: "3.4.0",
+					Config: types.IgnitionConfig{
+						Replace: types.Resource{
+							Source: dataURL(oldVersion),
+						},
+					},
+				},
+			},
+			nil,
+			report.Report{
+				Entries: []report.Entry{
+					{
+						Kind:    report.Warn,
+						Message: common.ErrFlattenVersion.Error(),
+						Context: path.New("json", "ignition", "config", "replace"),
+					},
+				},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("flatten %d", i), func(t *testing.T) {
+			ts := translate.NewTranslationSet("yaml", "json")
+			for _, tr := range parentTranslations {
+				ts.AddTranslation(tr.From, tr.To)
+			}
+			out, outTs, r := FlattenConfig(test.in, ts)
+			assert.Equal(t, test.out, out, "bad config")
+			assert.Equal(t, test.report, r, "bad report")
+			for _, tr := range test.translations {
+				actual, ok := outTs.Set[tr.To.String()]
+				if assert.True(t, ok, "missing translation for %s", tr.To) {
+					assert.Equal(t, tr.From, actual.From, "bad translation for %s", tr.To)
+				}
+			}
+		})
+	}
+}
@@ -26,6 +26,13 @@ import (
 // result.  It also generates and returns the merged TranslationSet by
 // mapping the parent/child TranslationSets through the merge transcript.
 func MergeTranslatedConfigs(parent interface{}, parentTranslations translate.TranslationSet, child interface{}, childTranslations translate.TranslationSet) (interface{}, translate.TranslationSet) {
+	result, ts, _ := mergeTranslatedConfigs(parent, parentTranslations, child, childTranslations)
+	return result, ts
+}
+
+// mergeTranslatedConfigs is MergeTranslatedConfigs, but also returns the
+// merge transcript.
+func mergeTranslatedConfigs(parent interface{}, parentTranslations translate.TranslationSet, child interface{}, childTranslations translate.TranslationSet) (interface{}, translate.TranslationSet, merge.Transcript) {
 	// mappings:
 	//   left:  parent or child translate.TranslationSet
 	//   right: merge.Transcript
@@ -71,5 +78,5 @@ func MergeTranslatedConfigs(parent interface{}, parentTranslations translate.Tra
 		rightEntry.To.Tag = leftEntry.To.Tag
 		ts.AddTranslation(leftEntry.From, rightEntry.To)
 	}
-	return result, ts
+	return result, ts, right
 }
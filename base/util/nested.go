@@ -0,0 +1,65 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package util
+
+import (
+	"github.com/coreos/butane/config/common"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+)
+
+// TranslateNestedConfig translates the nested Butane config in contents to
+// an Ignition config, using the parent's options.  name identifies the
+// nested config in error messages.  Since the report's markers refer to
+// the parent config, problems in the nested config are reported at c,
+// with a common.NestedEntryKind giving their location in the nested
+// config.  The returned config is nil if translation failed.
+func TranslateNestedConfig(name string, contents []byte, c path.ContextPath, options common.TranslateOptions) ([]byte, report.Report) {
+	var r report.Report
+	if options.NestedTranslator == nil {
+		r.AddOnError(c, common.ErrNoNestedTranslator)
+		return nil, r
+	}
+	// the nested config is embedded as an Ignition config, and its
+	// translations aren't meaningful to the caller
+	options.Translations = nil
+	bytesOptions := common.TranslateBytesOptions{
+		TranslateOptions: options,
+		Raw:              true,
+	}
+	out, childReport, err := options.NestedTranslator(contents, bytesOptions)
+	for _, entry := range childReport.Entries {
+		r.AddOn(c, common.ErrNestedConfig{
+			Name:   name,
+			Detail: entry.String(),
+		}, common.NestedEntryKind{
+			EntryKind: entry.Kind,
+			Source:    name,
+			Context:   entry.Context,
+			Marker:    entry.Marker,
+		})
+	}
+	if err != nil {
+		if !r.IsFatal() {
+			r.AddOnError(c, common.ErrNestedConfig{
+				Name:   name,
+				Detail: err.Error(),
+			})
+		}
+		return nil, r
+	}
+	return out, r
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package util
+
+import (
+	"math"
+	"math/big"
+	"regexp"
+
+	"github.com/coreos/butane/config/common"
+)
+
+var (
+	sizeRe = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?) ?([A-Za-z%]+)$`)
+
+	// sizes of the units in MiB; the decimal-looking units are binary,
+	// as in sgdisk and systemd-repart
+	sizeUnits = map[string]*big.Rat{
+		"K":   big.NewRat(1, 1024),
+		"KiB": big.NewRat(1, 1024),
+		"M":   big.NewRat(1, 1),
+		"MiB": big.NewRat(1, 1),
+		"G":   big.NewRat(1024, 1),
+		"GiB": big.NewRat(1024, 1),
+		"T":   big.NewRat(1024*1024, 1),
+		"TiB": big.NewRat(1024*1024, 1),
+	}
+)
+
+// ParseSizeMiB converts a human-readable size, such as "20GiB" or "512M",
+// to mebibytes.  "100%" converts to 0, which Ignition interprets as the
+// rest of the disk.
+func ParseSizeMiB(size string) (int, error) {
+	m := sizeRe.FindStringSubmatch(size)
+	if m == nil {
+		return 0, common.ErrSizeInvalid
+	}
+	if m[2] == "%" {
+		if m[1] != "100" {
+			return 0, common.ErrSizePercent
+		}
+		return 0, nil
+	}
+	unit, ok := sizeUnits[m[2]]
+	if !ok {
+		return 0, common.ErrSizeInvalid
+	}
+	value, ok := new(big.Rat).SetString(m[1])
+	if !ok {
+		return 0, common.ErrSizeInvalid
+	}
+	value.Mul(value, unit)
+	if !value.IsInt() {
+		return 0, common.ErrSizeNotMiBAligned
+	}
+	if !value.Num().IsInt64() || value.Num().Int64() > math.MaxInt32 {
+		return 0, common.ErrSizeInvalid
+	}
+	return int(value.Num().Int64()), nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package util
+
+import (
+	"testing"
+
+	"github.com/coreos/butane/config/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSizeMiB(t *testing.T) {
+	tests := []struct {
+		in  string
+		out int
+		err error
+	}{
+		{"512M", 512, nil},
+		{"512MiB", 512, nil},
+		{"20GiB", 20480, nil},
+		{"1.5G", 1536, nil},
+		{"2048K", 2, nil},
+		{"1 TiB", 1048576, nil},
+		{"100%", 0, nil},
+		{"50%", 0, common.ErrSizePercent},
+		{"1000K", 0, common.ErrSizeNotMiBAligned},
+		{"0.1M", 0, common.ErrSizeNotMiBAligned},
+		{"512", 0, common.ErrSizeInvalid},
+		{"512MB", 0, common.ErrSizeInvalid},
+		{"-1G", 0, common.ErrSizeInvalid},
+		{"G", 0, common.ErrSizeInvalid},
+		{"4096T", 0, common.ErrSizeInvalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.in, func(t *testing.T) {
+			out, err := ParseSizeMiB(test.in)
+			assert.Equal(t, test.err, err, "bad error")
+			assert.Equal(t, test.out, out, "bad size")
+		})
+	}
+}
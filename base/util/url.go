@@ -19,12 +19,28 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"net/url"
+	"unicode/utf8"
+
+	"github.com/coreos/butane/config/common"
 
 	"github.com/coreos/ignition/v2/config/util"
 	"github.com/vincent-petithory/dataurl"
 )
 
 func MakeDataURL(contents []byte, currentCompression *string, allowCompression bool) (uri string, compression *string, err error) {
+	return makeDataURL(contents, currentCompression, allowCompression, false)
+}
+
+// MakeDataURLWithOptions is like MakeDataURL, but selects the encoding
+// according to the resource settings in options.
+func MakeDataURLWithOptions(contents []byte, currentCompression *string, options common.TranslateOptions) (uri string, compression *string, err error) {
+	// small text resources are kept URL-escaped so they stay readable
+	// and produce small diffs when changed
+	readable := len(contents) < options.NoCompressUnder && utf8.Valid(contents)
+	return makeDataURL(contents, currentCompression, !options.NoResourceAutoCompression, readable)
+}
+
+func makeDataURL(contents []byte, currentCompression *string, allowCompression bool, readable bool) (uri string, compression *string, err error) {
 	// try three different encodings, and select the smallest one
 
 	if util.NilOrEmpty(currentCompression) {
@@ -45,6 +61,16 @@ func MakeDataURL(contents []byte, currentCompression *string, allowCompression b
 	// URL-escaped, useful for ASCII text
 	opaque := "," + dataurl.Escape(contents)
 
+	// Readable text resources always stay URL-escaped, even when
+	// another encoding would be smaller
+	if readable {
+		uri = (&url.URL{
+			Scheme: "data",
+			Opaque: opaque,
+		}).String()
+		return
+	}
+
 	// Base64-encoded, useful for small or incompressible binary data
 	b64 := ";base64," + base64.StdEncoding.EncodeToString(contents)
 	if len(b64) < len(opaque) {
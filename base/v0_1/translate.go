@@ -17,6 +17,7 @@ package v0_1
 import (
 	"net/url"
 
+	baseutil "github.com/coreos/butane/base/util"
 	"github.com/coreos/butane/config/common"
 	"github.com/coreos/butane/translate"
 
@@ -48,6 +49,11 @@ func (c Config) ToIgn3_0Unvalidated(options common.TranslateOptions) (types.Conf
 	if r.IsFatal() {
 		return types.Config{}, translate.TranslationSet{}, r
 	}
+	if options.Flatten {
+		flattened, tmFlattened, rFlatten := baseutil.FlattenConfig(ret, tm)
+		r.Merge(rFlatten)
+		return flattened.(types.Config), tmFlattened, r
+	}
 	return ret, tm, r
 }
 
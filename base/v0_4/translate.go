@@ -104,6 +104,11 @@ func (c Config) ToIgn3_3Unvalidated(options common.TranslateOptions) (types.Conf
 	if r.IsFatal() {
 		return types.Config{}, translate.TranslationSet{}, r
 	}
+	if options.Flatten {
+		flattened, tmFlattened, rFlatten := baseutil.FlattenConfig(ret, tm)
+		r.Merge(rFlatten)
+		return flattened.(types.Config), tmFlattened, r
+	}
 	return ret, tm, r
 }
 
@@ -140,7 +145,7 @@ func translateResource(from Resource, options common.TranslateOptions) (to types
 
 	if from.Local != nil {
 		c := path.New("yaml", "local")
-		contents, err := baseutil.ReadLocalFile(*from.Local, options.FilesDir)
+		contents, err := baseutil.ReadLocalFileWithOptions(*from.Local, options)
 		if err != nil {
 			r.AddOnError(c, err)
 			return
@@ -154,7 +159,7 @@ func translateResource(from Resource, options common.TranslateOptions) (to types
 				return
 			}
 		}
-		src, compression, err := baseutil.MakeDataURL(contents, to.Compression, !options.NoResourceAutoCompression)
+		src, compression, err := baseutil.MakeDataURLWithOptions(contents, to.Compression, options)
 		if err != nil {
 			r.AddOnError(c, err)
 			return
@@ -170,7 +175,7 @@ func translateResource(from Resource, options common.TranslateOptions) (to types
 	if from.Inline != nil {
 		c := path.New("yaml", "inline")
 
-		src, compression, err := baseutil.MakeDataURL([]byte(*from.Inline), to.Compression, !options.NoResourceAutoCompression)
+		src, compression, err := baseutil.MakeDataURLWithOptions([]byte(*from.Inline), to.Compression, options)
 		if err != nil {
 			r.AddOnError(c, err)
 			return
@@ -292,7 +297,8 @@ func walkTree(yamlPath path.ContextPath, ts *translate.TranslationSet, r *report
 				r.AddOnError(yamlPath, err)
 				return nil
 			}
-			url, compression, err := baseutil.MakeDataURL(contents, file.Contents.Compression, !options.NoResourceAutoCompression)
+			baseutil.RecordLocalFile(srcPath, contents, options)
+			url, compression, err := baseutil.MakeDataURLWithOptions(contents, file.Contents.Compression, options)
 			if err != nil {
 				r.AddOnError(yamlPath, err)
 				return nil
@@ -107,6 +107,11 @@ func (c Config) ToIgn3_6Unvalidated(options common.TranslateOptions) (types.Conf
 	if r.IsFatal() {
 		return types.Config{}, translate.TranslationSet{}, r
 	}
+	if options.Flatten {
+		flattened, tmFlattened, rFlatten := baseutil.FlattenConfig(ret, tm)
+		r.Merge(rFlatten)
+		return flattened.(types.Config), tmFlattened, r
+	}
 	return ret, tm, r
 }
 
@@ -143,7 +148,7 @@ func translateResource(from Resource, options common.TranslateOptions) (to types
 
 	if from.Local != nil {
 		c := path.New("yaml", "local")
-		contents, err := baseutil.ReadLocalFile(*from.Local, options.FilesDir)
+		contents, err := baseutil.ReadLocalFileWithOptions(*from.Local, options)
 		if err != nil {
 			r.AddOnError(c, err)
 			return
@@ -158,7 +163,7 @@ func translateResource(from Resource, options common.TranslateOptions) (to types
 			}
 		}
 
-		src, compression, err := baseutil.MakeDataURL(contents, to.Compression, !options.NoResourceAutoCompression)
+		src, compression, err := baseutil.MakeDataURLWithOptions(contents, to.Compression, options)
 		if err != nil {
 			r.AddOnError(c, err)
 			return
@@ -174,7 +179,7 @@ func translateResource(from Resource, options common.TranslateOptions) (to types
 	if from.Inline != nil {
 		c := path.New("yaml", "inline")
 
-		src, compression, err := baseutil.MakeDataURL([]byte(*from.Inline), to.Compression, !options.NoResourceAutoCompression)
+		src, compression, err := baseutil.MakeDataURLWithOptions([]byte(*from.Inline), to.Compression, options)
 		if err != nil {
 			r.AddOnError(c, err)
 			return
@@ -237,7 +242,7 @@ func translatePasswdUser(from PasswdUser, options common.TranslateOptions) (to t
 		}
 
 		for keyFileIndex, sshKeyFile := range from.SSHAuthorizedKeysLocal {
-			sshKeys, err := baseutil.ReadLocalFile(sshKeyFile, options.FilesDir)
+			sshKeys, err := baseutil.ReadLocalFileWithOptions(sshKeyFile, options)
 			if err != nil {
 				r.AddOnError(c.Append(keyFileIndex), err)
 				continue
@@ -266,7 +271,7 @@ func translateUnit(from Unit, options common.TranslateOptions) (to types.Unit, t
 
 	if util.NotEmpty(from.ContentsLocal) {
 		c := path.New("yaml", "contents_local")
-		contents, err := baseutil.ReadLocalFile(*from.ContentsLocal, options.FilesDir)
+		contents, err := baseutil.ReadLocalFileWithOptions(*from.ContentsLocal, options)
 		if err != nil {
 			r.AddOnError(c, err)
 			return
@@ -285,7 +290,7 @@ func translateDropin(from Dropin, options common.TranslateOptions) (to types.Dro
 
 	if util.NotEmpty(from.ContentsLocal) {
 		c := path.New("yaml", "contents_local")
-		contents, err := baseutil.ReadLocalFile(*from.ContentsLocal, options.FilesDir)
+		contents, err := baseutil.ReadLocalFileWithOptions(*from.ContentsLocal, options)
 		if err != nil {
 			r.AddOnError(c, err)
 			return
@@ -421,7 +426,8 @@ func walkTree(yamlPath path.ContextPath, ts *translate.TranslationSet, r *report
 				r.AddOnError(yamlPath, err)
 				return nil
 			}
-			url, compression, err := baseutil.MakeDataURL(contents, file.Contents.Compression, !options.NoResourceAutoCompression)
+			baseutil.RecordLocalFile(srcPath, contents, options.TranslateOptions)
+			url, compression, err := baseutil.MakeDataURLWithOptions(contents, file.Contents.Compression, options.TranslateOptions)
 			if err != nil {
 				r.AddOnError(yamlPath, err)
 				return nil
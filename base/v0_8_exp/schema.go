@@ -31,6 +31,12 @@ type ClevisCustom struct {
 	Pin          *string `yaml:"pin"`
 }
 
+type ConfigResource struct {
+	Resource     `yaml:",inline"`
+	ButaneInline *string `yaml:"butane_inline"` // Added, not in ignition spec
+	ButaneLocal  *string `yaml:"butane_local"`  // Added, not in ignition spec
+}
+
 type Config struct {
 	Version         string          `yaml:"version"`
 	Variant         string          `yaml:"variant"`
@@ -41,6 +47,28 @@ type Config struct {
 	Systemd         Systemd         `yaml:"systemd"`
 }
 
+type DataDevice struct {
+	Device       *string        `yaml:"device"`
+	Format       *string        `yaml:"format"`
+	Luks         DataDeviceLuks `yaml:"luks"`
+	MountOptions []string       `yaml:"mount_options"`
+	Path         *string        `yaml:"path"`
+	Raid         DataDeviceRaid `yaml:"raid"`
+}
+
+type DataDeviceLuks struct {
+	Discard   *bool    `yaml:"discard"`
+	KeyFile   Resource `yaml:"key_file"`
+	Tang      []Tang   `yaml:"tang"`
+	Threshold *int     `yaml:"threshold"`
+	Tpm2      *bool    `yaml:"tpm2"`
+}
+
+type DataDeviceRaid struct {
+	Devices []Device `yaml:"devices"`
+	Level   *string  `yaml:"level"`
+}
+
 type Device string
 
 type Directory struct {
@@ -74,15 +102,16 @@ type File struct {
 }
 
 type Filesystem struct {
-	Device         string   `yaml:"device"`
-	Format         *string  `yaml:"format"`
-	Label          *string  `yaml:"label"`
-	MountOptions   []string `yaml:"mount_options"`
-	Options        []string `yaml:"options"`
-	Path           *string  `yaml:"path"`
-	UUID           *string  `yaml:"uuid"`
-	WipeFilesystem *bool    `yaml:"wipe_filesystem"`
-	WithMountUnit  *bool    `yaml:"with_mount_unit" butane:"auto_skip"` // Added, not in Ignition spec
+	Device         string      `yaml:"device"`
+	Format         *string     `yaml:"format"`
+	Label          *string     `yaml:"label"`
+	MountOptions   []string    `yaml:"mount_options"`
+	Options        []string    `yaml:"options"`
+	Path           *string     `yaml:"path"`
+	Subvolumes     []Subvolume `yaml:"subvolumes" butane:"auto_skip"` // Added, not in Ignition spec
+	UUID           *string     `yaml:"uuid"`
+	WipeFilesystem *bool       `yaml:"wipe_filesystem"`
+	WithMountUnit  *bool       `yaml:"with_mount_unit" butane:"auto_skip"` // Added, not in Ignition spec
 }
 
 type Group string
@@ -102,8 +131,8 @@ type Ignition struct {
 }
 
 type IgnitionConfig struct {
-	Merge   []Resource `yaml:"merge"`
-	Replace Resource   `yaml:"replace"`
+	Merge   []ConfigResource `yaml:"merge"`
+	Replace ConfigResource   `yaml:"replace"`
 }
 
 type KernelArgument string
@@ -122,6 +151,14 @@ type Link struct {
 	Target    *string   `yaml:"target"`
 }
 
+type LogicalVolume struct {
+	Format       *string  `yaml:"format"`
+	MountOptions []string `yaml:"mount_options"`
+	Name         string   `yaml:"name"`
+	Path         *string  `yaml:"path"`
+	Size         *string  `yaml:"size"`
+}
+
 type Luks struct {
 	Cex         Cex      `yaml:"cex"`
 	Clevis      Clevis   `yaml:"clevis"`
@@ -152,7 +189,9 @@ type Partition struct {
 	Number             int     `yaml:"number"`
 	Resize             *bool   `yaml:"resize"`
 	ShouldExist        *bool   `yaml:"should_exist"`
+	Size               *string `yaml:"size" butane:"auto_skip"` // Added, not in ignition spec
 	SizeMiB            *int    `yaml:"size_mib"`
+	Start              *string `yaml:"start" butane:"auto_skip"` // Added, not in ignition spec
 	StartMiB           *int    `yaml:"start_mib"`
 	TypeGUID           *string `yaml:"type_guid"`
 	WipePartitionEntry *bool   `yaml:"wipe_partition_entry"`
@@ -219,14 +258,38 @@ type Security struct {
 }
 
 type Storage struct {
-	Directories []Directory  `yaml:"directories"`
-	Disks       []Disk       `yaml:"disks"`
-	Files       []File       `yaml:"files"`
-	Filesystems []Filesystem `yaml:"filesystems"`
-	Links       []Link       `yaml:"links"`
-	Luks        []Luks       `yaml:"luks"`
-	Raid        []Raid       `yaml:"raid"`
-	Trees       []Tree       `yaml:"trees" butane:"auto_skip"` // Added, not in ignition spec
+	DataDevices []DataDevice  `yaml:"data_devices" butane:"auto_skip"` // Added, not in ignition spec
+	Directories []Directory   `yaml:"directories"`
+	Disks       []Disk        `yaml:"disks"`
+	Files       []File        `yaml:"files"`
+	Filesystems []Filesystem  `yaml:"filesystems"`
+	Links       []Link        `yaml:"links"`
+	Luks        []Luks        `yaml:"luks"`
+	Lvm         []VolumeGroup `yaml:"lvm" butane:"auto_skip"` // Added, not in ignition spec
+	Raid        []Raid        `yaml:"raid"`
+	Swap        Swap          `yaml:"swap" butane:"auto_skip"`  // Added, not in ignition spec
+	Trees       []Tree        `yaml:"trees" butane:"auto_skip"` // Added, not in ignition spec
+}
+
+type Subvolume struct {
+	MountOptions []string `yaml:"mount_options"`
+	Name         string   `yaml:"name"`
+	Path         *string  `yaml:"path"`
+}
+
+type Swap struct {
+	File SwapFile `yaml:"file"`
+	Zram SwapZram `yaml:"zram"`
+}
+
+type SwapFile struct {
+	Path *string `yaml:"path"`
+	Size *string `yaml:"size"`
+}
+
+type SwapZram struct {
+	Algorithm *string `yaml:"algorithm"`
+	Size      *string `yaml:"size"`
 }
 
 type Systemd struct {
@@ -278,3 +341,9 @@ type Quadlet struct {
 type Verification struct {
 	Hash *string `yaml:"hash"`
 }
+
+type VolumeGroup struct {
+	Devices        []Device        `yaml:"devices"`
+	LogicalVolumes []LogicalVolume `yaml:"logical_volumes"`
+	Name           string          `yaml:"name"`
+}
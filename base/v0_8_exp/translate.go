@@ -20,6 +20,7 @@ import (
 	slashpath "path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -49,6 +50,13 @@ Options=
 
 # Generated by Butane
 {{- if .Swap }}
+{{- if .Requires }}
+[Unit]
+{{- range .Requires }}
+Requires={{.}}
+After={{.}}
+{{- end }}
+{{ end }}
 [Swap]
 What={{.Device}}
 {{- template "options" . }}
@@ -59,6 +67,10 @@ RequiredBy=swap.target
 [Unit]
 Requires=systemd-fsck@{{.EscapedDevice}}.service
 After=systemd-fsck@{{.EscapedDevice}}.service
+{{- range .Requires }}
+Requires={{.}}
+After={{.}}
+{{- end }}
 
 [Mount]
 Where={{.Path}}
@@ -73,6 +85,93 @@ RequiredBy=remote-fs.target
 RequiredBy=local-fs.target
 {{- end }}
 {{- end }}`))
+
+	lvmUnitTemplate = template.Must(template.New("unit").Parse(`# Generated by Butane
+[Unit]
+Description=Create LVM volume group {{.Name}}
+DefaultDependencies=no
+{{- range .Requires }}
+Requires={{.}}
+After={{.}}
+{{- end }}
+Before=shutdown.target
+Conflicts=shutdown.target
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/bin/sh -c 'vgs {{.Name}} >/dev/null 2>&1 || vgcreate {{.Name}}{{ range .Devices }} {{.}}{{ end }}'
+ExecStart=vgchange --activate y {{.Name}}
+{{- range .LogicalVolumes }}
+ExecStart=/bin/sh -c 'lvs {{$.Name}}/{{.Name}} >/dev/null 2>&1 || lvcreate --yes --name {{.Name}} {{.Size}} {{$.Name}}'
+{{- if .Mkfs }}
+ExecStart=/bin/sh -c '[ -n "$$(blkid --probe --match-tag TYPE --output value {{.Device}})" ] || {{.Mkfs}} {{.Device}}'
+{{- end }}
+{{- end }}
+
+[Install]
+WantedBy=local-fs.target`))
+
+	subvolumeUnitTemplate = template.Must(template.New("unit").Parse(`# Generated by Butane
+[Unit]
+Description=Create btrfs subvolumes on {{.Device}}
+DefaultDependencies=no
+Requires={{.EscapedDevice}}.device
+After={{.EscapedDevice}}.device
+Before=shutdown.target
+Conflicts=shutdown.target
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+PrivateMounts=yes
+ExecStartPre=mkdir -p /run/butane-btrfs
+ExecStartPre=mount -t btrfs -o subvolid=5 {{.Device}} /run/butane-btrfs
+{{- range .Subvolumes }}
+{{- if or .Compression .NoCow }}
+ExecStart=/bin/sh -c '[ -e /run/butane-btrfs/{{.Name}} ] || { btrfs subvolume create /run/butane-btrfs/{{.Name}}
+{{- if .Compression }} && btrfs property set /run/butane-btrfs/{{.Name}} compression {{.Compression}}{{ end }}
+{{- if .NoCow }} && chattr +C /run/butane-btrfs/{{.Name}}{{ end }}; }'
+{{- else }}
+ExecStart=/bin/sh -c '[ -e /run/butane-btrfs/{{.Name}} ] || btrfs subvolume create /run/butane-btrfs/{{.Name}}'
+{{- end }}
+{{- end }}
+ExecStartPost=umount /run/butane-btrfs
+
+[Install]
+{{- if .Remote }}
+WantedBy=remote-fs.target
+{{- else }}
+WantedBy=local-fs.target
+{{- end }}`))
+
+	swapFileUnitTemplate = template.Must(template.New("unit").Parse(`# Generated by Butane
+[Unit]
+Description=Create swap file {{.Path}}
+DefaultDependencies=no
+RequiresMountsFor={{.Dir}}
+ConditionPathExists=!{{.Path}}
+Before=shutdown.target
+Conflicts=shutdown.target
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=truncate --size 0 {{.Path}}.tmp
+ExecStart=-chattr -c +C {{.Path}}.tmp
+ExecStart=fallocate --length {{.SizeMiB}}MiB {{.Path}}.tmp
+ExecStart=chmod 0600 {{.Path}}.tmp
+ExecStart=-chcon --type swapfile_t {{.Path}}.tmp
+ExecStart=mkswap {{.Path}}.tmp
+ExecStart=mv {{.Path}}.tmp {{.Path}}`))
+
+	zramConfigTemplate = template.Must(template.New("zram").Parse(`# Generated by Butane
+[zram0]
+zram-size = {{.Size}}
+{{- if .Algorithm }}
+compression-algorithm = {{.Algorithm}}
+{{- end }}
+`))
 )
 
 // ToIgn3_7Unvalidated translates the config to an Ignition config. It also returns the set of translations
@@ -89,6 +188,7 @@ func (c Config) ToIgn3_7Unvalidated(options common.TranslateOptions) (types.Conf
 	tr.AddCustomTranslator(translateResource)
 	tr.AddCustomTranslator(translatePasswdUser)
 	tr.AddCustomTranslator(translateUnit)
+	tr.AddCustomTranslator(translatePartition)
 
 	tm, r := translate.Prefixed(tr, "ignition", &c.Ignition, &ret.Ignition)
 	tm.AddTranslation(path.New("yaml", "version"), path.New("json", "ignition", "version"))
@@ -99,6 +199,10 @@ func (c Config) ToIgn3_7Unvalidated(options common.TranslateOptions) (types.Conf
 	translate.MergeP(tr, tm, &r, "systemd", &c.Systemd, &ret.Systemd)
 
 	c.addMountUnits(&ret, &tm)
+	r.Merge(c.processDataDevices(&ret, &tm, options))
+	c.processLvm(&ret, &tm)
+	c.processSubvolumes(&ret, &tm)
+	r.Merge(c.processSwap(&ret, &tm, options))
 
 	tmTrees, rTrees := c.processTrees(&ret, options)
 	tmQuadlets, rQuadlets := c.processQuadlets(&ret, options)
@@ -111,12 +215,18 @@ func (c Config) ToIgn3_7Unvalidated(options common.TranslateOptions) (types.Conf
 	if r.IsFatal() {
 		return types.Config{}, translate.TranslationSet{}, r
 	}
+	if options.Flatten {
+		flattened, tmFlattened, rFlatten := baseutil.FlattenConfig(ret, tm)
+		r.Merge(rFlatten)
+		return flattened.(types.Config), tmFlattened, r
+	}
 	return ret, tm, r
 }
 
 func translateIgnition(from Ignition, options common.TranslateOptions) (to types.Ignition, tm translate.TranslationSet, r report.Report) {
 	tr := translate.NewTranslator("yaml", "json", options)
 	tr.AddCustomTranslator(translateResource)
+	tr.AddCustomTranslator(translateConfigResource)
 	to.Version = types.MaxVersion.String()
 	tm, r = translate.Prefixed(tr, "config", &from.Config, &to.Config)
 	translate.MergeP(tr, tm, &r, "proxy", &from.Proxy, &to.Proxy)
@@ -147,7 +257,7 @@ func translateResource(from Resource, options common.TranslateOptions) (to types
 
 	if from.Local != nil {
 		c := path.New("yaml", "local")
-		contents, err := baseutil.ReadLocalFile(*from.Local, options.FilesDir)
+		contents, err := baseutil.ReadLocalFileWithOptions(*from.Local, options)
 		if err != nil {
 			r.AddOnError(c, err)
 			return
@@ -162,7 +272,7 @@ func translateResource(from Resource, options common.TranslateOptions) (to types
 			}
 		}
 
-		src, compression, err := baseutil.MakeDataURL(contents, to.Compression, !options.NoResourceAutoCompression)
+		src, compression, err := baseutil.MakeDataURLWithOptions(contents, to.Compression, options)
 		if err != nil {
 			r.AddOnError(c, err)
 			return
@@ -178,7 +288,7 @@ func translateResource(from Resource, options common.TranslateOptions) (to types
 	if from.Inline != nil {
 		c := path.New("yaml", "inline")
 
-		src, compression, err := baseutil.MakeDataURL([]byte(*from.Inline), to.Compression, !options.NoResourceAutoCompression)
+		src, compression, err := baseutil.MakeDataURLWithOptions([]byte(*from.Inline), to.Compression, options)
 		if err != nil {
 			r.AddOnError(c, err)
 			return
@@ -193,6 +303,51 @@ func translateResource(from Resource, options common.TranslateOptions) (to types
 	return
 }
 
+// translateConfigResource translates a reference to an Ignition config,
+// which can be a nested Butane config that's translated with the parent's
+// options and embedded.
+func translateConfigResource(from ConfigResource, options common.TranslateOptions) (to types.Resource, tm translate.TranslationSet, r report.Report) {
+	to, tm, r = translateResource(from.Resource, options)
+
+	var c path.ContextPath
+	var name string
+	var contents []byte
+	if from.ButaneLocal != nil {
+		c = path.New("yaml", "butane_local")
+		name = *from.ButaneLocal
+		var err error
+		contents, err = baseutil.ReadLocalFileWithOptions(*from.ButaneLocal, options)
+		if err != nil {
+			r.AddOnError(c, err)
+			return
+		}
+	} else if from.ButaneInline != nil {
+		c = path.New("yaml", "butane_inline")
+		name = "inline"
+		contents = []byte(*from.ButaneInline)
+	} else {
+		return
+	}
+
+	ign, rNested := baseutil.TranslateNestedConfig(name, contents, c, options)
+	r.Merge(rNested)
+	if ign == nil {
+		return
+	}
+	src, compression, err := baseutil.MakeDataURLWithOptions(ign, to.Compression, options)
+	if err != nil {
+		r.AddOnError(c, err)
+		return
+	}
+	to.Source = &src
+	tm.AddTranslation(c, path.New("json", "source"))
+	if compression != nil {
+		to.Compression = compression
+		tm.AddTranslation(c, path.New("json", "compression"))
+	}
+	return
+}
+
 func translateDirectory(from Directory, options common.TranslateOptions) (to types.Directory, tm translate.TranslationSet, r report.Report) {
 	tr := translate.NewTranslator("yaml", "json", options)
 	tm, r = translate.Prefixed(tr, "group", &from.Group, &to.Group)
@@ -214,6 +369,25 @@ func translateLink(from Link, options common.TranslateOptions) (to types.Link, t
 	return
 }
 
+// translatePartition converts the human-readable size and start of a
+// partition to mebibytes.
+func translatePartition(from Partition, options common.TranslateOptions) (to types.Partition, tm translate.TranslationSet, r report.Report) {
+	tr := translate.NewTranslator("yaml", "json", options)
+	tm, r = tr.Translate(&from, &to)
+	// unchecked parse ok, from would fail validation otherwise
+	if from.Size != nil {
+		size, _ := baseutil.ParseSizeMiB(*from.Size)
+		to.SizeMiB = &size
+		tm.AddTranslation(path.New("yaml", "size"), path.New("json", "sizeMiB"))
+	}
+	if from.Start != nil {
+		start, _ := baseutil.ParseSizeMiB(*from.Start)
+		to.StartMiB = &start
+		tm.AddTranslation(path.New("yaml", "start"), path.New("json", "startMiB"))
+	}
+	return
+}
+
 func translatePasswdUser(from PasswdUser, options common.TranslateOptions) (to types.PasswdUser, tm translate.TranslationSet, r report.Report) {
 	tr := translate.NewTranslator("yaml", "json", options)
 	tm, r = translate.Prefixed(tr, "gecos", &from.Gecos, &to.Gecos)
@@ -241,7 +415,7 @@ func translatePasswdUser(from PasswdUser, options common.TranslateOptions) (to t
 		}
 
 		for keyFileIndex, sshKeyFile := range from.SSHAuthorizedKeysLocal {
-			sshKeys, err := baseutil.ReadLocalFile(sshKeyFile, options.FilesDir)
+			sshKeys, err := baseutil.ReadLocalFileWithOptions(sshKeyFile, options)
 			if err != nil {
 				r.AddOnError(c.Append(keyFileIndex), err)
 				continue
@@ -270,7 +444,7 @@ func translateUnit(from Unit, options common.TranslateOptions) (to types.Unit, t
 
 	if util.NotEmpty(from.ContentsLocal) {
 		c := path.New("yaml", "contents_local")
-		contents, err := baseutil.ReadLocalFile(*from.ContentsLocal, options.FilesDir)
+		contents, err := baseutil.ReadLocalFileWithOptions(*from.ContentsLocal, options)
 		if err != nil {
 			r.AddOnError(c, err)
 			return
@@ -289,7 +463,7 @@ func translateDropin(from Dropin, options common.TranslateOptions) (to types.Dro
 
 	if util.NotEmpty(from.ContentsLocal) {
 		c := path.New("yaml", "contents_local")
-		contents, err := baseutil.ReadLocalFile(*from.ContentsLocal, options.FilesDir)
+		contents, err := baseutil.ReadLocalFileWithOptions(*from.ContentsLocal, options)
 		if err != nil {
 			r.AddOnError(c, err)
 			return
@@ -339,7 +513,7 @@ func isTemplateInstance(name string) (bool, string) {
 func readLocalOrInlineContents(contentsLocal, contentsInline *string, ctxPath path.ContextPath, options common.TranslateOptions) (content []byte, contentPath path.ContextPath, err error) {
 	if util.NotEmpty(contentsLocal) {
 		contentPath = ctxPath.Append("contents_local")
-		localContents, err := baseutil.ReadLocalFile(*contentsLocal, options.FilesDir)
+		localContents, err := baseutil.ReadLocalFileWithOptions(*contentsLocal, options)
 		if err != nil {
 			return content, contentPath, err
 		}
@@ -383,7 +557,7 @@ func addFileWithContents(
 		r.AddOnError(contentPath, err)
 		return ts, r
 	}
-	url, compression, err := baseutil.MakeDataURL(contentBytes, file.Contents.Compression, !options.NoResourceAutoCompression)
+	url, compression, err := baseutil.MakeDataURLWithOptions(contentBytes, file.Contents.Compression, options)
 	if err != nil {
 		r.AddOnError(ctxPath, err)
 		return ts, r
@@ -591,7 +765,8 @@ func walkTree(yamlPath path.ContextPath, ts *translate.TranslationSet, r *report
 				r.AddOnError(yamlPath, err)
 				return nil
 			}
-			url, compression, err := baseutil.MakeDataURL(contents, file.Contents.Compression, !options.NoResourceAutoCompression)
+			baseutil.RecordLocalFile(srcPath, contents, options.TranslateOptions)
+			url, compression, err := baseutil.MakeDataURLWithOptions(contents, file.Contents.Compression, options.TranslateOptions)
 			if err != nil {
 				r.AddOnError(yamlPath, err)
 				return nil
@@ -677,21 +852,7 @@ func (c Config) addMountUnits(config *types.Config, ts *translate.TranslationSet
 			continue
 		}
 		fromPath := path.New("yaml", "storage", "filesystems", i, "with_mount_unit")
-		remote := false
-		// check filesystems targeting /dev/mapper devices against LUKS to determine if a
-		// remote mount is needed
-		if strings.HasPrefix(fs.Device, "/dev/mapper/") || strings.HasPrefix(fs.Device, "/dev/disk/by-id/dm-name-") {
-			for _, luks := range c.Storage.Luks {
-				// LUKS devices are opened with their name specified
-				if fs.Device == fmt.Sprintf("/dev/mapper/%s", luks.Name) || fs.Device == fmt.Sprintf("/dev/disk/by-id/dm-name-%s", luks.Name) {
-					if len(luks.Clevis.Tang) > 0 {
-						remote = true
-						break
-					}
-				}
-			}
-		}
-		newUnit := mountUnitFromFS(fs, remote)
+		newUnit := MountUnitFromFS(fs, c.isRemoteDevice(fs.Device))
 		unitPath := path.New("json", "systemd", "units", len(rendered.Systemd.Units))
 		rendered.Systemd.Units = append(rendered.Systemd.Units, newUnit)
 		renderedTranslations.AddFromCommonSource(fromPath, unitPath, newUnit)
@@ -701,16 +862,43 @@ func (c Config) addMountUnits(config *types.Config, ts *translate.TranslationSet
 	*ts = retTranslations
 }
 
-func mountUnitFromFS(fs Filesystem, remote bool) types.Unit {
+// isRemoteDevice reports whether device is a LUKS volume that requires
+// network access to unlock.
+func (c Config) isRemoteDevice(device string) bool {
+	// check filesystems targeting /dev/mapper devices against LUKS to determine if a
+	// remote mount is needed
+	if strings.HasPrefix(device, "/dev/mapper/") || strings.HasPrefix(device, "/dev/disk/by-id/dm-name-") {
+		for _, luks := range c.Storage.Luks {
+			// LUKS devices are opened with their name specified
+			if device == fmt.Sprintf("/dev/mapper/%s", luks.Name) || device == fmt.Sprintf("/dev/disk/by-id/dm-name-%s", luks.Name) {
+				return len(luks.Clevis.Tang) > 0
+			}
+		}
+	}
+	return false
+}
+
+// MountUnitFromFS returns the mount or swap unit that with_mount_unit
+// generates for fs.  remote specifies that the device requires network
+// access.
+func MountUnitFromFS(fs Filesystem, remote bool) types.Unit {
+	return mountUnitFromFS(fs, remote, nil)
+}
+
+// mountUnitFromFS is like MountUnitFromFS, but the mount unit also
+// requires and is ordered after the units in requires.
+func mountUnitFromFS(fs Filesystem, remote bool, requires []string) types.Unit {
 	context := struct {
 		*Filesystem
 		EscapedDevice string
 		Remote        bool
+		Requires      []string
 		Swap          bool
 	}{
 		Filesystem:    &fs,
 		EscapedDevice: unit.UnitNamePathEscape(fs.Device),
 		Remote:        remote,
+		Requires:      requires,
 		// unchecked deref of format ok, fs would fail validation otherwise
 		Swap: *fs.Format == "swap",
 	}
@@ -732,3 +920,518 @@ func mountUnitFromFS(fs Filesystem, remote bool) types.Unit {
 		Contents: util.StrToPtr(contents.String()),
 	}
 }
+
+// processDataDevices renders the partitions, RAID arrays, LUKS volumes,
+// filesystems, and mount units for storage.data_devices.
+func (c Config) processDataDevices(config *types.Config, ts *translate.TranslationSet, options common.TranslateOptions) report.Report {
+	var r report.Report
+	if len(c.Storage.DataDevices) == 0 {
+		return r
+	}
+	var rendered types.Config
+	renderedTranslations := translate.NewTranslationSet("yaml", "json")
+	for i, dd := range c.Storage.DataDevices {
+		ddPath := path.New("yaml", "storage", "data_devices", i)
+		name := dd.name()
+		var device string
+		var requires []string
+
+		// partitions, combined into a RAID array if there are several
+		// devices
+		if len(dd.Raid.Devices) > 0 {
+			raid := types.Raid{
+				Level: util.StrToPtr("raid1"),
+				Name:  name,
+			}
+			if dd.Raid.Level != nil {
+				raid.Level = util.StrToPtr(*dd.Raid.Level)
+			}
+			for j, member := range dd.Raid.Devices {
+				label := fmt.Sprintf("%s-%d", name, j+1)
+				disk := types.Disk{
+					Device: string(member),
+					Partitions: []types.Partition{{
+						Label:  util.StrToPtr(label),
+						Number: 1,
+					}},
+				}
+				renderedTranslations.AddFromCommonSource(ddPath.Append("raid", "devices", j), path.New("json", "storage", "disks", len(rendered.Storage.Disks)), disk)
+				rendered.Storage.Disks = append(rendered.Storage.Disks, disk)
+				raid.Devices = append(raid.Devices, types.Device("/dev/disk/by-partlabel/"+label))
+			}
+			rpath := path.New("json", "storage", "raid", len(rendered.Storage.Raid))
+			renderedTranslations.AddFromCommonSource(ddPath.Append("raid"), rpath, raid)
+			for j := range raid.Devices {
+				renderedTranslations.AddTranslation(ddPath.Append("raid", "devices", j), rpath.Append("devices", j))
+			}
+			if dd.Raid.Level != nil {
+				renderedTranslations.AddTranslation(ddPath.Append("raid", "level"), rpath.Append("level"))
+			}
+			rendered.Storage.Raid = append(rendered.Storage.Raid, raid)
+			device = "/dev/md/" + name
+			requires = append(requires, unit.UnitNamePathEscape(device)+".device")
+		} else {
+			disk := types.Disk{
+				// unchecked deref of device ok, dd would fail
+				// validation otherwise
+				Device: *dd.Device,
+				Partitions: []types.Partition{{
+					Label:  util.StrToPtr(name),
+					Number: 1,
+				}},
+			}
+			dpath := path.New("json", "storage", "disks", len(rendered.Storage.Disks))
+			renderedTranslations.AddFromCommonSource(ddPath, dpath, disk)
+			renderedTranslations.AddTranslation(ddPath.Append("device"), dpath.Append("device"))
+			rendered.Storage.Disks = append(rendered.Storage.Disks, disk)
+			device = "/dev/disk/by-partlabel/" + name
+		}
+
+		// LUKS volume
+		if dd.Luks.wanted() {
+			luks, tmLuks, rLuks := translateDataDeviceLuks(dd.Luks, options)
+			luks.Device = util.StrToPtr(device)
+			luks.Label = util.StrToPtr("luks-" + name)
+			luks.Name = name
+			lpath := ddPath.Append("luks")
+			rpath := path.New("json", "storage", "luks", len(rendered.Storage.Luks))
+			renderedTranslations.Merge(tmLuks.PrefixPaths(lpath, rpath))
+			for _, f := range []string{"device", "label", "name"} {
+				renderedTranslations.AddTranslation(lpath, rpath.Append(f))
+			}
+			for _, entry := range rLuks.Entries {
+				entry.Context = lpath.Append(entry.Context.Path...)
+				r.Entries = append(r.Entries, entry)
+			}
+			rendered.Storage.Luks = append(rendered.Storage.Luks, luks)
+			device = "/dev/mapper/" + name
+			requires = append(requires, fmt.Sprintf("systemd-cryptsetup@%s.service", unit.UnitNameEscape(name)))
+		}
+
+		// filesystem
+		fs := Filesystem{
+			Device:       device,
+			Format:       util.StrToPtr("xfs"),
+			MountOptions: dd.MountOptions,
+			// unchecked deref of path ok, dd would fail validation
+			// otherwise
+			Path: util.StrToPtr(*dd.Path),
+		}
+		if dd.Format != nil {
+			fs.Format = util.StrToPtr(*dd.Format)
+		}
+		filesystem := types.Filesystem{
+			Device: fs.Device,
+			Format: fs.Format,
+			Path:   fs.Path,
+		}
+		for _, opt := range dd.MountOptions {
+			filesystem.MountOptions = append(filesystem.MountOptions, types.MountOption(opt))
+		}
+		fspath := path.New("json", "storage", "filesystems", len(rendered.Storage.Filesystems))
+		renderedTranslations.AddFromCommonSource(ddPath, fspath, filesystem)
+		renderedTranslations.AddTranslation(ddPath.Append("path"), fspath.Append("path"))
+		if dd.Format != nil {
+			renderedTranslations.AddTranslation(ddPath.Append("format"), fspath.Append("format"))
+		}
+		for j := range dd.MountOptions {
+			renderedTranslations.AddTranslation(ddPath.Append("mount_options", j), fspath.Append("mountOptions", j))
+		}
+		if len(dd.MountOptions) > 0 {
+			renderedTranslations.AddTranslation(ddPath.Append("mount_options"), fspath.Append("mountOptions"))
+		}
+		rendered.Storage.Filesystems = append(rendered.Storage.Filesystems, filesystem)
+
+		// mount unit
+		mountUnit := mountUnitFromFS(fs, len(dd.Luks.Tang) > 0, requires)
+		renderedTranslations.AddFromCommonSource(ddPath, path.New("json", "systemd", "units", len(rendered.Systemd.Units)), mountUnit)
+		rendered.Systemd.Units = append(rendered.Systemd.Units, mountUnit)
+	}
+	fromPath := path.New("yaml", "storage", "data_devices")
+	renderedTranslations.AddTranslation(fromPath, path.New("json", "storage"))
+	renderedTranslations.AddTranslation(fromPath, path.New("json", "storage", "disks"))
+	if len(rendered.Storage.Raid) > 0 {
+		renderedTranslations.AddTranslation(fromPath, path.New("json", "storage", "raid"))
+	}
+	if len(rendered.Storage.Luks) > 0 {
+		renderedTranslations.AddTranslation(fromPath, path.New("json", "storage", "luks"))
+	}
+	renderedTranslations.AddTranslation(fromPath, path.New("json", "storage", "filesystems"))
+	renderedTranslations.AddTranslation(fromPath, path.New("json", "systemd"))
+	renderedTranslations.AddTranslation(fromPath, path.New("json", "systemd", "units"))
+	retConfig, retTranslations := baseutil.MergeTranslatedConfigs(rendered, renderedTranslations, *config, *ts)
+	*config = retConfig.(types.Config)
+	*ts = retTranslations
+	return r
+}
+
+// processLvm renders the volume group units and the mount and swap units
+// for storage.lvm.  Ignition doesn't support LVM, so the volume groups,
+// logical volumes, and filesystems are created on first boot.
+func (c Config) processLvm(config *types.Config, ts *translate.TranslationSet) {
+	if len(c.Storage.Lvm) == 0 {
+		return
+	}
+	type lvContext struct {
+		Name   string
+		Size   string
+		Device string
+		Mkfs   string
+	}
+	var rendered types.Config
+	renderedTranslations := translate.NewTranslationSet("yaml", "json")
+	for i, vg := range c.Storage.Lvm {
+		vgPath := path.New("yaml", "storage", "lvm", i)
+		context := struct {
+			Name           string
+			Devices        []string
+			Requires       []string
+			LogicalVolumes []lvContext
+		}{
+			Name: vg.Name,
+		}
+		for _, dev := range vg.Devices {
+			context.Devices = append(context.Devices, string(dev))
+			context.Requires = append(context.Requires, unit.UnitNamePathEscape(string(dev))+".device")
+		}
+		var mountUnits []types.Unit
+		var mountPaths []path.ContextPath
+		for j, lv := range vg.LogicalVolumes {
+			lvc := lvContext{
+				Name:   lv.Name,
+				Size:   "--extents 100%%FREE",
+				Device: vg.lvDevice(lv),
+			}
+			if !lv.fills() {
+				// unchecked parse ok, lv would fail validation otherwise
+				sizeMiB, _ := baseutil.ParseSizeMiB(*lv.Size)
+				lvc.Size = fmt.Sprintf("--size %dm", sizeMiB)
+			}
+			if lv.Format != nil {
+				if *lv.Format == "swap" {
+					lvc.Mkfs = "mkswap"
+				} else {
+					lvc.Mkfs = "mkfs." + *lv.Format
+				}
+				if lv.Path != nil || *lv.Format == "swap" {
+					fs := Filesystem{
+						Device:       lvc.Device,
+						Format:       lv.Format,
+						MountOptions: lv.MountOptions,
+						Path:         lv.Path,
+					}
+					mountUnits = append(mountUnits, mountUnitFromFS(fs, false, []string{vg.unitName()}))
+					mountPaths = append(mountPaths, vgPath.Append("logical_volumes", j))
+				}
+			}
+			context.LogicalVolumes = append(context.LogicalVolumes, lvc)
+		}
+		contents := strings.Builder{}
+		if err := lvmUnitTemplate.Execute(&contents, context); err != nil {
+			panic(err)
+		}
+		vgUnit := types.Unit{
+			Name:     vg.unitName(),
+			Enabled:  util.BoolToPtr(true),
+			Contents: util.StrToPtr(contents.String()),
+		}
+		renderedTranslations.AddFromCommonSource(vgPath, path.New("json", "systemd", "units", len(rendered.Systemd.Units)), vgUnit)
+		rendered.Systemd.Units = append(rendered.Systemd.Units, vgUnit)
+		for j, mountUnit := range mountUnits {
+			renderedTranslations.AddFromCommonSource(mountPaths[j], path.New("json", "systemd", "units", len(rendered.Systemd.Units)), mountUnit)
+			rendered.Systemd.Units = append(rendered.Systemd.Units, mountUnit)
+		}
+	}
+	fromPath := path.New("yaml", "storage", "lvm")
+	renderedTranslations.AddTranslation(fromPath, path.New("json", "systemd"))
+	renderedTranslations.AddTranslation(fromPath, path.New("json", "systemd", "units"))
+	retConfig, retTranslations := baseutil.MergeTranslatedConfigs(rendered, renderedTranslations, *config, *ts)
+	*config = retConfig.(types.Config)
+	*ts = retTranslations
+}
+
+// processSubvolumes renders the units that create and mount the btrfs
+// subvolumes of storage.filesystems.  Ignition doesn't support subvolumes,
+// so each filesystem with subvolumes gets a unit that creates any missing
+// subvolumes in the top-level subvolume, plus mount units ordered after it.
+// The compress and nodatacow mount options would apply to the whole
+// filesystem, so they're set on the subvolume when it's created instead.
+func (c Config) processSubvolumes(config *types.Config, ts *translate.TranslationSet) {
+	var rendered types.Config
+	renderedTranslations := translate.NewTranslationSet("yaml", "json")
+	for i, fs := range c.Storage.Filesystems {
+		if len(fs.Subvolumes) == 0 {
+			continue
+		}
+		fsPath := path.New("yaml", "storage", "filesystems", i)
+		remote := c.isRemoteDevice(fs.Device)
+		context := struct {
+			Device        string
+			EscapedDevice string
+			Remote        bool
+			Subvolumes    []subvolumeContext
+		}{
+			Device:        fs.Device,
+			EscapedDevice: unit.UnitNamePathEscape(fs.Device),
+			Remote:        remote,
+		}
+		var mountUnits []types.Unit
+		var mountPaths []path.ContextPath
+		for j, sv := range fs.Subvolumes {
+			compression, noCow, mountOptions := sv.properties()
+			context.Subvolumes = append(context.Subvolumes, subvolumeContext{
+				Name:        sv.Name,
+				Compression: compression,
+				NoCow:       noCow,
+			})
+			if sv.Path == nil {
+				continue
+			}
+			mountFS := Filesystem{
+				Device:       fs.Device,
+				Format:       fs.Format,
+				MountOptions: append([]string{"subvol=" + sv.Name}, mountOptions...),
+				Path:         sv.Path,
+			}
+			mountUnits = append(mountUnits, mountUnitFromFS(mountFS, remote, []string{fs.subvolumeUnitName()}))
+			mountPaths = append(mountPaths, fsPath.Append("subvolumes", j))
+		}
+		contents := strings.Builder{}
+		if err := subvolumeUnitTemplate.Execute(&contents, context); err != nil {
+			panic(err)
+		}
+		svUnit := types.Unit{
+			Name:     fs.subvolumeUnitName(),
+			Enabled:  util.BoolToPtr(true),
+			Contents: util.StrToPtr(contents.String()),
+		}
+		renderedTranslations.AddFromCommonSource(fsPath.Append("subvolumes"), path.New("json", "systemd", "units", len(rendered.Systemd.Units)), svUnit)
+		rendered.Systemd.Units = append(rendered.Systemd.Units, svUnit)
+		for j, mountUnit := range mountUnits {
+			renderedTranslations.AddFromCommonSource(mountPaths[j], path.New("json", "systemd", "units", len(rendered.Systemd.Units)), mountUnit)
+			rendered.Systemd.Units = append(rendered.Systemd.Units, mountUnit)
+		}
+	}
+	if len(rendered.Systemd.Units) == 0 {
+		return
+	}
+	fromPath := path.New("yaml", "storage", "filesystems")
+	renderedTranslations.AddTranslation(fromPath, path.New("json", "systemd"))
+	renderedTranslations.AddTranslation(fromPath, path.New("json", "systemd", "units"))
+	retConfig, retTranslations := baseutil.MergeTranslatedConfigs(rendered, renderedTranslations, *config, *ts)
+	*config = retConfig.(types.Config)
+	*ts = retTranslations
+}
+
+// processSwap renders the units that create and enable the swap file, and
+// the zram-generator config for zram swap, for storage.swap.
+func (c Config) processSwap(config *types.Config, ts *translate.TranslationSet, options common.TranslateOptions) report.Report {
+	var r report.Report
+	swap := c.Storage.Swap
+	if !swap.File.wanted() && !swap.Zram.wanted() {
+		return r
+	}
+	var rendered types.Config
+	renderedTranslations := translate.NewTranslationSet("yaml", "json")
+	if swap.File.wanted() {
+		filePath := path.New("yaml", "storage", "swap", "file")
+		// unchecked derefs and parse ok, file would fail validation
+		// otherwise
+		sizeMiB, _ := baseutil.ParseSizeMiB(*swap.File.Size)
+		context := struct {
+			Path    string
+			Dir     string
+			SizeMiB int
+		}{
+			Path:    *swap.File.Path,
+			Dir:     slashpath.Dir(*swap.File.Path),
+			SizeMiB: sizeMiB,
+		}
+		contents := strings.Builder{}
+		if err := swapFileUnitTemplate.Execute(&contents, context); err != nil {
+			panic(err)
+		}
+		createUnit := types.Unit{
+			Name:     swap.File.unitName(),
+			Contents: util.StrToPtr(contents.String()),
+		}
+		fs := Filesystem{
+			Device: *swap.File.Path,
+			Format: util.StrToPtr("swap"),
+		}
+		swapUnit := mountUnitFromFS(fs, false, []string{swap.File.unitName()})
+		for _, u := range []types.Unit{createUnit, swapUnit} {
+			renderedTranslations.AddFromCommonSource(filePath, path.New("json", "systemd", "units", len(rendered.Systemd.Units)), u)
+			rendered.Systemd.Units = append(rendered.Systemd.Units, u)
+		}
+		renderedTranslations.AddTranslation(filePath, path.New("json", "systemd"))
+		renderedTranslations.AddTranslation(filePath, path.New("json", "systemd", "units"))
+	}
+	if swap.Zram.wanted() {
+		zramPath := path.New("yaml", "storage", "swap", "zram")
+		// unchecked deref and parse ok, zram would fail validation
+		// otherwise
+		size, _ := zramSize(*swap.Zram.Size)
+		context := struct {
+			Size      string
+			Algorithm *string
+		}{
+			Size:      size,
+			Algorithm: swap.Zram.Algorithm,
+		}
+		contents := strings.Builder{}
+		if err := zramConfigTemplate.Execute(&contents, context); err != nil {
+			panic(err)
+		}
+		src, compression, err := baseutil.MakeDataURLWithOptions([]byte(contents.String()), nil, options)
+		if err != nil {
+			r.AddOnError(zramPath, err)
+			return r
+		}
+		file := types.File{
+			Node: types.Node{
+				Path: "/etc/systemd/zram-generator.conf",
+			},
+			FileEmbedded1: types.FileEmbedded1{
+				Contents: types.Resource{
+					Source:      util.StrToPtr(src),
+					Compression: compression,
+				},
+				Mode: util.IntToPtr(0644),
+			},
+		}
+		renderedTranslations.AddFromCommonSource(zramPath, path.New("json", "storage", "files", len(rendered.Storage.Files)), file)
+		rendered.Storage.Files = append(rendered.Storage.Files, file)
+		renderedTranslations.AddTranslation(zramPath, path.New("json", "storage"))
+		renderedTranslations.AddTranslation(zramPath, path.New("json", "storage", "files"))
+	}
+	retConfig, retTranslations := baseutil.MergeTranslatedConfigs(rendered, renderedTranslations, *config, *ts)
+	*config = retConfig.(types.Config)
+	*ts = retTranslations
+	return r
+}
+
+// translateDataDeviceLuks translates the LUKS settings of a data device.
+// The caller fills in the device, label, and name.
+func translateDataDeviceLuks(from DataDeviceLuks, options common.TranslateOptions) (to types.Luks, tm translate.TranslationSet, r report.Report) {
+	tr := translate.NewTranslator("yaml", "json", options)
+	tr.AddCustomTranslator(translateResource)
+	tm, r = translate.Prefixed(tr, "discard", &from.Discard, &to.Discard)
+	translate.MergeP2(tr, tm, &r, "key_file", &from.KeyFile, "keyFile", &to.KeyFile)
+	// the clevis fields are flattened into the luks section
+	clevis := Clevis{
+		Tang:      from.Tang,
+		Threshold: from.Threshold,
+		Tpm2:      from.Tpm2,
+	}
+	tmClevis, rClevis := tr.Translate(&clevis, &to.Clevis)
+	tm.Merge(tmClevis.PrefixPaths(path.New("yaml"), path.New("json", "clevis")))
+	r.Merge(rClevis)
+	// we're being called manually, not via the translate package's
+	// custom translator mechanism, so we have to add the base
+	// translation ourselves
+	tm.AddTranslation(path.New("yaml"), path.New("json"))
+	return
+}
+
+// name returns the name of the partition, RAID array, and LUKS volume of
+// the data device, derived from its mount path.
+func (dd DataDevice) name() string {
+	if dd.Path == nil {
+		return ""
+	}
+	return strings.ReplaceAll(strings.Trim(*dd.Path, "/"), "/", "-")
+}
+
+func (l DataDeviceLuks) wanted() bool {
+	return len(l.Tang) > 0 || util.IsTrue(l.Tpm2) || l.KeyFile.Source != nil || l.KeyFile.Inline != nil || l.KeyFile.Local != nil
+}
+
+// unitName returns the name of the unit that creates the volume group.
+func (vg VolumeGroup) unitName() string {
+	return "butane-lvm-" + unit.UnitNameEscape(vg.Name) + ".service"
+}
+
+// lvDevice returns the device path of a logical volume in the volume
+// group.
+func (vg VolumeGroup) lvDevice(lv LogicalVolume) string {
+	return "/dev/" + vg.Name + "/" + lv.Name
+}
+
+// dmName returns the device-mapper name of a logical volume in the volume
+// group.
+func (vg VolumeGroup) dmName(lv LogicalVolume) string {
+	return strings.ReplaceAll(vg.Name, "-", "--") + "-" + strings.ReplaceAll(lv.Name, "-", "--")
+}
+
+// fills reports whether the logical volume fills the rest of the volume
+// group.
+func (lv LogicalVolume) fills() bool {
+	if lv.Size == nil {
+		return true
+	}
+	sizeMiB, err := baseutil.ParseSizeMiB(*lv.Size)
+	return err == nil && sizeMiB == 0
+}
+
+// subvolumeUnitName returns the name of the unit that creates the
+// subvolumes of the filesystem.
+func (fs Filesystem) subvolumeUnitName() string {
+	return "butane-btrfs-" + unit.UnitNamePathEscape(fs.Device) + ".service"
+}
+
+type subvolumeContext struct {
+	Name        string
+	Compression string
+	NoCow       bool
+}
+
+// properties separates the mount options of the subvolume that are set on
+// the subvolume when it's created, since btrfs would apply them to the
+// whole filesystem.  It returns the compression algorithm, whether to
+// disable copy-on-write, and the remaining mount options.
+func (sv Subvolume) properties() (compression string, noCow bool, mountOptions []string) {
+	for _, opt := range sv.MountOptions {
+		if algorithm, ok := subvolumeCompression[opt]; ok {
+			compression = algorithm
+		} else if opt == "nodatacow" {
+			noCow = true
+		} else {
+			mountOptions = append(mountOptions, opt)
+		}
+	}
+	return
+}
+
+func (f SwapFile) wanted() bool {
+	return f.Path != nil || f.Size != nil
+}
+
+// unitName returns the name of the unit that creates the swap file.
+func (f SwapFile) unitName() string {
+	return "butane-swapfile-" + unit.UnitNamePathEscape(*f.Path) + ".service"
+}
+
+func (z SwapZram) wanted() bool {
+	return z.Size != nil || z.Algorithm != nil
+}
+
+// zramSize converts a zram size, either a percentage of RAM or a binary
+// size, to a zram-generator size expression in MiB.
+func zramSize(size string) (string, error) {
+	if percent, ok := strings.CutSuffix(size, "%"); ok {
+		n, err := strconv.Atoi(percent)
+		if err != nil || n <= 0 {
+			return "", common.ErrZramSize
+		}
+		if n == 100 {
+			return "ram", nil
+		}
+		return fmt.Sprintf("ram * %d / 100", n), nil
+	}
+	sizeMiB, err := baseutil.ParseSizeMiB(size)
+	if err != nil || sizeMiB == 0 {
+		return "", common.ErrZramSize
+	}
+	return strconv.Itoa(sizeMiB), nil
+}
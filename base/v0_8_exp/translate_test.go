@@ -585,6 +585,59 @@ func TestTranslateFile(t *testing.T) {
 				NoResourceAutoCompression: true,
 			},
 		},
+		// Test keeping small text resources readable
+		{
+			File{
+				Path: "/foo",
+				Contents: Resource{
+					Inline: util.StrToPtr(zzz),
+				},
+				Append: []Resource{
+					{
+						Inline: util.StrToPtr(random),
+					},
+				},
+			},
+			types.File{
+				Node: types.Node{
+					Path: "/foo",
+				},
+				FileEmbedded1: types.FileEmbedded1{
+					Contents: types.Resource{
+						Source:      util.StrToPtr("data:," + zzz),
+						Compression: util.StrToPtr(""),
+					},
+					Append: []types.Resource{
+						{
+							Source:      util.StrToPtr(random_b64),
+							Compression: util.StrToPtr(""),
+						},
+					},
+				},
+			},
+			[]translate.Translation{
+				{
+					From: path.New("yaml", "contents", "inline"),
+					To:   path.New("json", "contents", "source"),
+				},
+				{
+					From: path.New("yaml", "contents", "inline"),
+					To:   path.New("json", "contents", "compression"),
+				},
+				{
+					From: path.New("yaml", "append", 0, "inline"),
+					To:   path.New("json", "append", 0, "source"),
+				},
+				{
+					From: path.New("yaml", "append", 0, "inline"),
+					To:   path.New("json", "append", 0, "compression"),
+				},
+			},
+			"",
+			common.TranslateOptions{
+				NoCompressUnder: 1024,
+			},
+		},
 	}
 
 	for i, test := range tests {
@@ -1199,6 +1252,550 @@ RequiredBy=swap.target`),
 	}
 }
 
+// TestTranslatePartition tests converting human-readable partition sizes
+// to mebibytes.
+func TestTranslatePartition(t *testing.T) {
+	in := Config{
+		Storage: Storage{
+			Disks: []Disk{
+				{
+					Device: "/dev/vdb",
+					Partitions: []Partition{
+						{
+							Label: util.StrToPtr("a"),
+							Size:  util.StrToPtr("20GiB"),
+							Start: util.StrToPtr("1G"),
+						},
+						{
+							Label: util.StrToPtr("b"),
+							Size:  util.StrToPtr("100%"),
+						},
+					},
+				},
+			},
+		},
+	}
+	expected := types.Config{
+		Ignition: types.Ignition{
+			Version: "3.7.0-experimental",
+		},
+		Storage: types.Storage{
+			Disks: []types.Disk{
+				{
+					Device: "/dev/vdb",
+					Partitions: []types.Partition{
+						{
+							Label:    util.StrToPtr("a"),
+							SizeMiB:  util.IntToPtr(20480),
+							StartMiB: util.IntToPtr(1024),
+						},
+						{
+							Label:   util.StrToPtr("b"),
+							SizeMiB: util.IntToPtr(0),
+						},
+					},
+				},
+			},
+		},
+	}
+	out, translations, r := in.ToIgn3_7Unvalidated(common.TranslateOptions{})
+	assert.Equal(t, expected, out, "bad output")
+	assert.Equal(t, report.Report{}, r, "expected empty report")
+	baseutil.VerifyTranslations(t, translations, []translate.Translation{
+		{From: path.New("yaml", "version"), To: path.New("json", "ignition", "version")},
+		{From: path.New("yaml", "storage", "disks", 0, "partitions", 0, "size"), To: path.New("json", "storage", "disks", 0, "partitions", 0, "sizeMiB")},
+		{From: path.New("yaml", "storage", "disks", 0, "partitions", 0, "start"), To: path.New("json", "storage", "disks", 0, "partitions", 0, "startMiB")},
+		{From: path.New("yaml", "storage", "disks", 0, "partitions", 1, "size"), To: path.New("json", "storage", "disks", 0, "partitions", 1, "sizeMiB")},
+	})
+	assert.NoError(t, translations.DebugVerifyCoverage(out), "incomplete TranslationSet coverage")
+}
+
+// TestTranslateDataDevices tests translating storage.data_devices to
+// partitions, RAID arrays, LUKS volumes, filesystems, and mount units.
+func TestTranslateDataDevices(t *testing.T) {
+	tests := []struct {
+		in  Config
+		out types.Config
+	}{
+		// single device with mount options
+		{
+			Config{
+				Storage: Storage{
+					DataDevices: []DataDevice{
+						{
+							Device:       util.StrToPtr("/dev/vdb"),
+							MountOptions: []string{"noatime"},
+							Path:         util.StrToPtr("/var/lib/data"),
+						},
+					},
+				},
+			},
+			types.Config{
+				Ignition: types.Ignition{
+					Version: "3.7.0-experimental",
+				},
+				Storage: types.Storage{
+					Disks: []types.Disk{
+						{
+							Device: "/dev/vdb",
+							Partitions: []types.Partition{
+								{
+									Label:  util.StrToPtr("var-lib-data"),
+									Number: 1,
+								},
+							},
+						},
+					},
+					Filesystems: []types.Filesystem{
+						{
+							Device:       "/dev/disk/by-partlabel/var-lib-data",
+							Format:       util.StrToPtr("xfs"),
+							MountOptions: []types.MountOption{"noatime"},
+							Path:         util.StrToPtr("/var/lib/data"),
+						},
+					},
+				},
+				Systemd: types.Systemd{
+					Units: []types.Unit{
+						{
+							Enabled: util.BoolToPtr(true),
+							Contents: util.StrToPtr(`# Generated by Butane
+[Unit]
+Requires=systemd-fsck@dev-disk-by\x2dpartlabel-var\x2dlib\x2ddata.service
+After=systemd-fsck@dev-disk-by\x2dpartlabel-var\x2dlib\x2ddata.service
+
+[Mount]
+Where=/var/lib/data
+What=/dev/disk/by-partlabel/var-lib-data
+Type=xfs
+Options=noatime
+
+[Install]
+RequiredBy=local-fs.target`),
+							Name: "var-lib-data.mount",
+						},
+					},
+				},
+			},
+		},
+		// encrypted RAID, with the disk table wipe overridden
+		{
+			Config{
+				Storage: Storage{
+					DataDevices: []DataDevice{
+						{
+							Format: util.StrToPtr("ext4"),
+							Luks: DataDeviceLuks{
+								KeyFile: Resource{
+									Inline: util.StrToPtr("secret"),
+								},
+								Tpm2: util.BoolToPtr(true),
+							},
+							Path: util.StrToPtr("/var/srv"),
+							Raid: DataDeviceRaid{
+								Devices: []Device{"/dev/vdb", "/dev/vdc"},
+								Level:   util.StrToPtr("raid0"),
+							},
+						},
+					},
+					Disks: []Disk{
+						{
+							Device:    "/dev/vdc",
+							WipeTable: util.BoolToPtr(true),
+						},
+					},
+				},
+			},
+			types.Config{
+				Ignition: types.Ignition{
+					Version: "3.7.0-experimental",
+				},
+				Storage: types.Storage{
+					Disks: []types.Disk{
+						{
+							Device: "/dev/vdb",
+							Partitions: []types.Partition{
+								{
+									Label:  util.StrToPtr("var-srv-1"),
+									Number: 1,
+								},
+							},
+						},
+						{
+							Device: "/dev/vdc",
+							Partitions: []types.Partition{
+								{
+									Label:  util.StrToPtr("var-srv-2"),
+									Number: 1,
+								},
+							},
+							WipeTable: util.BoolToPtr(true),
+						},
+					},
+					Filesystems: []types.Filesystem{
+						{
+							Device: "/dev/mapper/var-srv",
+							Format: util.StrToPtr("ext4"),
+							Path:   util.StrToPtr("/var/srv"),
+						},
+					},
+					Luks: []types.Luks{
+						{
+							Clevis: types.Clevis{
+								Tpm2: util.BoolToPtr(true),
+							},
+							Device: util.StrToPtr("/dev/md/var-srv"),
+							KeyFile: types.Resource{
+								Compression: util.StrToPtr(""),
+								Source:      util.StrToPtr("data:,secret"),
+							},
+							Label: util.StrToPtr("luks-var-srv"),
+							Name:  "var-srv",
+						},
+					},
+					Raid: []types.Raid{
+						{
+							Devices: []types.Device{
+								"/dev/disk/by-partlabel/var-srv-1",
+								"/dev/disk/by-partlabel/var-srv-2",
+							},
+							Level: util.StrToPtr("raid0"),
+							Name:  "var-srv",
+						},
+					},
+				},
+				Systemd: types.Systemd{
+					Units: []types.Unit{
+						{
+							Enabled: util.BoolToPtr(true),
+							Contents: util.StrToPtr(`# Generated by Butane
+[Unit]
+Requires=systemd-fsck@dev-mapper-var\x2dsrv.service
+After=systemd-fsck@dev-mapper-var\x2dsrv.service
+Requires=dev-md-var\x2dsrv.device
+After=dev-md-var\x2dsrv.device
+Requires=systemd-cryptsetup@var\x2dsrv.service
+After=systemd-cryptsetup@var\x2dsrv.service
+
+[Mount]
+Where=/var/srv
+What=/dev/mapper/var-srv
+Type=ext4
+
+[Install]
+RequiredBy=local-fs.target`),
+							Name: "var-srv.mount",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("translate %d", i), func(t *testing.T) {
+			out, translations, r := test.in.ToIgn3_7Unvalidated(common.TranslateOptions{})
+			r = confutil.TranslateReportPaths(r, translations)
+			baseutil.VerifyReport(t, test.in, r)
+			assert.Equal(t, test.out, out, "bad output")
+			assert.Equal(t, report.Report{}, r, "expected empty report")
+			assert.NoError(t, translations.DebugVerifyCoverage(out), "incomplete TranslationSet coverage")
+		})
+	}
+}
+
+// TestTranslateLvm tests translating storage.lvm into volume group, mount,
+// and swap units.
+func TestTranslateLvm(t *testing.T) {
+	in := Config{
+		Storage: Storage{
+			Lvm: []VolumeGroup{
+				{
+					Name:    "vg0",
+					Devices: []Device{"/dev/disk/by-partlabel/pv1", "/dev/vdc"},
+					LogicalVolumes: []LogicalVolume{
+						{
+							Name:   "swap",
+							Size:   util.StrToPtr("4GiB"),
+							Format: util.StrToPtr("swap"),
+						},
+						{
+							Name:         "data",
+							Size:         util.StrToPtr("20GiB"),
+							Format:       util.StrToPtr("xfs"),
+							Path:         util.StrToPtr("/var/lib/data"),
+							MountOptions: []string{"noatime"},
+						},
+						{
+							Name: "scratch",
+						},
+					},
+				},
+			},
+		},
+	}
+	expected := types.Config{
+		Ignition: types.Ignition{
+			Version: "3.7.0-experimental",
+		},
+		Systemd: types.Systemd{
+			Units: []types.Unit{
+				{
+					Contents: util.StrToPtr(`# Generated by Butane
+[Unit]
+Description=Create LVM volume group vg0
+DefaultDependencies=no
+Requires=dev-disk-by\x2dpartlabel-pv1.device
+After=dev-disk-by\x2dpartlabel-pv1.device
+Requires=dev-vdc.device
+After=dev-vdc.device
+Before=shutdown.target
+Conflicts=shutdown.target
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/bin/sh -c 'vgs vg0 >/dev/null 2>&1 || vgcreate vg0 /dev/disk/by-partlabel/pv1 /dev/vdc'
+ExecStart=vgchange --activate y vg0
+ExecStart=/bin/sh -c 'lvs vg0/swap >/dev/null 2>&1 || lvcreate --yes --name swap --size 4096m vg0'
+ExecStart=/bin/sh -c '[ -n "$$(blkid --probe --match-tag TYPE --output value /dev/vg0/swap)" ] || mkswap /dev/vg0/swap'
+ExecStart=/bin/sh -c 'lvs vg0/data >/dev/null 2>&1 || lvcreate --yes --name data --size 20480m vg0'
+ExecStart=/bin/sh -c '[ -n "$$(blkid --probe --match-tag TYPE --output value /dev/vg0/data)" ] || mkfs.xfs /dev/vg0/data'
+ExecStart=/bin/sh -c 'lvs vg0/scratch >/dev/null 2>&1 || lvcreate --yes --name scratch --extents 100%%FREE vg0'
+
+[Install]
+WantedBy=local-fs.target`),
+					Enabled: util.BoolToPtr(true),
+					Name:    "butane-lvm-vg0.service",
+				},
+				{
+					Contents: util.StrToPtr(`# Generated by Butane
+[Unit]
+Requires=butane-lvm-vg0.service
+After=butane-lvm-vg0.service
+
+[Swap]
+What=/dev/vg0/swap
+
+[Install]
+RequiredBy=swap.target`),
+					Enabled: util.BoolToPtr(true),
+					Name:    "dev-vg0-swap.swap",
+				},
+				{
+					Contents: util.StrToPtr(`# Generated by Butane
+[Unit]
+Requires=systemd-fsck@dev-vg0-data.service
+After=systemd-fsck@dev-vg0-data.service
+Requires=butane-lvm-vg0.service
+After=butane-lvm-vg0.service
+
+[Mount]
+Where=/var/lib/data
+What=/dev/vg0/data
+Type=xfs
+Options=noatime
+
+[Install]
+RequiredBy=local-fs.target`),
+					Enabled: util.BoolToPtr(true),
+					Name:    "var-lib-data.mount",
+				},
+			},
+		},
+	}
+
+	out, translations, r := in.ToIgn3_7Unvalidated(common.TranslateOptions{})
+	r = confutil.TranslateReportPaths(r, translations)
+	baseutil.VerifyReport(t, in, r)
+	assert.Equal(t, expected, out, "bad output")
+	assert.Equal(t, report.Report{}, r, "expected empty report")
+	assert.Equal(t, path.New("yaml", "storage", "lvm", 0), translations.Set[path.New("json", "systemd", "units", 0, "contents").String()].From, "bad translation")
+	assert.Equal(t, path.New("yaml", "storage", "lvm", 0, "logical_volumes", 1), translations.Set[path.New("json", "systemd", "units", 2, "name").String()].From, "bad translation")
+	assert.NoError(t, translations.DebugVerifyCoverage(out), "incomplete TranslationSet coverage")
+}
+
+func TestTranslateSubvolumes(t *testing.T) {
+	in := Config{
+		Storage: Storage{
+			Filesystems: []Filesystem{
+				{
+					Device: "/dev/disk/by-partlabel/data",
+					Format: util.StrToPtr("btrfs"),
+					Subvolumes: []Subvolume{
+						{
+							Name:         "containers",
+							Path:         util.StrToPtr("/var/lib/containers"),
+							MountOptions: []string{"compress=zstd", "noatime"},
+						},
+						{
+							Name:         "snapshots",
+							MountOptions: []string{"nodatacow"},
+						},
+					},
+				},
+			},
+		},
+	}
+	expected := types.Config{
+		Ignition: types.Ignition{
+			Version: "3.7.0-experimental",
+		},
+		Storage: types.Storage{
+			Filesystems: []types.Filesystem{
+				{
+					Device: "/dev/disk/by-partlabel/data",
+					Format: util.StrToPtr("btrfs"),
+				},
+			},
+		},
+		Systemd: types.Systemd{
+			Units: []types.Unit{
+				{
+					Contents: util.StrToPtr(`# Generated by Butane
+[Unit]
+Description=Create btrfs subvolumes on /dev/disk/by-partlabel/data
+DefaultDependencies=no
+Requires=dev-disk-by\x2dpartlabel-data.device
+After=dev-disk-by\x2dpartlabel-data.device
+Before=shutdown.target
+Conflicts=shutdown.target
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+PrivateMounts=yes
+ExecStartPre=mkdir -p /run/butane-btrfs
+ExecStartPre=mount -t btrfs -o subvolid=5 /dev/disk/by-partlabel/data /run/butane-btrfs
+ExecStart=/bin/sh -c '[ -e /run/butane-btrfs/containers ] || { btrfs subvolume create /run/butane-btrfs/containers && btrfs property set /run/butane-btrfs/containers compression zstd; }'
+ExecStart=/bin/sh -c '[ -e /run/butane-btrfs/snapshots ] || { btrfs subvolume create /run/butane-btrfs/snapshots && chattr +C /run/butane-btrfs/snapshots; }'
+ExecStartPost=umount /run/butane-btrfs
+
+[Install]
+WantedBy=local-fs.target`),
+					Enabled: util.BoolToPtr(true),
+					Name:    "butane-btrfs-dev-disk-by\\x2dpartlabel-data.service",
+				},
+				{
+					Contents: util.StrToPtr(`# Generated by Butane
+[Unit]
+Requires=systemd-fsck@dev-disk-by\x2dpartlabel-data.service
+After=systemd-fsck@dev-disk-by\x2dpartlabel-data.service
+Requires=butane-btrfs-dev-disk-by\x2dpartlabel-data.service
+After=butane-btrfs-dev-disk-by\x2dpartlabel-data.service
+
+[Mount]
+Where=/var/lib/containers
+What=/dev/disk/by-partlabel/data
+Type=btrfs
+Options=subvol=containers,noatime
+
+[Install]
+RequiredBy=local-fs.target`),
+					Enabled: util.BoolToPtr(true),
+					Name:    "var-lib-containers.mount",
+				},
+			},
+		},
+	}
+
+	out, translations, r := in.ToIgn3_7Unvalidated(common.TranslateOptions{})
+	r = confutil.TranslateReportPaths(r, translations)
+	baseutil.VerifyReport(t, in, r)
+	assert.Equal(t, expected, out, "bad output")
+	assert.Equal(t, report.Report{}, r, "expected empty report")
+	assert.Equal(t, path.New("yaml", "storage", "filesystems", 0, "subvolumes"), translations.Set[path.New("json", "systemd", "units", 0, "contents").String()].From, "bad translation")
+	assert.Equal(t, path.New("yaml", "storage", "filesystems", 0, "subvolumes", 0), translations.Set[path.New("json", "systemd", "units", 1, "name").String()].From, "bad translation")
+	assert.NoError(t, translations.DebugVerifyCoverage(out), "incomplete TranslationSet coverage")
+}
+
+func TestTranslateSwap(t *testing.T) {
+	in := Config{
+		Storage: Storage{
+			Swap: Swap{
+				File: SwapFile{
+					Path: util.StrToPtr("/var/swapfile"),
+					Size: util.StrToPtr("2GiB"),
+				},
+				Zram: SwapZram{
+					Size:      util.StrToPtr("50%"),
+					Algorithm: util.StrToPtr("zstd"),
+				},
+			},
+		},
+	}
+	expected := types.Config{
+		Ignition: types.Ignition{
+			Version: "3.7.0-experimental",
+		},
+		Storage: types.Storage{
+			Files: []types.File{
+				{
+					Node: types.Node{
+						Path: "/etc/systemd/zram-generator.conf",
+					},
+					FileEmbedded1: types.FileEmbedded1{
+						Contents: types.Resource{
+							Source:      util.StrToPtr("data:,%23%20Generated%20by%20Butane%0A%5Bzram0%5D%0Azram-size%20%3D%20ram%20*%2050%20%2F%20100%0Acompression-algorithm%20%3D%20zstd%0A"),
+							Compression: util.StrToPtr(""),
+						},
+						Mode: util.IntToPtr(0644),
+					},
+				},
+			},
+		},
+		Systemd: types.Systemd{
+			Units: []types.Unit{
+				{
+					Contents: util.StrToPtr(`# Generated by Butane
+[Unit]
+Description=Create swap file /var/swapfile
+DefaultDependencies=no
+RequiresMountsFor=/var
+ConditionPathExists=!/var/swapfile
+Before=shutdown.target
+Conflicts=shutdown.target
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=truncate --size 0 /var/swapfile.tmp
+ExecStart=-chattr -c +C /var/swapfile.tmp
+ExecStart=fallocate --length 2048MiB /var/swapfile.tmp
+ExecStart=chmod 0600 /var/swapfile.tmp
+ExecStart=-chcon --type swapfile_t /var/swapfile.tmp
+ExecStart=mkswap /var/swapfile.tmp
+ExecStart=mv /var/swapfile.tmp /var/swapfile`),
+					Name: "butane-swapfile-var-swapfile.service",
+				},
+				{
+					Contents: util.StrToPtr(`# Generated by Butane
+[Unit]
+Requires=butane-swapfile-var-swapfile.service
+After=butane-swapfile-var-swapfile.service
+
+[Swap]
+What=/var/swapfile
+
+[Install]
+RequiredBy=swap.target`),
+					Enabled: util.BoolToPtr(true),
+					Name:    "var-swapfile.swap",
+				},
+			},
+		},
+	}
+
+	out, translations, r := in.ToIgn3_7Unvalidated(common.TranslateOptions{NoCompressUnder: 1024})
+	r = confutil.TranslateReportPaths(r, translations)
+	baseutil.VerifyReport(t, in, r)
+	assert.Equal(t, expected, out, "bad output")
+	assert.Equal(t, report.Report{}, r, "expected empty report")
+	assert.Equal(t, path.New("yaml", "storage", "swap", "file"), translations.Set[path.New("json", "systemd", "units", 1, "name").String()].From, "bad translation")
+	assert.Equal(t, path.New("yaml", "storage", "swap", "zram"), translations.Set[path.New("json", "storage", "files", 0, "contents", "source").String()].From, "bad translation")
+	assert.NoError(t, translations.DebugVerifyCoverage(out), "incomplete TranslationSet coverage")
+}
+
 // TestTranslateTree tests translating the butane storage.trees.[i] entries to ignition storage.files.[i] entries.
 func TestTranslateTree(t *testing.T) {
 	tests := []struct {
@@ -1905,13 +2502,17 @@ func TestTranslateIgnition(t *testing.T) {
 		{
 			Ignition{
 				Config: IgnitionConfig{
-					Merge: []Resource{
+					Merge: []ConfigResource{
 						{
-							Inline: util.StrToPtr("xyzzy"),
+							Resource: Resource{
+								Inline: util.StrToPtr("xyzzy"),
+							},
 						},
 					},
-					Replace: Resource{
-						Inline: util.StrToPtr("xyzzy"),
+					Replace: ConfigResource{
+						Resource: Resource{
+							Inline: util.StrToPtr("xyzzy"),
+						},
 					},
 				},
 			},
@@ -1993,6 +2594,170 @@ func TestTranslateIgnition(t *testing.T) {
 // ignition kernelArguments.{shouldExist,shouldNotExist}.[i] entries.
 //
 // KernelArguments do not use a custom translation function (it utilizes the MergeP2 functionality) so pass an entire config
+// TestTranslateNestedConfig tests translating Butane configs nested in
+// ignition.config.merge and ignition.config.replace.
+func TestTranslateNestedConfig(t *testing.T) {
+	filesDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(filesDir, "child.bu"), []byte("child"), 0644); err != nil {
+		t.Error(err)
+		return
+	}
+	// fake translator that wraps its input, or fails on "bad"
+	nested := func(input []byte, options common.TranslateBytesOptions) ([]byte, report.Report, error) {
+		var r report.Report
+		if string(input) == "bad" {
+			r.AddOnError(path.New("yaml", "storage"), common.ErrTooManyResourceSources)
+			return nil, r, common.ErrInvalidSourceConfig
+		}
+		r.AddOnWarn(path.New("yaml", "systemd"), common.ErrNodeExists)
+		return []byte("<" + string(input) + ">"), r, nil
+	}
+	warning := func(name string) string {
+		return common.ErrNestedConfig{
+			Name:   name,
+			Detail: "warning at $.systemd: " + common.ErrNodeExists.Error(),
+		}.Error()
+	}
+
+	tests := []struct {
+		in      Ignition
+		out     types.Ignition
+		options common.TranslateOptions
+		report  report.Report
+	}{
+		// local and inline nested configs
+		{
+			Ignition{
+				Config: IgnitionConfig{
+					Merge: []ConfigResource{
+						{
+							ButaneLocal: util.StrToPtr("child.bu"),
+						},
+					},
+					Replace: ConfigResource{
+						ButaneInline: util.StrToPtr("inline child"),
+					},
+				},
+			},
+			types.Ignition{
+				Version: "3.7.0-experimental",
+				Config: types.IgnitionConfig{
+					Merge: []types.Resource{
+						{
+							Source:      util.StrToPtr("data:,%3Cchild%3E"),
+							Compression: util.StrToPtr(""),
+						},
+					},
+					Replace: types.Resource{
+						Source:      util.StrToPtr("data:,%3Cinline%20child%3E"),
+						Compression: util.StrToPtr(""),
+					},
+				},
+			},
+			common.TranslateOptions{
+				FilesDir:         filesDir,
+				NestedTranslator: nested,
+			},
+			report.Report{
+				Entries: []report.Entry{
+					{
+						Kind: common.NestedEntryKind{
+							EntryKind: report.Warn,
+							Source:    "child.bu",
+							Context:   path.New("yaml", "systemd"),
+						},
+						Message: warning("child.bu"),
+						Context: path.New("yaml", "config", "merge", 0, "butane_local"),
+					},
+					{
+						Kind: common.NestedEntryKind{
+							EntryKind: report.Warn,
+							Source:    "inline",
+							Context:   path.New("yaml", "systemd"),
+						},
+						Message: warning("inline"),
+						Context: path.New("yaml", "config", "replace", "butane_inline"),
+					},
+				},
+			},
+		},
+		// nested config fails to translate
+		{
+			Ignition{
+				Config: IgnitionConfig{
+					Merge: []ConfigResource{
+						{
+							ButaneInline: util.StrToPtr("bad"),
+						},
+					},
+				},
+			},
+			types.Ignition{
+				Version: "3.7.0-experimental",
+				Config: types.IgnitionConfig{
+					Merge: []types.Resource{
+						{},
+					},
+				},
+			},
+			common.TranslateOptions{
+				NestedTranslator: nested,
+			},
+			report.Report{
+				Entries: []report.Entry{
+					{
+						Kind: common.NestedEntryKind{
+							EntryKind: report.Error,
+							Source:    "inline",
+							Context:   path.New("yaml", "storage"),
+						},
+						Message: common.ErrNestedConfig{
+							Name:   "inline",
+							Detail: "error at $.storage: " + common.ErrTooManyResourceSources.Error(),
+						}.Error(),
+						Context: path.New("yaml", "config", "merge", 0, "butane_inline"),
+					},
+				},
+			},
+		},
+		// no translator
+		{
+			Ignition{
+				Config: IgnitionConfig{
+					Replace: ConfigResource{
+						ButaneInline: util.StrToPtr("child"),
+					},
+				},
+			},
+			types.Ignition{
+				Version: "3.7.0-experimental",
+			},
+			common.TranslateOptions{},
+			report.Report{
+				Entries: []report.Entry{
+					{
+						Kind:    report.Error,
+						Message: common.ErrNoNestedTranslator.Error(),
+						Context: path.New("yaml", "config", "replace", "butane_inline"),
+					},
+				},
+			},
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("translate %d", i), func(t *testing.T) {
+			actual, translations, r := translateIgnition(test.in, test.options)
+			r = confutil.TranslateReportPaths(r, translations)
+			assert.Equal(t, test.out, actual, "translation mismatch")
+			assert.Equal(t, test.report, r, "bad report")
+			if !r.IsFatal() {
+				translations.AddTranslation(path.New("yaml", "bogus"), path.New("json", "version"))
+				assert.NoError(t, translations.DebugVerifyCoverage(actual), "incomplete TranslationSet coverage")
+			}
+		})
+	}
+}
+
 func TestTranslateKernelArguments(t *testing.T) {
 	tests := []struct {
 		in  Config
@@ -2905,3 +3670,155 @@ WantedBy=multi-user.target`
 		})
 	}
 }
+
+// TestCheckStorageReferences tests the warnings for dangling and reused
+// storage device references.
+func TestCheckStorageReferences(t *testing.T) {
+	type entry struct {
+		err  error
+		path path.ContextPath
+	}
+	image := ImageDevices{
+		PartLabels: []string{"root"},
+		FsLabels:   []string{"root"},
+	}
+	tests := []struct {
+		in      types.Config
+		entries []entry
+	}{
+		// declared and image devices
+		{
+			types.Config{
+				Storage: types.Storage{
+					Disks: []types.Disk{
+						{
+							Device: "/dev/vdb",
+							Partitions: []types.Partition{
+								{Label: util.StrToPtr("data-1")},
+								{Label: util.StrToPtr("data-2")},
+								{Label: util.StrToPtr("crypt")},
+							},
+						},
+					},
+					Raid: []types.Raid{
+						{
+							Name:    "data",
+							Devices: []types.Device{"/dev/disk/by-partlabel/data-1", "/dev/disk/by-partlabel/data-2"},
+						},
+					},
+					Luks: []types.Luks{
+						{
+							Name:   "crypt",
+							Device: util.StrToPtr("/dev/disk/by-partlabel/crypt"),
+							Label:  util.StrToPtr("crypt-luks"),
+						},
+					},
+					Filesystems: []types.Filesystem{
+						{
+							Device: "/dev/md/data",
+							Path:   util.StrToPtr("/var/lib/data"),
+						},
+						{
+							Device: "/dev/mapper/crypt",
+							Path:   util.StrToPtr("/var/lib/crypt"),
+						},
+						{
+							Device: "/dev/disk/by-label/root",
+							Format: util.StrToPtr("xfs"),
+						},
+						{
+							Device: "/dev/vdc",
+						},
+					},
+				},
+			},
+			nil,
+		},
+		// dangling references
+		{
+			types.Config{
+				Storage: types.Storage{
+					Disks: []types.Disk{
+						{
+							Device: "/dev/vdb",
+							Partitions: []types.Partition{
+								{Label: util.StrToPtr("data")},
+								{Label: util.StrToPtr("gone"), Number: 2, ShouldExist: util.BoolToPtr(false)},
+							},
+						},
+					},
+					Raid: []types.Raid{
+						{
+							Name:    "md",
+							Devices: []types.Device{"/dev/disk/by-partlabel/gone", "/dev/disk/by-partlabel/data"},
+						},
+					},
+					Luks: []types.Luks{
+						{
+							Name:   "crypt",
+							Device: util.StrToPtr("/dev/disk/by-label/nope"),
+						},
+					},
+					Filesystems: []types.Filesystem{
+						{
+							Device: "/dev/md/mdd",
+						},
+						{
+							Device: "/dev/mapper/crpyt",
+						},
+					},
+				},
+			},
+			[]entry{
+				{common.ErrUnknownRaidName, path.New("json", "storage", "filesystems", 0, "device")},
+				{common.ErrUnknownLuksName, path.New("json", "storage", "filesystems", 1, "device")},
+				{common.ErrUnknownFsLabel, path.New("json", "storage", "luks", 0, "device")},
+				{common.ErrUnknownPartLabel, path.New("json", "storage", "raid", 0, "devices", 0)},
+			},
+		},
+		// reused devices and mount paths
+		{
+			types.Config{
+				Storage: types.Storage{
+					Luks: []types.Luks{
+						{
+							Name:   "crypt",
+							Device: util.StrToPtr("/dev/vdb"),
+						},
+					},
+					Filesystems: []types.Filesystem{
+						{
+							Device: "/dev/vdb",
+							Path:   util.StrToPtr("/var/lib/data"),
+						},
+						{
+							Device: "/dev/mapper/crypt",
+							Path:   util.StrToPtr("/var/lib/data/"),
+						},
+						{
+							Device: "/dev/disk/by-label/root",
+						},
+						{
+							Device: "/dev/disk/by-label/root/",
+						},
+					},
+				},
+			},
+			[]entry{
+				{common.ErrDeviceReused, path.New("json", "storage", "filesystems", 3, "device")},
+				{common.ErrDeviceReused, path.New("json", "storage", "luks", 0, "device")},
+				{common.ErrMountPathReused, path.New("json", "storage", "filesystems", 1, "path")},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("check %d", i), func(t *testing.T) {
+			var expected report.Report
+			for _, entry := range test.entries {
+				expected.AddOnWarn(entry.path, entry.err)
+			}
+			assert.Equal(t, expected, CheckStorageReferences(test.in, image), "bad report")
+		})
+	}
+}
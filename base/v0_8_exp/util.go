@@ -15,6 +15,9 @@
 package v0_8_exp
 
 import (
+	"path/filepath"
+	"strings"
+
 	common "github.com/coreos/butane/config/common"
 	"github.com/coreos/ignition/v2/config/shared/errors"
 	"github.com/coreos/ignition/v2/config/util"
@@ -156,3 +159,111 @@ func ValidateIgnitionConfig(c path.ContextPath, rawConfig []byte) (report.Report
 	}
 	return r, nil
 }
+
+// ImageDevices describes the partitions and filesystems that the OS image
+// provides without being declared in the config.
+type ImageDevices struct {
+	PartLabels []string
+	FsLabels   []string
+}
+
+// storageRef is a use of a device by a filesystem, LUKS volume, or RAID
+// array.
+type storageRef struct {
+	device     string
+	path       path.ContextPath
+	filesystem bool
+}
+
+// CheckStorageReferences warns about filesystems, LUKS volumes, and RAID
+// arrays that reference partition labels, filesystem labels, LUKS
+// volumes, or RAID arrays that aren't declared in the config or provided
+// by the OS image, and about devices or mount paths used more than once.
+// It should be run after all sugar has been translated.
+func CheckStorageReferences(config types.Config, image ImageDevices) report.Report {
+	var r report.Report
+	partLabels := map[string]bool{}
+	fsLabels := map[string]bool{}
+	luksNames := map[string]bool{}
+	raidNames := map[string]bool{}
+	for _, label := range image.PartLabels {
+		partLabels[label] = true
+	}
+	for _, label := range image.FsLabels {
+		fsLabels[label] = true
+	}
+	for _, disk := range config.Storage.Disks {
+		for _, part := range disk.Partitions {
+			if part.Label != nil && (part.ShouldExist == nil || *part.ShouldExist) {
+				partLabels[*part.Label] = true
+			}
+		}
+	}
+	for _, fs := range config.Storage.Filesystems {
+		if fs.Label != nil {
+			fsLabels[*fs.Label] = true
+		}
+	}
+	for _, luks := range config.Storage.Luks {
+		luksNames[luks.Name] = true
+		if luks.Label != nil {
+			fsLabels[*luks.Label] = true
+		}
+	}
+	for _, raid := range config.Storage.Raid {
+		raidNames[raid.Name] = true
+	}
+
+	var refs []storageRef
+	for i, fs := range config.Storage.Filesystems {
+		if fs.Device != "" {
+			refs = append(refs, storageRef{fs.Device, path.New("json", "storage", "filesystems", i, "device"), true})
+		}
+	}
+	for i, luks := range config.Storage.Luks {
+		if luks.Device != nil && *luks.Device != "" {
+			refs = append(refs, storageRef{*luks.Device, path.New("json", "storage", "luks", i, "device"), false})
+		}
+	}
+	for i, raid := range config.Storage.Raid {
+		for j, dev := range raid.Devices {
+			refs = append(refs, storageRef{string(dev), path.New("json", "storage", "raid", i, "devices", j), false})
+		}
+	}
+
+	claimed := map[string]storageRef{}
+	for _, ref := range refs {
+		device := filepath.Clean(ref.device)
+		if name, ok := strings.CutPrefix(device, "/dev/disk/by-partlabel/"); ok && !partLabels[name] {
+			r.AddOnWarn(ref.path, common.ErrUnknownPartLabel)
+		} else if name, ok := strings.CutPrefix(device, "/dev/disk/by-label/"); ok && !fsLabels[name] {
+			r.AddOnWarn(ref.path, common.ErrUnknownFsLabel)
+		} else if name, ok := strings.CutPrefix(device, "/dev/mapper/"); ok && !luksNames[name] {
+			r.AddOnWarn(ref.path, common.ErrUnknownLuksName)
+		} else if name, ok := strings.CutPrefix(device, "/dev/md/"); ok && !raidNames[name] {
+			r.AddOnWarn(ref.path, common.ErrUnknownRaidName)
+		}
+		if prev, ok := claimed[device]; ok {
+			// Ignition already rejects filesystems with the same
+			// device string
+			if !(prev.filesystem && ref.filesystem && prev.device == ref.device) {
+				r.AddOnWarn(ref.path, common.ErrDeviceReused)
+			}
+		} else {
+			claimed[device] = ref
+		}
+	}
+
+	mounts := map[string]bool{}
+	for i, fs := range config.Storage.Filesystems {
+		if fs.Path == nil || *fs.Path == "" {
+			continue
+		}
+		mountPath := filepath.Clean(*fs.Path)
+		if mounts[mountPath] {
+			r.AddOnWarn(path.New("json", "storage", "filesystems", i, "path"), common.ErrMountPathReused)
+		}
+		mounts[mountPath] = true
+	}
+	return r
+}
@@ -15,6 +15,8 @@
 package v0_8_exp
 
 import (
+	"regexp"
+	"slices"
 	"strings"
 
 	baseutil "github.com/coreos/butane/base/util"
@@ -25,6 +27,32 @@ import (
 	"github.com/coreos/vcontext/report"
 )
 
+var (
+	lvmNameRe   = regexp.MustCompile(`^[A-Za-z0-9+_.][A-Za-z0-9+_.-]*$`)
+	lvmDeviceRe = regexp.MustCompile("^/[^\\s'\"%$\\\\`]+$")
+	lvmFormats  = map[string]bool{"btrfs": true, "ext4": true, "swap": true, "vfat": true, "xfs": true}
+
+	subvolumeNameRe = regexp.MustCompile(`^[A-Za-z0-9+_.@-]+(/[A-Za-z0-9+_.@-]+)*$`)
+	// compress mount options that can be set as the compression
+	// property of a subvolume, and their algorithms
+	subvolumeCompression = map[string]string{"compress": "zlib", "compress=lzo": "lzo", "compress=zlib": "zlib", "compress=zstd": "zstd"}
+	// btrfs mount options, without any "=value" suffix, that apply to
+	// the whole filesystem from its first mount
+	btrfsFilesystemOptions = map[string]bool{
+		"autodefrag": true, "noautodefrag": true, "barrier": true, "nobarrier": true,
+		"clear_cache": true, "commit": true, "compress": true, "compress-force": true,
+		"datacow": true, "nodatacow": true, "datasum": true, "nodatasum": true,
+		"degraded": true, "device": true, "discard": true, "nodiscard": true,
+		"fatal_errors": true, "flushoncommit": true, "noflushoncommit": true,
+		"max_inline": true, "metadata_ratio": true, "rescue": true, "skip_balance": true,
+		"space_cache": true, "nospace_cache": true, "ssd": true, "nossd": true,
+		"ssd_spread": true, "nossd_spread": true, "thread_pool": true,
+		"treelog": true, "notreelog": true, "user_subvol_rm_allowed": true,
+	}
+
+	zramAlgorithms = map[string]bool{"842": true, "deflate": true, "lz4": true, "lz4hc": true, "lzo": true, "lzo-rle": true, "zstd": true}
+)
+
 func (rs Resource) Validate(c path.ContextPath) (r report.Report) {
 	var field string
 	sources := 0
@@ -58,7 +86,40 @@ func (rs Resource) Validate(c path.ContextPath) (r report.Report) {
 	return
 }
 
+func (rs ConfigResource) Validate(c path.ContextPath) (r report.Report) {
+	var field string
+	sources := 0
+	if rs.Local != nil || rs.Inline != nil || rs.Source != nil {
+		sources++
+	}
+	if rs.ButaneInline != nil {
+		sources++
+		field = "butane_inline"
+	}
+	if rs.ButaneLocal != nil {
+		sources++
+		field = "butane_local"
+	}
+	if sources > 1 {
+		r.AddOnError(c.Append(field), common.ErrTooManyConfigSources)
+		return
+	}
+	// the embedded Resource isn't validated separately
+	r.Merge(rs.Resource.Validate(c))
+	return
+}
+
 func (fs Filesystem) Validate(c path.ContextPath) (r report.Report) {
+	if len(fs.Subvolumes) > 0 && (fs.Format == nil || *fs.Format != "btrfs") {
+		r.AddOnError(c.Append("subvolumes"), common.ErrSubvolumeFormat)
+	}
+	names := map[string]bool{}
+	for i, sv := range fs.Subvolumes {
+		if names[sv.Name] {
+			r.AddOnError(c.Append("subvolumes", i, "name"), common.ErrSubvolumeDuplicate)
+		}
+		names[sv.Name] = true
+	}
 	if !util.IsTrue(fs.WithMountUnit) {
 		return
 	}
@@ -70,6 +131,328 @@ func (fs Filesystem) Validate(c path.ContextPath) (r report.Report) {
 	return
 }
 
+func (sv Subvolume) Validate(c path.ContextPath) (r report.Report) {
+	valid := subvolumeNameRe.MatchString(sv.Name)
+	for _, component := range strings.Split(sv.Name, "/") {
+		if component == "." || component == ".." {
+			valid = false
+		}
+	}
+	if !valid {
+		r.AddOnError(c.Append("name"), common.ErrSubvolumeName)
+	}
+	if sv.Path != nil && (!strings.HasPrefix(*sv.Path, "/") || strings.Trim(*sv.Path, "/") == "") {
+		r.AddOnError(c.Append("path"), common.ErrSubvolumePath)
+	}
+	compression, _, mountOptions := sv.properties()
+	if sv.Path == nil && len(mountOptions) > 0 {
+		r.AddOnError(c.Append("path"), common.ErrSubvolumeNoPath)
+	}
+	for i, opt := range sv.MountOptions {
+		name, _, _ := strings.Cut(opt, "=")
+		if name == "subvol" || name == "subvolid" {
+			r.AddOnError(c.Append("mount_options", i), common.ErrSubvolumeOption)
+		} else if _, ok := subvolumeCompression[opt]; !ok && opt != "nodatacow" && btrfsFilesystemOptions[name] {
+			r.AddOnError(c.Append("mount_options", i), common.ErrSubvolumeFsOption)
+		} else if opt == "nodatacow" && compression != "" {
+			r.AddOnError(c.Append("mount_options", i), common.ErrSubvolumeNoCowCompress)
+		}
+	}
+	return
+}
+
+func (s Storage) Validate(c path.ContextPath) (r report.Report) {
+	names := make(map[string]bool, len(s.DataDevices))
+	for i, dd := range s.DataDevices {
+		if util.NilOrEmpty(dd.Path) {
+			continue
+		}
+		if names[dd.name()] {
+			r.AddOnError(c.Append("data_devices", i, "path"), common.ErrDataDeviceDuplicate)
+		}
+		names[dd.name()] = true
+	}
+	r.Merge(s.validateSubvolumes(c))
+	r.Merge(s.validateLvm(c))
+	r.Merge(s.validateSwap(c))
+	return
+}
+
+// validateSubvolumes checks that subvolumes aren't mounted where another
+// filesystem or subvolume is mounted.
+func (s Storage) validateSubvolumes(c path.ContextPath) (r report.Report) {
+	mountPaths := map[string]bool{}
+	for _, fs := range s.Filesystems {
+		if fs.Path != nil {
+			mountPaths[*fs.Path] = true
+		}
+	}
+	for _, dd := range s.DataDevices {
+		if dd.Path != nil {
+			mountPaths[*dd.Path] = true
+		}
+	}
+	for i, fs := range s.Filesystems {
+		for j, sv := range fs.Subvolumes {
+			if sv.Path == nil {
+				continue
+			}
+			if mountPaths[*sv.Path] {
+				r.AddOnError(c.Append("filesystems", i, "subvolumes", j, "path"), common.ErrMountPathReused)
+			}
+			mountPaths[*sv.Path] = true
+		}
+	}
+	return
+}
+
+// validateLvm checks that volume groups don't reuse devices or names
+// claimed by other storage entries.
+func (s Storage) validateLvm(c path.ContextPath) (r report.Report) {
+	if len(s.Lvm) == 0 {
+		return
+	}
+	devices := map[string]bool{}
+	dmNames := map[string]bool{}
+	mountPaths := map[string]bool{}
+	for _, fs := range s.Filesystems {
+		devices[fs.Device] = true
+		if fs.Path != nil {
+			mountPaths[*fs.Path] = true
+		}
+		for _, sv := range fs.Subvolumes {
+			if sv.Path != nil {
+				mountPaths[*sv.Path] = true
+			}
+		}
+	}
+	for _, luks := range s.Luks {
+		if luks.Device != nil {
+			devices[*luks.Device] = true
+		}
+		dmNames[luks.Name] = true
+	}
+	for _, raid := range s.Raid {
+		for _, dev := range raid.Devices {
+			devices[string(dev)] = true
+		}
+	}
+	for _, dd := range s.DataDevices {
+		if dd.Device != nil {
+			devices[*dd.Device] = true
+		}
+		for _, dev := range dd.Raid.Devices {
+			devices[string(dev)] = true
+		}
+		if dd.Luks.wanted() {
+			dmNames[dd.name()] = true
+		}
+		if dd.Path != nil {
+			mountPaths[*dd.Path] = true
+		}
+	}
+	vgNames := map[string]bool{}
+	for i, vg := range s.Lvm {
+		vgPath := c.Append("lvm", i)
+		if vgNames[vg.Name] {
+			r.AddOnError(vgPath.Append("name"), common.ErrLvmDuplicateName)
+		}
+		vgNames[vg.Name] = true
+		for j, dev := range vg.Devices {
+			if devices[string(dev)] {
+				r.AddOnError(vgPath.Append("devices", j), common.ErrLvmDeviceReused)
+			}
+			devices[string(dev)] = true
+		}
+		for j, lv := range vg.LogicalVolumes {
+			if dmNames[vg.dmName(lv)] {
+				r.AddOnError(vgPath.Append("logical_volumes", j, "name"), common.ErrLvmDmName)
+			}
+			if lv.Path != nil {
+				if mountPaths[*lv.Path] {
+					r.AddOnError(vgPath.Append("logical_volumes", j, "path"), common.ErrMountPathReused)
+				}
+				mountPaths[*lv.Path] = true
+			}
+		}
+	}
+	return
+}
+
+// validateSwap checks that the swap file isn't on a btrfs filesystem that
+// would copy it on write, which the kernel refuses to swap to.
+func (s Storage) validateSwap(c path.ContextPath) (r report.Report) {
+	if s.Swap.File.Path == nil {
+		return
+	}
+	// find the innermost declared mount containing the swap file
+	var mountPath, format string
+	var options []string
+	consider := func(p string, f *string, opts []string) {
+		if f == nil || !strings.HasPrefix(*s.Swap.File.Path, strings.TrimSuffix(p, "/")+"/") || len(p) <= len(mountPath) {
+			return
+		}
+		mountPath, format, options = p, *f, opts
+	}
+	for _, fs := range s.Filesystems {
+		if fs.Path != nil {
+			consider(*fs.Path, fs.Format, fs.MountOptions)
+		}
+		for _, sv := range fs.Subvolumes {
+			if sv.Path != nil {
+				consider(*sv.Path, fs.Format, sv.MountOptions)
+			}
+		}
+	}
+	for _, dd := range s.DataDevices {
+		if dd.Path != nil {
+			consider(*dd.Path, dd.Format, dd.MountOptions)
+		}
+	}
+	for _, vg := range s.Lvm {
+		for _, lv := range vg.LogicalVolumes {
+			if lv.Path != nil {
+				consider(*lv.Path, lv.Format, lv.MountOptions)
+			}
+		}
+	}
+	if format == "btrfs" && !slices.Contains(options, "nodatacow") {
+		r.AddOnError(c.Append("swap", "file", "path"), common.ErrSwapFileCow)
+	}
+	return
+}
+
+func (dd DataDevice) Validate(c path.ContextPath) (r report.Report) {
+	if util.NilOrEmpty(dd.Path) {
+		r.AddOnError(c.Append("path"), common.ErrDataDeviceNoPath)
+	} else if !strings.HasPrefix(*dd.Path, "/") || dd.name() == "" {
+		r.AddOnError(c.Append("path"), common.ErrDataDeviceBadPath)
+	}
+	switch {
+	case util.NilOrEmpty(dd.Device) && len(dd.Raid.Devices) == 0:
+		r.AddOnError(c, common.ErrDataDeviceNoDevice)
+	case !util.NilOrEmpty(dd.Device) && len(dd.Raid.Devices) > 0:
+		r.AddOnError(c.Append("raid"), common.ErrDataDeviceNoDevice)
+	case len(dd.Raid.Devices) == 1:
+		r.AddOnError(c.Append("raid", "devices"), common.ErrTooFewRaidDevices)
+	}
+	if dd.Format != nil && (*dd.Format == "swap" || *dd.Format == "none") {
+		r.AddOnError(c.Append("format"), common.ErrDataDeviceFormat)
+	}
+	return
+}
+
+func (l DataDeviceLuks) Validate(c path.ContextPath) (r report.Report) {
+	if !l.wanted() && (l.Discard != nil || l.Threshold != nil) {
+		r.AddOnError(c, common.ErrNoLuksMethodSpecified)
+	}
+	return
+}
+
+func (vg VolumeGroup) Validate(c path.ContextPath) (r report.Report) {
+	if !lvmNameRe.MatchString(vg.Name) || vg.Name == "." || vg.Name == ".." {
+		r.AddOnError(c.Append("name"), common.ErrLvmName)
+	}
+	if len(vg.Devices) == 0 {
+		r.AddOnError(c.Append("devices"), common.ErrLvmNoDevices)
+	}
+	for i, dev := range vg.Devices {
+		if !lvmDeviceRe.MatchString(string(dev)) {
+			r.AddOnError(c.Append("devices", i), common.ErrLvmDevice)
+		}
+	}
+	names := map[string]bool{}
+	for i, lv := range vg.LogicalVolumes {
+		if names[lv.Name] {
+			r.AddOnError(c.Append("logical_volumes", i, "name"), common.ErrLvmDuplicateName)
+		}
+		names[lv.Name] = true
+		if i < len(vg.LogicalVolumes)-1 && lv.fills() {
+			r.AddOnError(c.Append("logical_volumes", i), common.ErrLvmFillNotLast)
+		}
+	}
+	return
+}
+
+func (lv LogicalVolume) Validate(c path.ContextPath) (r report.Report) {
+	if !lvmNameRe.MatchString(lv.Name) || lv.Name == "." || lv.Name == ".." {
+		r.AddOnError(c.Append("name"), common.ErrLvmName)
+	}
+	if lv.Size != nil {
+		if _, err := baseutil.ParseSizeMiB(*lv.Size); err != nil {
+			r.AddOnError(c.Append("size"), err)
+		}
+	}
+	if lv.Format != nil && !lvmFormats[*lv.Format] {
+		r.AddOnError(c.Append("format"), common.ErrLvmFormat)
+	}
+	if lv.Path != nil {
+		switch {
+		case !strings.HasPrefix(*lv.Path, "/") || strings.Trim(*lv.Path, "/") == "":
+			r.AddOnError(c.Append("path"), common.ErrLvmPath)
+		case lv.Format == nil:
+			r.AddOnError(c.Append("path"), common.ErrLvmPathNoFormat)
+		case *lv.Format == "swap":
+			r.AddOnError(c.Append("path"), common.ErrLvmSwapPath)
+		}
+	}
+	return
+}
+
+func (f SwapFile) Validate(c path.ContextPath) (r report.Report) {
+	if !f.wanted() {
+		return
+	}
+	if f.Path == nil {
+		r.AddOnError(c.Append("path"), common.ErrSwapFileNoPath)
+	} else if !strings.HasPrefix(*f.Path, "/") || strings.HasSuffix(*f.Path, "/") {
+		r.AddOnError(c.Append("path"), common.ErrSwapFilePath)
+	}
+	if f.Size == nil {
+		r.AddOnError(c.Append("size"), common.ErrSwapNoSize)
+	} else if sizeMiB, err := baseutil.ParseSizeMiB(*f.Size); err != nil {
+		r.AddOnError(c.Append("size"), err)
+	} else if sizeMiB == 0 {
+		r.AddOnError(c.Append("size"), common.ErrSwapFileSize)
+	}
+	return
+}
+
+func (z SwapZram) Validate(c path.ContextPath) (r report.Report) {
+	if !z.wanted() {
+		return
+	}
+	if z.Size == nil {
+		r.AddOnError(c.Append("size"), common.ErrSwapNoSize)
+	} else if _, err := zramSize(*z.Size); err != nil {
+		r.AddOnError(c.Append("size"), err)
+	}
+	if z.Algorithm != nil && !zramAlgorithms[*z.Algorithm] {
+		r.AddOnError(c.Append("algorithm"), common.ErrZramAlgorithm)
+	}
+	return
+}
+
+func (p Partition) Validate(c path.ContextPath) (r report.Report) {
+	if p.Size != nil {
+		if p.SizeMiB != nil {
+			r.AddOnError(c.Append("size"), common.ErrSizeSizeMiB)
+		} else if _, err := baseutil.ParseSizeMiB(*p.Size); err != nil {
+			r.AddOnError(c.Append("size"), err)
+		}
+	}
+	if p.Start != nil {
+		if p.StartMiB != nil {
+			r.AddOnError(c.Append("start"), common.ErrStartStartMiB)
+		} else if strings.HasSuffix(*p.Start, "%") {
+			r.AddOnError(c.Append("start"), common.ErrStartPercent)
+		} else if _, err := baseutil.ParseSizeMiB(*p.Start); err != nil {
+			r.AddOnError(c.Append("start"), err)
+		}
+	}
+	return
+}
+
 func (d Directory) Validate(c path.ContextPath) (r report.Report) {
 	if d.Mode != nil {
 		r.AddOnWarn(c.Append("mode"), baseutil.CheckForDecimalMode(*d.Mode, true))
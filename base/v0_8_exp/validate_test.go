@@ -24,6 +24,7 @@ import (
 	"github.com/coreos/ignition/v2/config/util"
 	"github.com/coreos/vcontext/path"
 	"github.com/coreos/vcontext/report"
+	"github.com/coreos/vcontext/validate"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -139,6 +140,65 @@ func TestValidateResource(t *testing.T) {
 	}
 }
 
+func TestValidateConfigResource(t *testing.T) {
+	tests := []struct {
+		in      ConfigResource
+		out     error
+		errPath path.ContextPath
+	}{
+		{},
+		// nested config
+		{
+			ConfigResource{
+				ButaneLocal: util.StrToPtr("child.bu"),
+			},
+			nil,
+			path.New("yaml"),
+		},
+		// nested config + source, invalid
+		{
+			ConfigResource{
+				Resource: Resource{
+					Source: util.StrToPtr("data:,hello"),
+				},
+				ButaneInline: util.StrToPtr("variant: fcos"),
+			},
+			common.ErrTooManyConfigSources,
+			path.New("yaml", "butane_inline"),
+		},
+		// both nested config sources, invalid
+		{
+			ConfigResource{
+				ButaneInline: util.StrToPtr("variant: fcos"),
+				ButaneLocal:  util.StrToPtr("child.bu"),
+			},
+			common.ErrTooManyConfigSources,
+			path.New("yaml", "butane_local"),
+		},
+		// embedded resource is validated
+		{
+			ConfigResource{
+				Resource: Resource{
+					Source: util.StrToPtr("data:,hello"),
+					Inline: util.StrToPtr("hello"),
+				},
+			},
+			common.ErrTooManyResourceSources,
+			path.New("yaml", "source"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := test.in.Validate(path.New("yaml"))
+			baseutil.VerifyReport(t, test.in, actual)
+			expected := report.Report{}
+			expected.AddOnError(test.errPath, test.out)
+			assert.Equal(t, expected, actual, "bad report")
+		})
+	}
+}
+
 func TestValidateTree(t *testing.T) {
 	tests := []struct {
 		in  Tree
@@ -283,6 +343,112 @@ func TestValidateFilesystem(t *testing.T) {
 			common.ErrMountUnitNoPath,
 			path.New("yaml", "path"),
 		},
+		{
+			Filesystem{
+				Device: "/dev/foo",
+				Format: util.StrToPtr("btrfs"),
+				Subvolumes: []Subvolume{
+					{Name: "a"},
+					{Name: "b"},
+				},
+			},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			Filesystem{
+				Device: "/dev/foo",
+				Format: util.StrToPtr("xfs"),
+				Subvolumes: []Subvolume{
+					{Name: "a"},
+				},
+			},
+			common.ErrSubvolumeFormat,
+			path.New("yaml", "subvolumes"),
+		},
+		{
+			Filesystem{
+				Device: "/dev/foo",
+				Format: util.StrToPtr("btrfs"),
+				Subvolumes: []Subvolume{
+					{Name: "a"},
+					{Name: "a"},
+				},
+			},
+			common.ErrSubvolumeDuplicate,
+			path.New("yaml", "subvolumes", 1, "name"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := test.in.Validate(path.New("yaml"))
+			baseutil.VerifyReport(t, test.in, actual)
+			expected := report.Report{}
+			expected.AddOnError(test.errPath, test.out)
+			assert.Equal(t, expected, actual, "bad report")
+		})
+	}
+}
+
+func TestValidatePartition(t *testing.T) {
+	tests := []struct {
+		in      Partition
+		out     error
+		errPath path.ContextPath
+	}{
+		{
+			Partition{
+				Size:  util.StrToPtr("20GiB"),
+				Start: util.StrToPtr("512M"),
+			},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			Partition{
+				Size: util.StrToPtr("100%"),
+			},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			Partition{
+				Size:    util.StrToPtr("20GiB"),
+				SizeMiB: util.IntToPtr(20480),
+			},
+			common.ErrSizeSizeMiB,
+			path.New("yaml", "size"),
+		},
+		{
+			Partition{
+				Start:    util.StrToPtr("1GiB"),
+				StartMiB: util.IntToPtr(1024),
+			},
+			common.ErrStartStartMiB,
+			path.New("yaml", "start"),
+		},
+		{
+			Partition{
+				Size: util.StrToPtr("20"),
+			},
+			common.ErrSizeInvalid,
+			path.New("yaml", "size"),
+		},
+		{
+			Partition{
+				Size: util.StrToPtr("1.1M"),
+			},
+			common.ErrSizeNotMiBAligned,
+			path.New("yaml", "size"),
+		},
+		{
+			Partition{
+				Start: util.StrToPtr("100%"),
+			},
+			common.ErrStartPercent,
+			path.New("yaml", "start"),
+		},
 	}
 
 	for i, test := range tests {
@@ -296,6 +462,768 @@ func TestValidateFilesystem(t *testing.T) {
 	}
 }
 
+func TestValidateDataDevice(t *testing.T) {
+	tests := []struct {
+		in      DataDevice
+		out     error
+		errPath path.ContextPath
+	}{
+		{
+			DataDevice{
+				Device: util.StrToPtr("/dev/vdb"),
+				Path:   util.StrToPtr("/var/lib/data"),
+			},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			DataDevice{
+				Path: util.StrToPtr("/var/lib/data"),
+				Raid: DataDeviceRaid{
+					Devices: []Device{"/dev/vdb", "/dev/vdc"},
+				},
+			},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			DataDevice{
+				Device: util.StrToPtr("/dev/vdb"),
+			},
+			common.ErrDataDeviceNoPath,
+			path.New("yaml", "path"),
+		},
+		{
+			DataDevice{
+				Device: util.StrToPtr("/dev/vdb"),
+				Path:   util.StrToPtr("/"),
+			},
+			common.ErrDataDeviceBadPath,
+			path.New("yaml", "path"),
+		},
+		{
+			DataDevice{
+				Device: util.StrToPtr("/dev/vdb"),
+				Path:   util.StrToPtr("var/lib/data"),
+			},
+			common.ErrDataDeviceBadPath,
+			path.New("yaml", "path"),
+		},
+		{
+			DataDevice{
+				Path: util.StrToPtr("/var/lib/data"),
+			},
+			common.ErrDataDeviceNoDevice,
+			path.New("yaml"),
+		},
+		{
+			DataDevice{
+				Device: util.StrToPtr("/dev/vdb"),
+				Path:   util.StrToPtr("/var/lib/data"),
+				Raid: DataDeviceRaid{
+					Devices: []Device{"/dev/vdc", "/dev/vdd"},
+				},
+			},
+			common.ErrDataDeviceNoDevice,
+			path.New("yaml", "raid"),
+		},
+		{
+			DataDevice{
+				Path: util.StrToPtr("/var/lib/data"),
+				Raid: DataDeviceRaid{
+					Devices: []Device{"/dev/vdb"},
+				},
+			},
+			common.ErrTooFewRaidDevices,
+			path.New("yaml", "raid", "devices"),
+		},
+		{
+			DataDevice{
+				Device: util.StrToPtr("/dev/vdb"),
+				Format: util.StrToPtr("swap"),
+				Path:   util.StrToPtr("/var/lib/data"),
+			},
+			common.ErrDataDeviceFormat,
+			path.New("yaml", "format"),
+		},
+		{
+			DataDevice{
+				Device: util.StrToPtr("/dev/vdb"),
+				Luks: DataDeviceLuks{
+					Discard: util.BoolToPtr(true),
+				},
+				Path: util.StrToPtr("/var/lib/data"),
+			},
+			common.ErrNoLuksMethodSpecified,
+			path.New("yaml", "luks"),
+		},
+		{
+			DataDevice{
+				Device: util.StrToPtr("/dev/vdb"),
+				Luks: DataDeviceLuks{
+					Discard: util.BoolToPtr(true),
+					KeyFile: Resource{
+						Inline: util.StrToPtr("secret"),
+					},
+				},
+				Path: util.StrToPtr("/var/lib/data"),
+			},
+			nil,
+			path.New("yaml"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := validate.Validate(test.in, "yaml")
+			baseutil.VerifyReport(t, test.in, actual)
+			expected := report.Report{}
+			expected.AddOnError(test.errPath, test.out)
+			assert.Equal(t, expected, actual, "bad report")
+		})
+	}
+}
+
+func TestValidateDataDeviceDuplicate(t *testing.T) {
+	in := Storage{
+		DataDevices: []DataDevice{
+			{
+				Device: util.StrToPtr("/dev/vdb"),
+				Path:   util.StrToPtr("/var/a/b"),
+			},
+			{
+				Device: util.StrToPtr("/dev/vdc"),
+				Path:   util.StrToPtr("/var/a-b"),
+			},
+		},
+	}
+	actual := in.Validate(path.New("yaml"))
+	expected := report.Report{}
+	expected.AddOnError(path.New("yaml", "data_devices", 1, "path"), common.ErrDataDeviceDuplicate)
+	assert.Equal(t, expected, actual, "bad report")
+}
+
+func TestValidateVolumeGroup(t *testing.T) {
+	tests := []struct {
+		in      VolumeGroup
+		out     error
+		errPath path.ContextPath
+	}{
+		{
+			VolumeGroup{
+				Name:    "vg0",
+				Devices: []Device{"/dev/disk/by-partlabel/pv1", "/dev/vdc"},
+				LogicalVolumes: []LogicalVolume{
+					{
+						Name:   "swap",
+						Size:   util.StrToPtr("4GiB"),
+						Format: util.StrToPtr("swap"),
+					},
+					{
+						Name:   "data",
+						Format: util.StrToPtr("xfs"),
+						Path:   util.StrToPtr("/var/lib/data"),
+					},
+				},
+			},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			VolumeGroup{
+				Name:    "-vg",
+				Devices: []Device{"/dev/vdb"},
+			},
+			common.ErrLvmName,
+			path.New("yaml", "name"),
+		},
+		{
+			VolumeGroup{
+				Name: "vg0",
+			},
+			common.ErrLvmNoDevices,
+			path.New("yaml", "devices"),
+		},
+		{
+			VolumeGroup{
+				Name:    "vg0",
+				Devices: []Device{"/dev/disk/by-partlabel/my pv"},
+			},
+			common.ErrLvmDevice,
+			path.New("yaml", "devices", 0),
+		},
+		{
+			VolumeGroup{
+				Name:    "vg0",
+				Devices: []Device{"/dev/vdb"},
+				LogicalVolumes: []LogicalVolume{
+					{Name: "data", Size: util.StrToPtr("1GiB")},
+					{Name: "data", Size: util.StrToPtr("1GiB")},
+				},
+			},
+			common.ErrLvmDuplicateName,
+			path.New("yaml", "logical_volumes", 1, "name"),
+		},
+		{
+			VolumeGroup{
+				Name:    "vg0",
+				Devices: []Device{"/dev/vdb"},
+				LogicalVolumes: []LogicalVolume{
+					{Name: "data", Size: util.StrToPtr("100%")},
+					{Name: "logs", Size: util.StrToPtr("1GiB")},
+				},
+			},
+			common.ErrLvmFillNotLast,
+			path.New("yaml", "logical_volumes", 0),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := test.in.Validate(path.New("yaml"))
+			expected := report.Report{}
+			expected.AddOnError(test.errPath, test.out)
+			assert.Equal(t, expected, actual, "bad report")
+		})
+	}
+}
+
+func TestValidateLogicalVolume(t *testing.T) {
+	tests := []struct {
+		in      LogicalVolume
+		out     error
+		errPath path.ContextPath
+	}{
+		{
+			LogicalVolume{
+				Name:         "data",
+				Size:         util.StrToPtr("20GiB"),
+				Format:       util.StrToPtr("ext4"),
+				Path:         util.StrToPtr("/var/lib/data"),
+				MountOptions: []string{"noatime"},
+			},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			LogicalVolume{
+				Name: "data/x",
+			},
+			common.ErrLvmName,
+			path.New("yaml", "name"),
+		},
+		{
+			LogicalVolume{
+				Name: "data",
+				Size: util.StrToPtr("20GB"),
+			},
+			common.ErrSizeInvalid,
+			path.New("yaml", "size"),
+		},
+		{
+			LogicalVolume{
+				Name:   "data",
+				Format: util.StrToPtr("ntfs"),
+			},
+			common.ErrLvmFormat,
+			path.New("yaml", "format"),
+		},
+		{
+			LogicalVolume{
+				Name:   "data",
+				Format: util.StrToPtr("xfs"),
+				Path:   util.StrToPtr("/"),
+			},
+			common.ErrLvmPath,
+			path.New("yaml", "path"),
+		},
+		{
+			LogicalVolume{
+				Name: "data",
+				Path: util.StrToPtr("/var/lib/data"),
+			},
+			common.ErrLvmPathNoFormat,
+			path.New("yaml", "path"),
+		},
+		{
+			LogicalVolume{
+				Name:   "swap",
+				Format: util.StrToPtr("swap"),
+				Path:   util.StrToPtr("/var/swap"),
+			},
+			common.ErrLvmSwapPath,
+			path.New("yaml", "path"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := validate.Validate(test.in, "yaml")
+			baseutil.VerifyReport(t, test.in, actual)
+			expected := report.Report{}
+			expected.AddOnError(test.errPath, test.out)
+			assert.Equal(t, expected, actual, "bad report")
+		})
+	}
+}
+
+func TestValidateLvmConflicts(t *testing.T) {
+	in := Storage{
+		Filesystems: []Filesystem{
+			{
+				Device: "/dev/disk/by-partlabel/pv1",
+				Path:   util.StrToPtr("/var/lib/data"),
+			},
+		},
+		Luks: []Luks{
+			{
+				Name:   "vg1-data",
+				Device: util.StrToPtr("/dev/vdd"),
+			},
+		},
+		Lvm: []VolumeGroup{
+			{
+				Name:    "vg0",
+				Devices: []Device{"/dev/disk/by-partlabel/pv1"},
+				LogicalVolumes: []LogicalVolume{
+					{
+						Name:   "data",
+						Format: util.StrToPtr("xfs"),
+						Path:   util.StrToPtr("/var/lib/data"),
+					},
+				},
+			},
+			{
+				Name:    "vg0",
+				Devices: []Device{"/dev/vdc", "/dev/vdc"},
+			},
+			{
+				Name:    "vg1",
+				Devices: []Device{"/dev/vde"},
+				LogicalVolumes: []LogicalVolume{
+					{Name: "data"},
+				},
+			},
+		},
+	}
+	actual := in.Validate(path.New("yaml"))
+	expected := report.Report{}
+	expected.AddOnError(path.New("yaml", "lvm", 0, "devices", 0), common.ErrLvmDeviceReused)
+	expected.AddOnError(path.New("yaml", "lvm", 0, "logical_volumes", 0, "path"), common.ErrMountPathReused)
+	expected.AddOnError(path.New("yaml", "lvm", 1, "name"), common.ErrLvmDuplicateName)
+	expected.AddOnError(path.New("yaml", "lvm", 1, "devices", 1), common.ErrLvmDeviceReused)
+	expected.AddOnError(path.New("yaml", "lvm", 2, "logical_volumes", 0, "name"), common.ErrLvmDmName)
+	assert.Equal(t, expected, actual, "bad report")
+}
+
+func TestValidateSubvolume(t *testing.T) {
+	tests := []struct {
+		in      Subvolume
+		out     error
+		errPath path.ContextPath
+	}{
+		{
+			Subvolume{
+				Name:         "@/containers",
+				Path:         util.StrToPtr("/var/lib/containers"),
+				MountOptions: []string{"compress=zstd"},
+			},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			Subvolume{
+				Name: ".snapshots",
+			},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			Subvolume{},
+			common.ErrSubvolumeName,
+			path.New("yaml", "name"),
+		},
+		{
+			Subvolume{
+				Name: "a/../b",
+			},
+			common.ErrSubvolumeName,
+			path.New("yaml", "name"),
+		},
+		{
+			Subvolume{
+				Name: "/a",
+			},
+			common.ErrSubvolumeName,
+			path.New("yaml", "name"),
+		},
+		{
+			Subvolume{
+				Name: "a b",
+			},
+			common.ErrSubvolumeName,
+			path.New("yaml", "name"),
+		},
+		{
+			Subvolume{
+				Name: "a",
+				Path: util.StrToPtr("var/a"),
+			},
+			common.ErrSubvolumePath,
+			path.New("yaml", "path"),
+		},
+		{
+			Subvolume{
+				Name: "a",
+				Path: util.StrToPtr("/"),
+			},
+			common.ErrSubvolumePath,
+			path.New("yaml", "path"),
+		},
+		{
+			Subvolume{
+				Name:         "a",
+				MountOptions: []string{"compress=zstd"},
+			},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			Subvolume{
+				Name:         "a",
+				MountOptions: []string{"noatime"},
+			},
+			common.ErrSubvolumeNoPath,
+			path.New("yaml", "path"),
+		},
+		{
+			Subvolume{
+				Name:         "a",
+				Path:         util.StrToPtr("/var/a"),
+				MountOptions: []string{"noatime", "compress=zstd:3"},
+			},
+			common.ErrSubvolumeFsOption,
+			path.New("yaml", "mount_options", 1),
+		},
+		{
+			Subvolume{
+				Name:         "a",
+				Path:         util.StrToPtr("/var/a"),
+				MountOptions: []string{"ssd"},
+			},
+			common.ErrSubvolumeFsOption,
+			path.New("yaml", "mount_options", 0),
+		},
+		{
+			Subvolume{
+				Name:         "a",
+				MountOptions: []string{"compress", "nodatacow"},
+			},
+			common.ErrSubvolumeNoCowCompress,
+			path.New("yaml", "mount_options", 1),
+		},
+		{
+			Subvolume{
+				Name:         "a",
+				Path:         util.StrToPtr("/var/a"),
+				MountOptions: []string{"noatime", "subvol=b"},
+			},
+			common.ErrSubvolumeOption,
+			path.New("yaml", "mount_options", 1),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := test.in.Validate(path.New("yaml"))
+			baseutil.VerifyReport(t, test.in, actual)
+			expected := report.Report{}
+			expected.AddOnError(test.errPath, test.out)
+			assert.Equal(t, expected, actual, "bad report")
+		})
+	}
+}
+
+func TestValidateSubvolumeConflicts(t *testing.T) {
+	in := Storage{
+		Filesystems: []Filesystem{
+			{
+				Device: "/dev/vdb",
+				Format: util.StrToPtr("btrfs"),
+				Path:   util.StrToPtr("/var/lib/data"),
+				Subvolumes: []Subvolume{
+					{
+						Name: "a",
+						Path: util.StrToPtr("/var/lib/data"),
+					},
+					{
+						Name: "b",
+						Path: util.StrToPtr("/var/lib/b"),
+					},
+				},
+			},
+			{
+				Device: "/dev/vdc",
+				Format: util.StrToPtr("btrfs"),
+				Subvolumes: []Subvolume{
+					{
+						Name: "b",
+						Path: util.StrToPtr("/var/lib/b"),
+					},
+				},
+			},
+		},
+		Lvm: []VolumeGroup{
+			{
+				Name:    "vg0",
+				Devices: []Device{"/dev/vdd"},
+				LogicalVolumes: []LogicalVolume{
+					{
+						Name:   "b",
+						Format: util.StrToPtr("xfs"),
+						Path:   util.StrToPtr("/var/lib/b"),
+					},
+				},
+			},
+		},
+	}
+	actual := in.Validate(path.New("yaml"))
+	expected := report.Report{}
+	expected.AddOnError(path.New("yaml", "filesystems", 0, "subvolumes", 0, "path"), common.ErrMountPathReused)
+	expected.AddOnError(path.New("yaml", "filesystems", 1, "subvolumes", 0, "path"), common.ErrMountPathReused)
+	expected.AddOnError(path.New("yaml", "lvm", 0, "logical_volumes", 0, "path"), common.ErrMountPathReused)
+	assert.Equal(t, expected, actual, "bad report")
+}
+
+func TestValidateSwapFile(t *testing.T) {
+	tests := []struct {
+		in      SwapFile
+		out     error
+		errPath path.ContextPath
+	}{
+		{
+			SwapFile{},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			SwapFile{
+				Path: util.StrToPtr("/var/swapfile"),
+				Size: util.StrToPtr("4GiB"),
+			},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			SwapFile{
+				Size: util.StrToPtr("4GiB"),
+			},
+			common.ErrSwapFileNoPath,
+			path.New("yaml", "path"),
+		},
+		{
+			SwapFile{
+				Path: util.StrToPtr("/var/swap/"),
+				Size: util.StrToPtr("4GiB"),
+			},
+			common.ErrSwapFilePath,
+			path.New("yaml", "path"),
+		},
+		{
+			SwapFile{
+				Path: util.StrToPtr("/var/swapfile"),
+			},
+			common.ErrSwapNoSize,
+			path.New("yaml", "size"),
+		},
+		{
+			SwapFile{
+				Path: util.StrToPtr("/var/swapfile"),
+				Size: util.StrToPtr("100%"),
+			},
+			common.ErrSwapFileSize,
+			path.New("yaml", "size"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := test.in.Validate(path.New("yaml"))
+			baseutil.VerifyReport(t, test.in, actual)
+			expected := report.Report{}
+			expected.AddOnError(test.errPath, test.out)
+			assert.Equal(t, expected, actual, "bad report")
+		})
+	}
+}
+
+func TestValidateSwapZram(t *testing.T) {
+	tests := []struct {
+		in      SwapZram
+		out     error
+		errPath path.ContextPath
+	}{
+		{
+			SwapZram{},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			SwapZram{
+				Size:      util.StrToPtr("50%"),
+				Algorithm: util.StrToPtr("zstd"),
+			},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			SwapZram{
+				Size: util.StrToPtr("8GiB"),
+			},
+			nil,
+			path.New("yaml"),
+		},
+		{
+			SwapZram{
+				Algorithm: util.StrToPtr("zstd"),
+			},
+			common.ErrSwapNoSize,
+			path.New("yaml", "size"),
+		},
+		{
+			SwapZram{
+				Size: util.StrToPtr("-50%"),
+			},
+			common.ErrZramSize,
+			path.New("yaml", "size"),
+		},
+		{
+			SwapZram{
+				Size: util.StrToPtr("lots"),
+			},
+			common.ErrZramSize,
+			path.New("yaml", "size"),
+		},
+		{
+			SwapZram{
+				Size:      util.StrToPtr("50%"),
+				Algorithm: util.StrToPtr("gzip"),
+			},
+			common.ErrZramAlgorithm,
+			path.New("yaml", "algorithm"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := test.in.Validate(path.New("yaml"))
+			baseutil.VerifyReport(t, test.in, actual)
+			expected := report.Report{}
+			expected.AddOnError(test.errPath, test.out)
+			assert.Equal(t, expected, actual, "bad report")
+		})
+	}
+}
+
+func TestValidateSwapFileCow(t *testing.T) {
+	tests := []struct {
+		in  Storage
+		out error
+	}{
+		// btrfs subvolume without nodatacow
+		{
+			Storage{
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/vdb",
+						Format: util.StrToPtr("btrfs"),
+						Path:   util.StrToPtr("/var"),
+						Subvolumes: []Subvolume{
+							{
+								Name: "swap",
+								Path: util.StrToPtr("/var/swap"),
+							},
+						},
+					},
+				},
+				Swap: Swap{
+					File: SwapFile{
+						Path: util.StrToPtr("/var/swap/file"),
+						Size: util.StrToPtr("1GiB"),
+					},
+				},
+			},
+			common.ErrSwapFileCow,
+		},
+		// btrfs subvolume with nodatacow
+		{
+			Storage{
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/vdb",
+						Format: util.StrToPtr("btrfs"),
+						Subvolumes: []Subvolume{
+							{
+								Name:         "swap",
+								Path:         util.StrToPtr("/var/swap"),
+								MountOptions: []string{"nodatacow"},
+							},
+						},
+					},
+				},
+				Swap: Swap{
+					File: SwapFile{
+						Path: util.StrToPtr("/var/swap/file"),
+						Size: util.StrToPtr("1GiB"),
+					},
+				},
+			},
+			nil,
+		},
+		// xfs logical volume inside a btrfs filesystem
+		{
+			Storage{
+				Filesystems: []Filesystem{
+					{
+						Device: "/dev/vdb",
+						Format: util.StrToPtr("btrfs"),
+						Path:   util.StrToPtr("/var/lib"),
+					},
+				},
+				Lvm: []VolumeGroup{
+					{
+						Name:    "vg0",
+						Devices: []Device{"/dev/vdc"},
+						LogicalVolumes: []LogicalVolume{
+							{
+								Name:   "swap",
+								Format: util.StrToPtr("xfs"),
+								Path:   util.StrToPtr("/var/lib/swap"),
+							},
+						},
+					},
+				},
+				Swap: Swap{
+					File: SwapFile{
+						Path: util.StrToPtr("/var/lib/swap/file"),
+						Size: util.StrToPtr("1GiB"),
+					},
+				},
+			},
+			nil,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := test.in.Validate(path.New("yaml"))
+			expected := report.Report{}
+			expected.AddOnError(path.New("yaml", "swap", "file", "path"), test.out)
+			assert.Equal(t, expected, actual, "bad report")
+		})
+	}
+}
+
 // TestValidateUnit tests that multiple sources (i.e. contents and contents_local) are not allowed but zero or one sources are
 func TestValidateUnit(t *testing.T) {
 	tests := []struct {
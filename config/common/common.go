@@ -14,14 +14,55 @@
 
 package common
 
+import (
+	"github.com/coreos/butane/translate"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	"github.com/coreos/vcontext/tree"
+)
+
 type TranslateOptions struct {
-	FilesDir                  string // allow embedding local files relative to this directory
-	NoResourceAutoCompression bool   // skip automatic compression of inline/local resources
-	DebugPrintTranslations    bool   // report translations to stderr
+	FilesDir                  string                    // allow embedding local files relative to this directory
+	NoResourceAutoCompression bool                      // skip automatic compression of inline/local resources
+	NoCompressUnder           int                       // keep inline/local text resources smaller than this many bytes URL-escaped
+	DebugPrintTranslations    bool                      // report translations to stderr
+	Flatten                   bool                      // merge local referenced Ignition configs into the output
+	Translations              *translate.TranslationSet // if non-nil, receives the translations of the output config, prefixed by document index if there are several
+	LocalFiles                map[string]string         // if non-nil, receives the sha256 of each local file read, by path relative to FilesDir
+	// translates nested Butane configs to Ignition; set by config.TranslateBytes
+	NestedTranslator func(input []byte, options TranslateBytesOptions) ([]byte, report.Report, error)
+}
+
+// NestedEntryKind is the kind of a report entry about a problem in a
+// nested Butane config.  The entry itself points to the field holding the
+// nested config in the parent; Source, Context, and Marker locate the
+// problem in the nested config.
+type NestedEntryKind struct {
+	report.EntryKind
+	// file name of the nested config, or "inline"
+	Source  string
+	Context path.ContextPath
+	Marker  tree.Marker
 }
 
 type TranslateBytesOptions struct {
 	TranslateOptions
-	Pretty bool
-	Raw    bool // encode only the Ignition config, not any wrapper
+	Pretty    bool
+	Raw       bool // encode only the Ignition config, not any wrapper
+	Canonical bool // sort unordered lists and pretty-print, for stable diffs
+	// if non-nil, embed provenance metadata in the output config; the
+	// caller sets ButaneVersion and the rest is filled in
+	Provenance *Provenance
+	// list of directories, separated by os.PathListSeparator, to search
+	// for butane-translator-<variant> plugins implementing variants
+	// that aren't registered
+	PluginPath string
+}
+
+// Provenance describes the inputs of a translation.
+type Provenance struct {
+	ButaneVersion string            `json:"butane_version"`
+	SourceSha256  string            `json:"source_sha256"`
+	Files         map[string]string `json:"files,omitempty"` // sha256 by path relative to the files dir
 }
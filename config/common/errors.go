@@ -41,6 +41,11 @@ var (
 	ErrNoFilesDir             = errors.New("local file paths are relative to a files directory that must be specified with -d/--files-dir")
 	ErrTreeNotDirectory       = errors.New("root of tree must be a directory")
 	ErrTreeNoLocal            = errors.New("local is required")
+	ErrTooManyConfigSources   = errors.New("only one of the following can be set: inline, local, source, butane_inline, butane_local")
+	ErrNoNestedTranslator     = errors.New("nested Butane configs can only be translated through the config package")
+	ErrNestingTooDeep         = errors.New("nested Butane configs are nested too deeply")
+	ErrProvenanceFileExists   = errors.New("config already contains a provenance file")
+	ErrProvenanceUnsupported  = errors.New("provenance metadata isn't supported by this spec version")
 
 	// filesystem nodes
 	ErrDecimalMode = errors.New("unreasonable mode would be reasonable if specified in octal; remember to add a leading zero")
@@ -55,6 +60,50 @@ var (
 	ErrMountUnitNoFormat   = errors.New("format is required if with_mount_unit is true")
 	ErrMountPointForbidden = errors.New("path must be under /etc or /var if with_mount_unit is true")
 
+	// data devices
+	ErrDataDeviceNoPath     = errors.New("path is required")
+	ErrDataDeviceBadPath    = errors.New("path must be an absolute path other than /")
+	ErrDataDeviceDuplicate  = errors.New("path conflicts with another data device after replacing / with -")
+	ErrDataDeviceNoDevice   = errors.New("exactly one of device or raid must be specified")
+	ErrTooFewRaidDevices    = errors.New("raid requires at least two devices")
+	ErrDataDeviceFormat     = errors.New("format cannot be swap or none")
+	ErrDataDeviceMountPoint = errors.New("path must be under /etc or /var")
+
+	// LVM
+	ErrLvmName          = errors.New("name must contain only letters, digits, and the characters +_.- and cannot start with -")
+	ErrLvmDuplicateName = errors.New("name is already used by another volume group, or by another logical volume in the same volume group")
+	ErrLvmNoDevices     = errors.New("at least one device is required")
+	ErrLvmDevice        = errors.New("device must be an absolute path without whitespace, quotes, or the characters %$\\`")
+	ErrLvmDeviceReused  = errors.New("device is also used by a filesystem, LUKS volume, RAID array, data device, or other volume group")
+	ErrLvmDmName        = errors.New("device-mapper name of the logical volume conflicts with a LUKS volume")
+	ErrLvmFillNotLast   = errors.New("only the last logical volume in a volume group can fill the remaining space")
+	ErrLvmFormat        = errors.New("format must be one of: btrfs, ext4, swap, vfat, xfs")
+	ErrLvmPath          = errors.New("path must be an absolute path other than /")
+	ErrLvmPathNoFormat  = errors.New("format is required if path is specified")
+	ErrLvmSwapPath      = errors.New("path cannot be specified if format is swap")
+	ErrLvmMountPoint    = errors.New("path must be under /etc or /var")
+
+	// btrfs subvolumes
+	ErrSubvolumeFormat        = errors.New("subvolumes require format btrfs")
+	ErrSubvolumeName          = errors.New("name must be a relative path whose components contain only letters, digits, and the characters +_.@- and are not . or ..")
+	ErrSubvolumeDuplicate     = errors.New("name is already used by another subvolume of the filesystem")
+	ErrSubvolumePath          = errors.New("path must be an absolute path other than /")
+	ErrSubvolumeNoPath        = errors.New("path is required if mount_options other than compress and nodatacow are specified")
+	ErrSubvolumeOption        = errors.New("subvol and subvolid are set from the subvolume name and cannot be specified in mount_options")
+	ErrSubvolumeFsOption      = errors.New("btrfs applies this mount option to the whole filesystem; only nodatacow and compress without a level can be set for a subvolume")
+	ErrSubvolumeNoCowCompress = errors.New("nodatacow cannot be combined with compress")
+	ErrSubvolumeMountPoint    = errors.New("path must be under /etc or /var")
+
+	// swap
+	ErrSwapNoSize         = errors.New("size is required")
+	ErrSwapFileNoPath     = errors.New("path is required")
+	ErrSwapFilePath       = errors.New("path must be an absolute path to a file")
+	ErrSwapFileSize       = errors.New("size must be a binary size such as 4GiB, not a percentage")
+	ErrSwapFileCow        = errors.New("swap files on btrfs require the nodatacow mount option")
+	ErrSwapFileMountPoint = errors.New("path must be under /etc or /var")
+	ErrZramSize           = errors.New("size must be a percentage of RAM such as 50%, or a binary size such as 4GiB")
+	ErrZramAlgorithm      = errors.New("algorithm must be one of: 842, deflate, lz4, lz4hc, lzo, lzo-rle, zstd")
+
 	// boot device
 	ErrUnknownBootDeviceLayout       = errors.New("layout must be one of: aarch64, ppc64le, s390x-eckd, s390x-virt, s390x-zfcp, x86_64")
 	ErrUnknownBootDeviceLayoutLegacy = errors.New("layout must be one of: aarch64, ppc64le, x86_64")
@@ -66,17 +115,47 @@ var (
 	ErrCexArchitectureMismatch       = errors.New("when using cex the targeted architecture must match s390x")
 	ErrCexNotSupported               = errors.New("cex is not currently supported on the target platform")
 	ErrNoLuksMethodSpecified         = errors.New("no method specified for luks")
+	ErrVarNotSupport                 = errors.New("a separate /var partition is not supported on layout s390x-eckd")
+	ErrVarFormat                     = errors.New("format of the /var filesystem cannot be swap or none")
+	ErrVarSizeRequired               = errors.New("size_mib is required if boot_device.mirror.partitions is specified")
+	ErrMirrorNoDevices               = errors.New("devices are required if level, spares, root_size_mib, or partitions is specified")
+	ErrMirrorRootSize                = errors.New("root_size_mib must be positive")
+	ErrMirrorPartitionLabel          = errors.New("label must contain only letters, digits, and the characters _- and cannot be bios, boot, esp, prep, reserved, root, or var")
+	ErrMirrorPartitionDuplicate      = errors.New("label is already used by another mirrored partition")
+	ErrMirrorPartitionFill           = errors.New("only the last mirrored partition can omit size_mib")
+	ErrMirrorPartitionFormat         = errors.New("format cannot be swap or none")
+	ErrMirrorPartitionPath           = errors.New("path must be under /etc or /var")
+	ErrMirrorLevel                   = errors.New("level must be raid1 or raid10")
+	ErrMirrorSpares                  = errors.New("spares must be non-negative and less than the number of devices")
+	ErrMirrorActiveDevices           = errors.New("too few active devices for the mirror level; raid1 requires two and raid10 requires four")
 
 	// partition
 	ErrReuseByLabel         = errors.New("partitions cannot be reused by label; number must be specified except on boot disk (/dev/disk/by-id/coreos-boot-disk) or when wipe_table is true")
 	ErrWrongPartitionNumber = errors.New("incorrect partition number; a new partition will be created using reserved label")
 	ErrRootTooSmall         = errors.New("root should have 8GiB of space assigned")
 	ErrRootConstrained      = errors.New("root partition cannot expand; it is set to fill available space but is followed by an auto-positioned partition")
+	ErrSizeInvalid          = errors.New("size must be a number followed by K, KiB, M, MiB, G, GiB, T, or TiB")
+	ErrSizeNotMiBAligned    = errors.New("size must be a multiple of 1 MiB")
+	ErrSizePercent          = errors.New("only 100% is supported, to fill the rest of the disk")
+	ErrStartPercent         = errors.New("start cannot be a percentage")
+	ErrSizeSizeMiB          = errors.New("size and size_mib are mutually exclusive")
+	ErrStartStartMiB        = errors.New("start and start_mib are mutually exclusive")
+
+	// storage references
+	ErrUnknownPartLabel = errors.New("no partition with this label is declared in storage.disks or present in the OS image")
+	ErrUnknownFsLabel   = errors.New("no filesystem or LUKS volume with this label is declared in the config or present in the OS image")
+	ErrUnknownLuksName  = errors.New("no LUKS volume with this name is declared in storage.luks")
+	ErrUnknownRaidName  = errors.New("no RAID array with this name is declared in storage.raid")
+	ErrDeviceReused     = errors.New("device is also used by another filesystem, LUKS volume, or RAID array")
+	ErrMountPathReused  = errors.New("another filesystem is mounted at this path")
 
 	// MachineConfigs
 	ErrFieldElided              = errors.New("field ignored in raw mode")
 	ErrNameRequired             = errors.New("metadata.name is required")
 	ErrRoleRequired             = errors.New("machineconfiguration.openshift.io/role label is required")
+	ErrRolesWithRoleLabel       = errors.New("machineconfiguration.openshift.io/role label cannot be combined with metadata.roles")
+	ErrDuplicateRole            = errors.New("role is listed more than once")
+	ErrObjectName               = errors.New("generated object name must consist of lowercase letters, digits, '-', and '.', and be at most 253 characters")
 	ErrInvalidKernelType        = errors.New("must be empty, \"default\", or \"realtime\"")
 	ErrBtrfsSupport             = errors.New("btrfs is not supported in this spec version")
 	ErrFilesystemNoneSupport    = errors.New("format \"none\" is not supported in this spec version")
@@ -90,6 +169,19 @@ var (
 	ErrUserNameSupport          = errors.New("users other than \"core\" are not supported in this spec version")
 	ErrKernelArgumentSupport    = errors.New("this section cannot be used for kernel arguments in this spec version; use openshift.kernel_arguments instead")
 	ErrMissingKernelArgumentCex = errors.New("'rd.luks.key=/etc/luks/cex.key' must be set as kernel argument when CEX is enabled for the boot device")
+	ErrNotPositive              = errors.New("must be greater than zero")
+	ErrInvalidQuantity          = errors.New("must be a Kubernetes resource quantity such as \"500m\", \"1Gi\", or \"100M\"")
+	ErrInvalidKubeletLogLevel   = errors.New("must be between 0 and 10")
+	ErrInvalidCrioLogLevel      = errors.New("must be one of \"trace\", \"debug\", \"info\", \"warn\", \"error\", \"fatal\", or \"panic\"")
+	ErrInvalidDefaultRuntime    = errors.New("must be \"runc\" or \"crun\"")
+	ErrImageNotPinned           = errors.New("image must be pinned by digest, such as \"quay.io/example/image@sha256:<digest>\"")
+	ErrUnknownExtension         = errors.New("extension is not known to be supported in this spec version")
+	ErrDuplicateKernelArgument  = errors.New("kernel argument is listed more than once")
+	ErrKernelArgumentManaged    = errors.New("kernel argument is managed by the Machine Config Operator and will be overridden")
+	ErrKernelArgumentRealtime   = errors.New("kernel argument conflicts with kernel_type \"realtime\"")
+	ErrPathOwned                = errors.New("file is managed by the Machine Config Operator or kubelet; this config will conflict with it")
+	ErrPathReboot               = errors.New("changing this file drains and reboots every node in the pool")
+	ErrPathReload               = errors.New("changing this file reloads CRI-O without draining or rebooting nodes")
 
 	// Storage
 	ErrClevisSupport     = errors.New("clevis is not supported in this spec version")
@@ -97,8 +189,10 @@ var (
 	ErrDiskSupport       = errors.New("disk customization is not supported in this spec version")
 	ErrFilesystemSupport = errors.New("filesystem customization is not supported in this spec version")
 	ErrLinkSupport       = errors.New("links are not supported in this spec version")
+	ErrLvmSupport        = errors.New("LVM is not supported in this spec version")
 	ErrLuksSupport       = errors.New("luks is not supported in this spec version")
 	ErrRaidSupport       = errors.New("raid is not supported in this spec version")
+	ErrSwapSupport       = errors.New("swap is not supported in this spec version; the kubelet refuses to start on nodes with swap enabled")
 
 	// Grub
 	ErrGrubUserNameNotSpecified = errors.New("field \"name\" is required")
@@ -107,8 +201,24 @@ var (
 	// Kernel arguments
 	ErrGeneralKernelArgumentSupport = errors.New("kernel argument customization is not supported in this spec version")
 
+	// flattening
+	ErrFlattenRemote  = errors.New("referenced config is not local; not flattening it or any later merged configs")
+	ErrFlattenInvalid = errors.New("referenced config could not be parsed; not flattening it or any later merged configs")
+	ErrFlattenVersion = errors.New("referenced config has a different Ignition spec version; not flattening it or any later merged configs")
+	ErrFlattenNested  = errors.New("referenced config merges a config that is not local; not flattening it or any later merged configs")
+
 	// Unkown ignition version
 	ErrUnkownIgnitionVersion = errors.New("skipping validation for the merge/replace ignition config due to an unkown version")
+
+	// custom variants
+	ErrVariantNameRequired = errors.New("variant is required")
+	ErrVariantExists       = errors.New("variant and version are already defined")
+	ErrVariantBase         = errors.New("base must be a built-in variant and version")
+	ErrVariantRulePath     = errors.New("rule path is required")
+	ErrVariantConstraint   = errors.New("constraint must specify exactly one of values or pattern")
+
+	// translator plugins
+	ErrPluginProvenance = errors.New("translator plugins don't support provenance metadata")
 )
 
 type ErrUnmarshal struct {
@@ -121,6 +231,46 @@ func (e ErrUnmarshal) Error() string {
 	return fmt.Sprintf("Error unmarshaling yaml: %v", e.Detail)
 }
 
+type ErrNestedConfig struct {
+	// file name of the nested config, or "inline"
+	Name string
+	// description of the problem, including the location in the
+	// nested config
+	Detail string
+}
+
+func (e ErrNestedConfig) Error() string {
+	return fmt.Sprintf("in nested config %s: %s", e.Name, e.Detail)
+}
+
+type ErrFieldOverridden struct {
+	// source path of the merged config
+	By string
+}
+
+func (e ErrFieldOverridden) Error() string {
+	return fmt.Sprintf("field overridden by merged config at %s", e.By)
+}
+
+type ErrVariantRule struct {
+	Variant string
+	// e.g. "field is required"
+	Problem string
+}
+
+func (e ErrVariantRule) Error() string {
+	return fmt.Sprintf("%s by the %s variant", e.Problem, e.Variant)
+}
+
+type ErrPlugin struct {
+	Plugin string
+	Detail string
+}
+
+func (e ErrPlugin) Error() string {
+	return fmt.Sprintf("translator plugin %s: %s", e.Plugin, e.Detail)
+}
+
 type ErrUnknownVersion struct {
 	Variant string
 	Version semver.Version
@@ -16,6 +16,8 @@ package config
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/coreos/butane/config/common"
 	fcos1_0 "github.com/coreos/butane/config/fcos/v1_0"
@@ -57,6 +59,9 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// maximum depth of Butane configs nested in other Butane configs
+const maxNestingDepth = 10
+
 var (
 	registry = map[string]translator{}
 )
@@ -115,6 +120,23 @@ func RegisterTranslator(variant, version string, trans translator) {
 	registry[key] = trans
 }
 
+// Versions returns the spec versions registered for the specified variant,
+// oldest first.
+func Versions(variant string) []semver.Version {
+	var ret []semver.Version
+	for key := range registry {
+		v, ver, _ := strings.Cut(key, "+")
+		if v != variant {
+			continue
+		}
+		ret = append(ret, *semver.New(ver))
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].LessThan(ret[j])
+	})
+	return ret
+}
+
 func getTranslator(variant string, version semver.Version) (translator, error) {
 	t, ok := registry[fmt.Sprintf("%s+%s", variant, version.String())]
 	if !ok {
@@ -154,12 +176,32 @@ func TranslateBytes(input []byte, options common.TranslateBytesOptions) ([]byte,
 
 	translator, err := getTranslator(ver.Variant, version)
 	if err != nil {
-		return nil, report.Report{}, err
+		// fall back to a translator plugin; it checks the version
+		exe, ok := findPlugin(options.PluginPath, ver.Variant)
+		if !ok {
+			return nil, report.Report{}, err
+		}
+		translator = pluginTranslator(exe)
 	}
 
+	if options.NestedTranslator == nil {
+		options.NestedTranslator = nestedTranslator(1)
+	}
 	return translator(input, options)
 }
 
+// nestedTranslator returns a translator for Butane configs nested depth
+// levels deep in ignition.config.merge or ignition.config.replace.
+func nestedTranslator(depth int) translator {
+	return func(input []byte, options common.TranslateBytesOptions) ([]byte, report.Report, error) {
+		if depth > maxNestingDepth {
+			return nil, report.Report{}, common.ErrNestingTooDeep
+		}
+		options.NestedTranslator = nestedTranslator(depth + 1)
+		return TranslateBytes(input, options)
+	}
+}
+
 func unsupportedRhcosVariant(input []byte, options common.TranslateBytesOptions) ([]byte, report.Report, error) {
 	return nil, report.Report{}, common.ErrRhcosVariantUnsupported
 }
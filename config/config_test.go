@@ -0,0 +1,64 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package config
+
+import (
+	"testing"
+
+	"github.com/coreos/butane/config/common"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNestedConfigReport tests that problems in nested configs are
+// located in both the parent and the nested config.
+func TestNestedConfigReport(t *testing.T) {
+	in := `variant: fcos
+version: 1.8.0-experimental
+ignition:
+  config:
+    merge:
+      - butane_inline: |
+          variant: fcos
+          version: 1.8.0-experimental
+          storage:
+            files:
+              - path: /etc/motd
+                contents:
+                  inline: hello
+                  source: https://example.com/motd
+`
+	_, r, err := TranslateBytes([]byte(in), common.TranslateBytesOptions{})
+	assert.Equal(t, common.ErrInvalidSourceConfig, err, "bad error")
+	if !assert.Len(t, r.Entries, 1, "bad report") {
+		return
+	}
+	entry := r.Entries[0]
+	kind, ok := entry.Kind.(common.NestedEntryKind)
+	if !assert.True(t, ok, "bad kind %T", entry.Kind) {
+		return
+	}
+	assert.Equal(t, report.Error, kind.EntryKind, "bad kind")
+	assert.Equal(t, path.New("yaml", "ignition", "config", "merge", 0, "butane_inline"), entry.Context, "bad parent path")
+	assert.Equal(t, int64(6), entry.Marker.StartP.Line, "bad parent line")
+	assert.Equal(t, "inline", kind.Source, "bad source")
+	assert.Equal(t, path.New("yaml", "storage", "files", 0, "contents", "source"), kind.Context, "bad nested path")
+	if assert.NotNil(t, kind.Marker.StartP, "missing nested marker") {
+		assert.Equal(t, int64(8), kind.Marker.StartP.Line, "bad nested line")
+		assert.Equal(t, int64(17), kind.Marker.StartP.Column, "bad nested column")
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/coreos/butane/config/common"
+	cutil "github.com/coreos/butane/config/util"
+	"github.com/coreos/butane/translate"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	vyaml "github.com/coreos/vcontext/yaml"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// custom variants registered by RegisterVariant
+	customVariants = map[string]bool{}
+)
+
+// VariantDefinition declares a custom variant version as a built-in
+// variant version plus restrictions.  Rule paths are dot-separated field
+// names in the generated config, as in FieldFilters; lists are traversed
+// implicitly.
+type VariantDefinition struct {
+	Variant     string            `yaml:"variant"`
+	Version     string            `yaml:"version"`
+	Description string            `yaml:"description"`
+	Base        VariantBase       `yaml:"base"`
+	Forbidden   []VariantRule     `yaml:"forbidden"`
+	Required    []VariantRule     `yaml:"required"`
+	Constraints []ValueConstraint `yaml:"constraints"`
+}
+
+type VariantBase struct {
+	Variant string `yaml:"variant"`
+	Version string `yaml:"version"`
+}
+
+type VariantRule struct {
+	Path    string `yaml:"path"`
+	Message string `yaml:"message"`
+}
+
+// ValueConstraint restricts the values of a field to a list of allowed
+// values or to those matching a regular expression.
+type ValueConstraint struct {
+	VariantRule `yaml:",inline"`
+	Values      []string `yaml:"values"`
+	Pattern     *string  `yaml:"pattern"`
+}
+
+// Predicate returns the values allowed by the constraint.  The constraint
+// must be valid.
+func (c ValueConstraint) Predicate() cutil.ValuePredicate {
+	if c.Pattern != nil {
+		return cutil.MatchesPattern(*c.Pattern)
+	}
+	return cutil.OneOf(c.Values...)
+}
+
+// LoadVariantDir parses the variant definitions in the *.yaml files in
+// dir, in lexical order.
+func LoadVariantDir(dir string) ([]VariantDefinition, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var defs []VariantDefinition
+	for _, p := range paths {
+		def, err := loadVariant(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+func loadVariant(p string) (VariantDefinition, error) {
+	var def VariantDefinition
+	contents, err := os.ReadFile(p)
+	if err != nil {
+		return def, err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&def); err != nil {
+		return def, err
+	}
+	return def, def.validate()
+}
+
+func (def VariantDefinition) validate() error {
+	if def.Variant == "" {
+		return common.ErrVariantNameRequired
+	}
+	if _, err := semver.NewVersion(def.Version); err != nil {
+		return common.ErrInvalidVersion
+	}
+	for _, rules := range [][]VariantRule{def.Forbidden, def.Required} {
+		for _, rule := range rules {
+			if rule.Path == "" {
+				return common.ErrVariantRulePath
+			}
+		}
+	}
+	for _, constraint := range def.Constraints {
+		if constraint.Path == "" {
+			return common.ErrVariantRulePath
+		}
+		if (constraint.Values == nil) == (constraint.Pattern == nil) {
+			return fmt.Errorf("%s: %w", constraint.Path, common.ErrVariantConstraint)
+		}
+		if constraint.Pattern != nil {
+			if _, err := regexp.Compile(*constraint.Pattern); err != nil {
+				return fmt.Errorf("%s: %w", constraint.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// RegisterVariant registers a translator for the custom variant version
+// declared by def, to be available for use by TranslateBytes.  The base
+// variant version must be built in.
+func RegisterVariant(def VariantDefinition) error {
+	if err := def.validate(); err != nil {
+		return err
+	}
+	version := *semver.New(def.Version)
+	if _, err := getTranslator(def.Variant, version); err == nil {
+		return fmt.Errorf("%s %s: %w", def.Variant, def.Version, common.ErrVariantExists)
+	}
+	baseVersion, err := semver.NewVersion(def.Base.Version)
+	if err != nil || customVariants[def.Base.Variant] {
+		return common.ErrVariantBase
+	}
+	base, err := getTranslator(def.Base.Variant, *baseVersion)
+	if err != nil {
+		return common.ErrVariantBase
+	}
+	RegisterTranslator(def.Variant, def.Version, def.translator(base, def.fieldFilters()))
+	customVariants[def.Variant] = true
+	return nil
+}
+
+// fieldFilters returns the forbidden fields and value constraints of def
+// as FieldFilters over the generated config decoded as JSON.
+func (def VariantDefinition) fieldFilters() cutil.FieldFilters {
+	filters := cutil.FilterMap{}
+	for _, rule := range def.Forbidden {
+		filters[rule.Path] = def.ruleError(rule, "field is forbidden")
+	}
+	values := cutil.ValueFilterMap{}
+	for _, constraint := range def.Constraints {
+		values[constraint.Path] = append(values[constraint.Path], cutil.ValueRule{
+			Allowed: constraint.Predicate(),
+			Err:     def.ruleError(constraint.VariantRule, "value is not allowed"),
+		})
+	}
+	return cutil.NewValueFilters(map[string]any{}, filters, values)
+}
+
+// translator returns a translator that translates with base and then
+// checks the generated config against the rules in def.
+func (def VariantDefinition) translator(base translator, filters cutil.FieldFilters) translator {
+	return func(input []byte, options common.TranslateBytesOptions) ([]byte, report.Report, error) {
+		// the rules need the translations even if the caller doesn't
+		callerTranslations := options.Translations
+		var ts translate.TranslationSet
+		options.Translations = &ts
+		output, r, err := base(input, options)
+		if callerTranslations != nil {
+			*callerTranslations = ts
+		}
+		if err != nil {
+			return output, r, err
+		}
+
+		docs, err := cutil.SplitDocuments(output, ts)
+		if err != nil {
+			return nil, r, err
+		}
+		var ruleReport report.Report
+		for _, doc := range docs {
+			// companion objects such as KubeletConfigs lack the
+			// fields of the config itself
+			required := doc.Value["kind"] == docs[0].Value["kind"]
+			ruleReport.Merge(cutil.TranslateReportPaths(def.check(filters, doc.Value, required), doc.Translations))
+		}
+		if contextTree, err := vyaml.UnmarshalToContext(input); err == nil {
+			ruleReport.Correlate(contextTree)
+		}
+		r.Merge(ruleReport)
+		if r.IsFatal() {
+			return nil, r, common.ErrInvalidSourceConfig
+		}
+		return output, r, nil
+	}
+}
+
+// check reports the violations of the rules in def by the generated
+// object v.  Required rules are skipped unless required is set.
+func (def VariantDefinition) check(filters cutil.FieldFilters, v map[string]any, required bool) report.Report {
+	r := filters.Verify(v)
+	if required {
+		for _, rule := range def.Required {
+			walkRulePath(v, rule.Path, func(value any, p path.ContextPath, found bool) {
+				if !found || isEmptyValue(value) {
+					r.AddOnError(p, def.ruleError(rule, "field is required"))
+				}
+			})
+		}
+	}
+	return r
+}
+
+func (def VariantDefinition) ruleError(rule VariantRule, problem string) error {
+	if rule.Message != "" {
+		return errors.New(rule.Message)
+	}
+	return common.ErrVariantRule{
+		Variant: def.Variant,
+		Problem: problem,
+	}
+}
+
+// walkRulePath calls fn with each value at the dot-separated path rule in
+// v, descending into lists.  If a field is missing, fn is called with the
+// path of the object that lacks it and found set to false.
+func walkRulePath(v any, rule string, fn func(value any, p path.ContextPath, found bool)) {
+	var walk func(v any, elems []string, p path.ContextPath)
+	walk = func(v any, elems []string, p path.ContextPath) {
+		if len(elems) == 0 {
+			fn(v, p, true)
+			return
+		}
+		switch obj := v.(type) {
+		case []any:
+			for i, item := range obj {
+				walk(item, elems, p.Append(i))
+			}
+		case map[string]any:
+			child, ok := obj[elems[0]]
+			if !ok || child == nil {
+				fn(nil, p, false)
+				return
+			}
+			walk(child, elems[1:], p.Append(elems[0]))
+		}
+	}
+	walk(v, strings.Split(rule, "."), path.New("json"))
+}
+
+func isEmptyValue(v any) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case []any:
+		return len(value) == 0
+	case map[string]any:
+		return len(value) == 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/butane/config/common"
+	"github.com/coreos/butane/translate"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	"github.com/coreos/vcontext/tree"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomVariant(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "acme.yaml"), []byte(`variant: test-acme
+version: 1.0.0
+base:
+  variant: fcos
+  version: 1.5.0
+forbidden:
+  - path: storage.disks
+    message: disks are partitioned by the platform team
+required:
+  - path: passwd.users.sshAuthorizedKeys
+constraints:
+  - path: passwd.users.name
+    values: [core]
+  - path: storage.files.contents.source
+    pattern: ^(data:|https://mirror\.example\.com/)
+    message: sources must be on the mirror
+`), 0644)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defs, err := LoadVariantDir(dir)
+	if !assert.NoError(t, err) || !assert.Len(t, defs, 1) {
+		return
+	}
+	if !assert.NoError(t, RegisterVariant(defs[0])) {
+		return
+	}
+	assert.True(t, errors.Is(RegisterVariant(defs[0]), common.ErrVariantExists), "reregistered variant")
+	assert.Equal(t, []string{"1.0.0"}, versionStrings(Versions("test-acme")))
+
+	tests := []struct {
+		in      string
+		entries []report.Entry
+	}{
+		// conforming config
+		{
+			in: `variant: test-acme
+version: 1.0.0
+passwd:
+  users:
+    - name: core
+      ssh_authorized_keys: [key]
+storage:
+  files:
+    - path: /etc/motd
+      contents:
+        source: https://mirror.example.com/motd
+`,
+		},
+		// every kind of violation, including in generated fields
+		{
+			in: `variant: test-acme
+version: 1.0.0
+passwd:
+  users:
+    - name: admin
+boot_device:
+  layout: x86_64
+  mirror:
+    devices: [/dev/vda, /dev/vdb]
+storage:
+  files:
+    - path: /etc/motd
+      contents:
+        source: https://example.com/motd
+`,
+			entries: []report.Entry{
+				{
+					Kind:    report.Error,
+					Message: common.ErrVariantRule{Variant: "test-acme", Problem: "value is not allowed"}.Error(),
+					Context: path.New("yaml", "passwd", "users", 0, "name"),
+				},
+				{
+					Kind:    report.Error,
+					Message: "disks are partitioned by the platform team",
+					Context: path.New("yaml", "boot_device", "mirror", "devices"),
+				},
+				{
+					Kind:    report.Error,
+					Message: "sources must be on the mirror",
+					Context: path.New("yaml", "storage", "files", 0, "contents", "source"),
+				},
+				{
+					Kind:    report.Error,
+					Message: common.ErrVariantRule{Variant: "test-acme", Problem: "field is required"}.Error(),
+					Context: path.New("yaml", "passwd", "users", 0),
+				},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		_, r, err := TranslateBytes([]byte(test.in), common.TranslateBytesOptions{})
+		if test.entries == nil {
+			assert.NoError(t, err, "#%d: translation failed", i)
+			assert.Empty(t, r.Entries, "#%d: non-empty report", i)
+			continue
+		}
+		assert.Equal(t, common.ErrInvalidSourceConfig, err, "#%d: bad error", i)
+		for j := range r.Entries {
+			// markers depend on the input layout
+			r.Entries[j].Marker = test.entries[j].Marker
+		}
+		assert.Equal(t, test.entries, r.Entries, "#%d: bad report", i)
+	}
+
+	// translations reach the caller
+	var ts translate.TranslationSet
+	_, _, err = TranslateBytes([]byte(tests[0].in), common.TranslateBytesOptions{
+		TranslateOptions: common.TranslateOptions{
+			Translations: &ts,
+		},
+	})
+	assert.NoError(t, err, "translation failed")
+	assert.Equal(t, path.New("yaml", "storage", "files", 0, "contents", "source"), ts.Set[path.New("json", "storage", "files", 0, "contents", "source").String()].From, "bad translation")
+}
+
+func TestCustomVariantManifests(t *testing.T) {
+	def := VariantDefinition{
+		Variant: "test-acme-ocp",
+		Version: "1.0.0",
+		Base: VariantBase{
+			Variant: "openshift",
+			Version: "4.23.0-experimental",
+		},
+		Forbidden: []VariantRule{
+			{Path: "spec.kubeletConfig.maxPods"},
+		},
+		Required: []VariantRule{
+			{Path: "spec.kernelArguments"},
+		},
+	}
+	if !assert.NoError(t, RegisterVariant(def)) {
+		return
+	}
+
+	// companion objects are checked, but needn't have required fields
+	_, r, err := TranslateBytes([]byte(`variant: test-acme-ocp
+version: 1.0.0
+metadata:
+  name: 99-worker-x
+  labels:
+    machineconfiguration.openshift.io/role: worker
+openshift:
+  kernel_arguments: [a=1]
+  kubelet:
+    max_pods: 100
+`), common.TranslateBytesOptions{})
+	assert.Equal(t, common.ErrInvalidSourceConfig, err, "bad error")
+	for i := range r.Entries {
+		r.Entries[i].Marker = tree.Marker{}
+	}
+	assert.Equal(t, []report.Entry{
+		{
+			Kind:    report.Error,
+			Message: common.ErrVariantRule{Variant: "test-acme-ocp", Problem: "field is forbidden"}.Error(),
+			Context: path.New("yaml", "openshift", "kubelet", "max_pods"),
+		},
+	}, r.Entries, "bad report")
+}
+
+func TestLoadVariantDirErrors(t *testing.T) {
+	tests := []struct {
+		in  string
+		err error
+	}{
+		{"version: 1.0.0\n", common.ErrVariantNameRequired},
+		{"variant: x\nversion: one\n", common.ErrInvalidVersion},
+		{"variant: x\nversion: 1.0.0\nforbidden:\n  - message: m\n", common.ErrVariantRulePath},
+		{"variant: x\nversion: 1.0.0\nconstraints:\n  - path: a\n", common.ErrVariantConstraint},
+	}
+	for i, test := range tests {
+		dir := t.TempDir()
+		if !assert.NoError(t, os.WriteFile(filepath.Join(dir, "v.yaml"), []byte(test.in), 0644)) {
+			return
+		}
+		_, err := LoadVariantDir(dir)
+		assert.True(t, errors.Is(err, test.err), "#%d: bad error %v", i, err)
+	}
+
+	def := VariantDefinition{
+		Variant: "test-bad-base",
+		Version: "1.0.0",
+		Base: VariantBase{
+			Variant: "fcos",
+			Version: "0.9.0",
+		},
+	}
+	assert.Equal(t, common.ErrVariantBase, RegisterVariant(def), "bad error for unknown base")
+}
+
+func versionStrings(versions []semver.Version) []string {
+	var ret []string
+	for _, v := range versions {
+		ret = append(ret, v.String())
+	}
+	return ret
+}
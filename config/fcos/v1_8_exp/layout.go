@@ -0,0 +1,57 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package v1_8_exp
+
+// BootDiskPartition is a partition of the OS image on the boot disk.
+type BootDiskPartition struct {
+	Number int
+	Label  string
+	// zero if the size depends on the OS image rather than the layout
+	SizeMiB int
+	// the partition is grown to fill the disk on first boot
+	Grows bool
+}
+
+// BootDiskLayout returns the partitions of the OS image for the specified
+// boot_device layout, in order starting at 1 MiB.  It returns false if
+// the layout is unknown or doesn't use a GPT disk label.
+func BootDiskLayout(layout string) ([]BootDiskPartition, bool) {
+	var ret []BootDiskPartition
+	switch layout {
+	case "", "x86_64":
+		ret = []BootDiskPartition{
+			{Number: 1, Label: "BIOS-BOOT", SizeMiB: biosV1SizeMiB},
+			{Number: 2, Label: "EFI-SYSTEM", SizeMiB: espV1SizeMiB},
+		}
+	case "aarch64":
+		ret = []BootDiskPartition{
+			{Number: 1, Label: "reserved", SizeMiB: reservedV1SizeMiB},
+			{Number: 2, Label: "EFI-SYSTEM", SizeMiB: espV1SizeMiB},
+		}
+	case "ppc64le":
+		ret = []BootDiskPartition{
+			{Number: 1, Label: "PowerPC-PReP-boot", SizeMiB: prepV1SizeMiB},
+			{Number: 2, Label: "reserved", SizeMiB: reservedV1SizeMiB},
+		}
+	default:
+		return nil, false
+	}
+	return append(ret,
+		BootDiskPartition{Number: 3, Label: "boot", SizeMiB: bootV1SizeMiB},
+		// the root partition is sized to fit the OS tree, which varies
+		// between releases
+		BootDiskPartition{Number: 4, Label: "root", Grows: true},
+	), true
+}
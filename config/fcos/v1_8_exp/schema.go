@@ -28,6 +28,7 @@ type BootDevice struct {
 	Layout *string          `yaml:"layout"`
 	Luks   BootDeviceLuks   `yaml:"luks"`
 	Mirror BootDeviceMirror `yaml:"mirror"`
+	Var    BootDeviceVar    `yaml:"var"`
 }
 
 type BootDeviceLuks struct {
@@ -40,7 +41,32 @@ type BootDeviceLuks struct {
 }
 
 type BootDeviceMirror struct {
-	Devices []string `yaml:"devices"`
+	Devices     []string                    `yaml:"devices"`
+	Level       *string                     `yaml:"level"`
+	Partitions  []BootDeviceMirrorPartition `yaml:"partitions"`
+	RootSizeMiB *int                        `yaml:"root_size_mib"`
+	Spares      *int                        `yaml:"spares"`
+}
+
+type BootDeviceMirrorPartition struct {
+	Format  *string              `yaml:"format"`
+	Label   string               `yaml:"label"`
+	Luks    BootDeviceVolumeLuks `yaml:"luks"`
+	Path    *string              `yaml:"path"`
+	SizeMiB *int                 `yaml:"size_mib"`
+}
+
+type BootDeviceVar struct {
+	Format  *string              `yaml:"format"`
+	Luks    BootDeviceVolumeLuks `yaml:"luks"`
+	SizeMiB *int                 `yaml:"size_mib"`
+}
+
+type BootDeviceVolumeLuks struct {
+	Discard   *bool       `yaml:"discard"`
+	Tang      []base.Tang `yaml:"tang"`
+	Threshold *int        `yaml:"threshold"`
+	Tpm2      *bool       `yaml:"tpm2"`
 }
 
 type Grub struct {
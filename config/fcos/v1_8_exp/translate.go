@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	baseutil "github.com/coreos/butane/base/util"
+	base "github.com/coreos/butane/base/v0_8_exp"
 	"github.com/coreos/butane/config/common"
 	cutil "github.com/coreos/butane/config/util"
 	"github.com/coreos/butane/translate"
@@ -53,6 +54,18 @@ const (
 	prepV1SizeMiB     = 4
 	espV1SizeMiB      = 127
 	bootV1SizeMiB     = 384
+
+	// root size when followed by a separate /var partition; the
+	// minimum recommended size
+	rootVarSizeMiB = 8192
+)
+
+var (
+	// partitions and filesystems of the OS image, on all layouts
+	imageDevices = base.ImageDevices{
+		PartLabels: []string{"BIOS-BOOT", "EFI-SYSTEM", "PowerPC-PReP-boot", "boot", "reserved", "root"},
+		FsLabels:   []string{"EFI-SYSTEM", "boot", "root"},
+	}
 )
 
 // Return FieldFilters for this spec.
@@ -85,7 +98,7 @@ func (c Config) ToIgn3_7Unvalidated(options common.TranslateOptions) (types.Conf
 								break
 							}
 						}
-					} else if *partition.SizeMiB < 8192 {
+					} else if *partition.SizeMiB < rootVarSizeMiB {
 						r.AddOnWarn(path.New("json", "storage", "disks", i, "partitions", p, "size_mib"), common.ErrRootTooSmall)
 					}
 				}
@@ -106,6 +119,7 @@ func (c Config) ToIgn3_7Unvalidated(options common.TranslateOptions) (types.Conf
 	retConfig, ts := baseutil.MergeTranslatedConfigs(retp, tsp, ret, ts)
 	ret = retConfig.(types.Config)
 	r.Merge(rp)
+	r.Merge(base.CheckStorageReferences(ret, imageDevices))
 	return ret, ts, r
 }
 
@@ -133,7 +147,8 @@ func (c Config) processBootDevice(config *types.Config, ts *translate.Translatio
 	// check for high-level features
 	wantLuks := util.IsTrue(c.BootDevice.Luks.Tpm2) || len(c.BootDevice.Luks.Tang) > 0 || util.IsTrue(c.BootDevice.Luks.Cex.Enabled)
 	wantMirror := len(c.BootDevice.Mirror.Devices) > 0
-	if !wantLuks && !wantMirror {
+	wantVar := c.BootDevice.Var.wanted()
+	if !wantLuks && !wantMirror && !wantVar {
 		return r
 	}
 
@@ -201,9 +216,40 @@ func (c Config) processBootDevice(config *types.Config, ts *translate.Translatio
 				Label:   util.StrToPtr(fmt.Sprintf("boot-%d", labelIndex)),
 				SizeMiB: util.IntToPtr(bootV1SizeMiB),
 			}, types.Partition{
-				Label: util.StrToPtr(fmt.Sprintf("root-%d", labelIndex)),
+				Label:   util.StrToPtr(fmt.Sprintf("root-%d", labelIndex)),
+				SizeMiB: c.BootDevice.Mirror.rootSizeMiB(wantVar),
 			})
-			renderedTranslations.AddFromCommonSource(path.New("yaml", "boot_device", "mirror", "devices", i), path.New("json", "storage", "disks", len(rendered.Storage.Disks)), disk)
+			rootIndex := len(disk.Partitions) - 1
+			varIndex := -1
+			if wantVar {
+				disk.Partitions = append(disk.Partitions, types.Partition{
+					Label:   util.StrToPtr(fmt.Sprintf("var-%d", labelIndex)),
+					SizeMiB: c.BootDevice.Var.sizeMiB(),
+				})
+				varIndex = len(disk.Partitions) - 1
+			}
+			for _, part := range c.BootDevice.Mirror.Partitions {
+				disk.Partitions = append(disk.Partitions, types.Partition{
+					Label:   util.StrToPtr(fmt.Sprintf("%s-%d", part.Label, labelIndex)),
+					SizeMiB: part.sizeMiB(),
+				})
+			}
+			dpath := path.New("json", "storage", "disks", len(rendered.Storage.Disks))
+			renderedTranslations.AddFromCommonSource(path.New("yaml", "boot_device", "mirror", "devices", i), dpath, disk)
+			if c.BootDevice.Mirror.RootSizeMiB != nil {
+				renderedTranslations.AddTranslation(path.New("yaml", "boot_device", "mirror", "root_size_mib"), dpath.Append("partitions", rootIndex, "sizeMiB"))
+			}
+			if wantVar && c.BootDevice.Var.SizeMiB != nil {
+				renderedTranslations.AddTranslation(path.New("yaml", "boot_device", "var", "size_mib"), dpath.Append("partitions", varIndex, "sizeMiB"))
+			}
+			for j, part := range c.BootDevice.Mirror.Partitions {
+				ppath := path.New("yaml", "boot_device", "mirror", "partitions", j)
+				partIndex := len(disk.Partitions) - len(c.BootDevice.Mirror.Partitions) + j
+				renderedTranslations.AddFromCommonSource(ppath, dpath.Append("partitions", partIndex), disk.Partitions[partIndex])
+				if part.SizeMiB != nil {
+					renderedTranslations.AddTranslation(ppath.Append("size_mib"), dpath.Append("partitions", partIndex, "sizeMiB"))
+				}
+			}
 			rendered.Storage.Disks = append(rendered.Storage.Disks, disk)
 
 			if wantEFIPart {
@@ -229,6 +275,7 @@ func (c Config) processBootDevice(config *types.Config, ts *translate.Translatio
 			}
 			return ret
 		}
+		level := c.BootDevice.Mirror.level()
 		rendered.Storage.Raid = []types.Raid{{
 			Devices: raidDevices("boot"),
 			Level:   util.StrToPtr("raid1"),
@@ -237,12 +284,44 @@ func (c Config) processBootDevice(config *types.Config, ts *translate.Translatio
 			// partition so BIOS GRUB doesn't need to
 			// understand RAID
 			Options: []types.RaidOption{"--metadata=1.0"},
+			Spares:  c.BootDevice.Mirror.spares(),
 		}, {
 			Devices: raidDevices("root"),
-			Level:   util.StrToPtr("raid1"),
+			Level:   util.StrToPtr(level),
 			Name:    "md-root",
+			Spares:  c.BootDevice.Mirror.spares(),
 		}}
+		if wantVar {
+			rendered.Storage.Raid = append(rendered.Storage.Raid, types.Raid{
+				Devices: raidDevices("var"),
+				Level:   util.StrToPtr(level),
+				Name:    "md-var",
+				Spares:  c.BootDevice.Mirror.spares(),
+			})
+		}
+		for _, part := range c.BootDevice.Mirror.Partitions {
+			rendered.Storage.Raid = append(rendered.Storage.Raid, types.Raid{
+				Devices: raidDevices(part.Label),
+				Level:   util.StrToPtr(level),
+				Name:    "md-" + part.Label,
+				Spares:  c.BootDevice.Mirror.spares(),
+			})
+		}
 		renderedTranslations.AddFromCommonSource(path.New("yaml", "boot_device", "mirror"), path.New("json", "storage", "raid"), rendered.Storage.Raid)
+		for j := range c.BootDevice.Mirror.Partitions {
+			raidIndex := len(rendered.Storage.Raid) - len(c.BootDevice.Mirror.Partitions) + j
+			renderedTranslations.AddFromCommonSource(path.New("yaml", "boot_device", "mirror", "partitions", j), path.New("json", "storage", "raid", raidIndex), rendered.Storage.Raid[raidIndex])
+		}
+		for i := range rendered.Storage.Raid {
+			// md-boot stays RAID1 so each member is readable on
+			// its own
+			if i > 0 && c.BootDevice.Mirror.Level != nil {
+				renderedTranslations.AddTranslation(path.New("yaml", "boot_device", "mirror", "level"), path.New("json", "storage", "raid", i, "level"))
+			}
+			if c.BootDevice.Mirror.Spares != nil {
+				renderedTranslations.AddTranslation(path.New("yaml", "boot_device", "mirror", "spares"), path.New("json", "storage", "raid", i, "spares"))
+			}
+		}
 
 		// create boot filesystem
 		bootFilesystem := types.Filesystem{
@@ -313,26 +392,41 @@ func (c Config) processBootDevice(config *types.Config, ts *translate.Translatio
 	}
 
 	// create root filesystem
-	var rootDevice string
-	switch {
-	case wantLuks:
-		// LUKS, or LUKS on RAID
-		rootDevice = "/dev/mapper/root"
-	case wantMirror:
-		// RAID without LUKS
-		rootDevice = "/dev/md/md-root"
-	default:
-		panic("can't happen")
+	if wantLuks || wantMirror {
+		var rootDevice string
+		if wantLuks {
+			// LUKS, or LUKS on RAID
+			rootDevice = "/dev/mapper/root"
+		} else {
+			// RAID without LUKS
+			rootDevice = "/dev/md/md-root"
+		}
+		rootFilesystem := types.Filesystem{
+			Device:         rootDevice,
+			Format:         util.StrToPtr("xfs"),
+			Label:          util.StrToPtr("root"),
+			WipeFilesystem: util.BoolToPtr(true),
+		}
+		renderedTranslations.AddFromCommonSource(path.New("yaml", "boot_device"), path.New("json", "storage", "filesystems", len(rendered.Storage.Filesystems)), rootFilesystem)
+		renderedTranslations.AddTranslation(path.New("yaml", "boot_device"), path.New("json", "storage", "filesystems"))
+		rendered.Storage.Filesystems = append(rendered.Storage.Filesystems, rootFilesystem)
+	}
+
+	// separate /var partition
+	if wantVar {
+		r.Merge(c.processBootDeviceVar(&rendered, &renderedTranslations, wantMirror, options))
 	}
-	rootFilesystem := types.Filesystem{
-		Device:         rootDevice,
-		Format:         util.StrToPtr("xfs"),
-		Label:          util.StrToPtr("root"),
-		WipeFilesystem: util.BoolToPtr(true),
+
+	// additional mirrored partitions
+	if wantMirror {
+		for i, part := range c.BootDevice.Mirror.Partitions {
+			ppath := path.New("yaml", "boot_device", "mirror", "partitions", i)
+			r.Merge(processBootDeviceVolume(&rendered, &renderedTranslations, ppath, "/dev/md/md-"+part.Label, part.Label, part.Format, part.Path, part.Luks, options))
+			if part.Path != nil {
+				renderedTranslations.AddTranslation(ppath.Append("path"), path.New("json", "storage", "filesystems", len(rendered.Storage.Filesystems)-1, "path"))
+			}
+		}
 	}
-	renderedTranslations.AddFromCommonSource(path.New("yaml", "boot_device"), path.New("json", "storage", "filesystems", len(rendered.Storage.Filesystems)), rootFilesystem)
-	renderedTranslations.AddTranslation(path.New("yaml", "boot_device"), path.New("json", "storage", "filesystems"))
-	rendered.Storage.Filesystems = append(rendered.Storage.Filesystems, rootFilesystem)
 
 	// merge with translated config
 	renderedTranslations.AddTranslation(path.New("yaml", "boot_device"), path.New("json", "storage"))
@@ -342,6 +436,170 @@ func (c Config) processBootDevice(config *types.Config, ts *translate.Translatio
 	return r
 }
 
+// processBootDeviceVar renders the /var partition, or the RAID of /var
+// partitions rendered with the mirror, and its LUKS volume, filesystem,
+// and mount unit.
+func (c Config) processBootDeviceVar(rendered *types.Config, renderedTranslations *translate.TranslationSet, wantMirror bool, options common.TranslateOptions) report.Report {
+	var r report.Report
+	vpath := path.New("yaml", "boot_device", "var")
+
+	// partition, unless the mirror created one on each disk
+	device := "/dev/md/md-var"
+	if !wantMirror {
+		device = "/dev/disk/by-partlabel/var"
+		disk := types.Disk{
+			Device: rootDevice,
+			Partitions: []types.Partition{{
+				Label:   util.StrToPtr("root"),
+				Number:  4,
+				Resize:  util.BoolToPtr(true),
+				SizeMiB: util.IntToPtr(rootVarSizeMiB),
+			}, {
+				Label:   util.StrToPtr("var"),
+				Number:  5,
+				SizeMiB: c.BootDevice.Var.sizeMiB(),
+			}},
+		}
+		dpath := path.New("json", "storage", "disks", len(rendered.Storage.Disks))
+		renderedTranslations.AddFromCommonSource(vpath, dpath, disk)
+		if c.BootDevice.Var.SizeMiB != nil {
+			renderedTranslations.AddTranslation(vpath.Append("size_mib"), dpath.Append("partitions", 1, "sizeMiB"))
+		}
+		renderedTranslations.AddTranslation(vpath, path.New("json", "storage", "disks"))
+		rendered.Storage.Disks = append(rendered.Storage.Disks, disk)
+	}
+
+	r.Merge(processBootDeviceVolume(rendered, renderedTranslations, vpath, device, "var", c.BootDevice.Var.Format, util.StrToPtr("/var"), c.BootDevice.Var.Luks, options))
+	return r
+}
+
+// processBootDeviceVolume renders the LUKS volume, filesystem, and mount
+// unit for a partition or RAID array created by boot_device.  The LUKS
+// volume and filesystem are named after name, and aren't wiped, so the
+// existing data is kept when a machine is reprovisioned.  The mount unit
+// is only rendered if mountPath is specified.
+func processBootDeviceVolume(rendered *types.Config, renderedTranslations *translate.TranslationSet, yamlPath path.ContextPath, device, name string, format, mountPath *string, luks BootDeviceVolumeLuks, options common.TranslateOptions) report.Report {
+	var r report.Report
+
+	// LUKS volume
+	if luks.wanted() {
+		clevis, ts2, r2 := translateBootDeviceLuks(BootDeviceLuks{
+			Tang:      luks.Tang,
+			Threshold: luks.Threshold,
+			Tpm2:      luks.Tpm2,
+		}, options)
+		luksVolume := types.Luks{
+			Clevis:  clevis,
+			Device:  util.StrToPtr(device),
+			Discard: luks.Discard,
+			Label:   util.StrToPtr("luks-" + name),
+			Name:    name,
+		}
+		lpath := yamlPath.Append("luks")
+		rpath := path.New("json", "storage", "luks", len(rendered.Storage.Luks))
+		renderedTranslations.Merge(ts2.PrefixPaths(lpath, rpath.Append("clevis")))
+		renderedTranslations.AddTranslation(lpath.Append("discard"), rpath.Append("discard"))
+		for _, f := range []string{"device", "label", "name"} {
+			renderedTranslations.AddTranslation(lpath, rpath.Append(f))
+		}
+		renderedTranslations.AddTranslation(lpath, rpath)
+		renderedTranslations.AddTranslation(lpath, path.New("json", "storage", "luks"))
+		rendered.Storage.Luks = append(rendered.Storage.Luks, luksVolume)
+		r.Merge(r2)
+		device = "/dev/mapper/" + name
+	}
+
+	// filesystem
+	fsFormat := "xfs"
+	if format != nil {
+		fsFormat = *format
+	}
+	filesystem := types.Filesystem{
+		Device: device,
+		Format: util.StrToPtr(fsFormat),
+		Label:  util.StrToPtr(name),
+		Path:   mountPath,
+	}
+	fspath := path.New("json", "storage", "filesystems", len(rendered.Storage.Filesystems))
+	renderedTranslations.AddFromCommonSource(yamlPath, fspath, filesystem)
+	if format != nil {
+		renderedTranslations.AddTranslation(yamlPath.Append("format"), fspath.Append("format"))
+	}
+	renderedTranslations.AddTranslation(yamlPath, path.New("json", "storage", "filesystems"))
+	rendered.Storage.Filesystems = append(rendered.Storage.Filesystems, filesystem)
+
+	// mount unit
+	if mountPath == nil {
+		return r
+	}
+	mountUnit := base.MountUnitFromFS(base.Filesystem{
+		Device: device,
+		Format: filesystem.Format,
+		Path:   filesystem.Path,
+	}, len(luks.Tang) > 0)
+	upath := path.New("json", "systemd", "units", len(rendered.Systemd.Units))
+	renderedTranslations.AddFromCommonSource(yamlPath, upath, mountUnit)
+	renderedTranslations.AddTranslation(yamlPath, path.New("json", "systemd", "units"))
+	renderedTranslations.AddTranslation(yamlPath, path.New("json", "systemd"))
+	rendered.Systemd.Units = append(rendered.Systemd.Units, mountUnit)
+	return r
+}
+
+// wanted returns true if a separate /var partition is requested.
+func (v BootDeviceVar) wanted() bool {
+	return v.SizeMiB != nil || v.Format != nil || v.Luks.wanted()
+}
+
+// sizeMiB returns the size of the /var partition, where 0 fills the
+// rest of the disk.
+func (v BootDeviceVar) sizeMiB() *int {
+	if v.SizeMiB == nil {
+		return util.IntToPtr(0)
+	}
+	return util.IntToPtr(*v.SizeMiB)
+}
+
+// rootSizeMiB returns the size of the mirrored root partitions, where nil
+// fills the rest of the disk.
+func (m BootDeviceMirror) rootSizeMiB(wantVar bool) *int {
+	switch {
+	case m.RootSizeMiB != nil:
+		return util.IntToPtr(*m.RootSizeMiB)
+	case wantVar || len(m.Partitions) > 0:
+		return util.IntToPtr(rootVarSizeMiB)
+	default:
+		return nil
+	}
+}
+
+// level returns the RAID level of the mirrored arrays other than md-boot.
+func (m BootDeviceMirror) level() string {
+	if m.Level == nil {
+		return "raid1"
+	}
+	return *m.Level
+}
+
+func (m BootDeviceMirror) spares() *int {
+	if m.Spares == nil {
+		return nil
+	}
+	return util.IntToPtr(*m.Spares)
+}
+
+// sizeMiB returns the size of the mirrored partition, where 0 fills the
+// rest of the disk.
+func (p BootDeviceMirrorPartition) sizeMiB() *int {
+	if p.SizeMiB == nil {
+		return util.IntToPtr(0)
+	}
+	return util.IntToPtr(*p.SizeMiB)
+}
+
+func (l BootDeviceVolumeLuks) wanted() bool {
+	return len(l.Tang) > 0 || util.IsTrue(l.Tpm2)
+}
+
 func translateBootDeviceLuks(from BootDeviceLuks, options common.TranslateOptions) (to types.Clevis, tm translate.TranslationSet, r report.Report) {
 	tr := translate.NewTranslator("yaml", "json", options)
 	// Discard field is handled by the caller because it doesn't go
@@ -388,7 +646,7 @@ func (c Config) handleUserGrubCfg(options common.TranslateOptions) (types.Config
 		})
 
 	userCfgContent := []byte(buildGrubConfig(c.Grub))
-	src, compression, err := baseutil.MakeDataURL(userCfgContent, nil, !options.NoResourceAutoCompression)
+	src, compression, err := baseutil.MakeDataURLWithOptions(userCfgContent, nil, options)
 	if err != nil {
 		r.AddOnError(yamlPath, err)
 		return rendered, ts, r
@@ -1482,6 +1482,742 @@ func TestTranslateBootDevice(t *testing.T) {
 			},
 			report.Report{},
 		},
+		// separate /var partition with LUKS
+		{
+			Config{
+				BootDevice: BootDevice{
+					Var: BootDeviceVar{
+						Format:  util.StrToPtr("ext4"),
+						SizeMiB: util.IntToPtr(20480),
+						Luks: BootDeviceVolumeLuks{
+							Discard: util.BoolToPtr(true),
+							Tang: []base.Tang{{
+								URL:        "https://example.com/",
+								Thumbprint: util.StrToPtr("z"),
+							}},
+						},
+					},
+				},
+			},
+			types.Config{
+				Ignition: types.Ignition{
+					Version: "3.7.0-experimental",
+				},
+				Storage: types.Storage{
+					Disks: []types.Disk{
+						{
+							Device: "/dev/disk/by-id/coreos-boot-disk",
+							Partitions: []types.Partition{
+								{
+									Label:   util.StrToPtr("root"),
+									Number:  4,
+									Resize:  util.BoolToPtr(true),
+									SizeMiB: util.IntToPtr(rootVarSizeMiB),
+								},
+								{
+									Label:   util.StrToPtr("var"),
+									Number:  5,
+									SizeMiB: util.IntToPtr(20480),
+								},
+							},
+						},
+					},
+					Luks: []types.Luks{
+						{
+							Clevis: types.Clevis{
+								Tang: []types.Tang{{
+									URL:        "https://example.com/",
+									Thumbprint: util.StrToPtr("z"),
+								}},
+							},
+							Device:  util.StrToPtr("/dev/disk/by-partlabel/var"),
+							Discard: util.BoolToPtr(true),
+							Label:   util.StrToPtr("luks-var"),
+							Name:    "var",
+						},
+					},
+					Filesystems: []types.Filesystem{
+						{
+							Device: "/dev/mapper/var",
+							Format: util.StrToPtr("ext4"),
+							Label:  util.StrToPtr("var"),
+							Path:   util.StrToPtr("/var"),
+						},
+					},
+				},
+				Systemd: types.Systemd{
+					Units: []types.Unit{
+						{
+							Name:     "var.mount",
+							Enabled:  util.BoolToPtr(true),
+							Contents: util.StrToPtr("# Generated by Butane\n[Unit]\nRequires=systemd-fsck@dev-mapper-var.service\nAfter=systemd-fsck@dev-mapper-var.service\n\n[Mount]\nWhere=/var\nWhat=/dev/mapper/var\nType=ext4\nOptions=_netdev\n\n[Install]\nRequiredBy=remote-fs.target"),
+						},
+					},
+				},
+			},
+			[]translate.Translation{
+				{From: path.New("yaml", "version"), To: path.New("json", "ignition", "version")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "disks", 0, "device")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "disks", 0, "partitions", 0, "label")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "disks", 0, "partitions", 0, "number")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "disks", 0, "partitions", 0, "resize")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "disks", 0, "partitions", 0, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "disks", 0, "partitions", 0)},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "disks", 0, "partitions", 1, "label")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "disks", 0, "partitions", 1, "number")},
+				{From: path.New("yaml", "boot_device", "var", "size_mib"), To: path.New("json", "storage", "disks", 0, "partitions", 1, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "disks", 0, "partitions", 1)},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "disks", 0, "partitions")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "disks", 0)},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "disks")},
+				{From: path.New("yaml", "boot_device", "var", "luks", "tang", 0, "url"), To: path.New("json", "storage", "luks", 0, "clevis", "tang", 0, "url")},
+				{From: path.New("yaml", "boot_device", "var", "luks", "tang", 0, "thumbprint"), To: path.New("json", "storage", "luks", 0, "clevis", "tang", 0, "thumbprint")},
+				{From: path.New("yaml", "boot_device", "var", "luks", "tang", 0), To: path.New("json", "storage", "luks", 0, "clevis", "tang", 0)},
+				{From: path.New("yaml", "boot_device", "var", "luks", "tang"), To: path.New("json", "storage", "luks", 0, "clevis", "tang")},
+				{From: path.New("yaml", "boot_device", "var", "luks", "discard"), To: path.New("json", "storage", "luks", 0, "discard")},
+				{From: path.New("yaml", "boot_device", "var", "luks"), To: path.New("json", "storage", "luks", 0, "clevis")},
+				{From: path.New("yaml", "boot_device", "var", "luks"), To: path.New("json", "storage", "luks", 0, "device")},
+				{From: path.New("yaml", "boot_device", "var", "luks"), To: path.New("json", "storage", "luks", 0, "label")},
+				{From: path.New("yaml", "boot_device", "var", "luks"), To: path.New("json", "storage", "luks", 0, "name")},
+				{From: path.New("yaml", "boot_device", "var", "luks"), To: path.New("json", "storage", "luks", 0)},
+				{From: path.New("yaml", "boot_device", "var", "luks"), To: path.New("json", "storage", "luks")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "filesystems", 0, "device")},
+				{From: path.New("yaml", "boot_device", "var", "format"), To: path.New("json", "storage", "filesystems", 0, "format")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "filesystems", 0, "label")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "filesystems", 0, "path")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "filesystems", 0)},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "storage", "filesystems")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "systemd", "units", 0, "contents")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "systemd", "units", 0, "enabled")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "systemd", "units", 0, "name")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "systemd", "units", 0)},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "systemd", "units")},
+				{From: path.New("yaml", "boot_device", "var"), To: path.New("json", "systemd")},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage")},
+			},
+			report.Report{},
+		},
+		// 2-disk mirror with root size and additional partition, aarch64
+		{
+			Config{
+				BootDevice: BootDevice{
+					Layout: util.StrToPtr("aarch64"),
+					Mirror: BootDeviceMirror{
+						Devices:     []string{"/dev/vda", "/dev/vdb"},
+						RootSizeMiB: util.IntToPtr(16384),
+						Partitions: []BootDeviceMirrorPartition{
+							{
+								Label:   "data",
+								SizeMiB: util.IntToPtr(10240),
+								Path:    util.StrToPtr("/var/lib/data"),
+								Luks: BootDeviceVolumeLuks{
+									Tpm2: util.BoolToPtr(true),
+								},
+							},
+						},
+					},
+				},
+			},
+			types.Config{
+				Ignition: types.Ignition{
+					Version: "3.7.0-experimental",
+				},
+				Storage: types.Storage{
+					Disks: []types.Disk{
+						{
+							Device: "/dev/vda",
+							Partitions: []types.Partition{
+								{
+									Label:    util.StrToPtr("reserved-1"),
+									SizeMiB:  util.IntToPtr(reservedV1SizeMiB),
+									TypeGUID: util.StrToPtr(reservedTypeGuid),
+								},
+								{
+									Label:    util.StrToPtr("esp-1"),
+									SizeMiB:  util.IntToPtr(espV1SizeMiB),
+									TypeGUID: util.StrToPtr(espTypeGuid),
+								},
+								{
+									Label:   util.StrToPtr("boot-1"),
+									SizeMiB: util.IntToPtr(bootV1SizeMiB),
+								},
+								{
+									Label:   util.StrToPtr("root-1"),
+									SizeMiB: util.IntToPtr(16384),
+								},
+								{
+									Label:   util.StrToPtr("data-1"),
+									SizeMiB: util.IntToPtr(10240),
+								},
+							},
+							WipeTable: util.BoolToPtr(true),
+						},
+						{
+							Device: "/dev/vdb",
+							Partitions: []types.Partition{
+								{
+									Label:    util.StrToPtr("reserved-2"),
+									SizeMiB:  util.IntToPtr(reservedV1SizeMiB),
+									TypeGUID: util.StrToPtr(reservedTypeGuid),
+								},
+								{
+									Label:    util.StrToPtr("esp-2"),
+									SizeMiB:  util.IntToPtr(espV1SizeMiB),
+									TypeGUID: util.StrToPtr(espTypeGuid),
+								},
+								{
+									Label:   util.StrToPtr("boot-2"),
+									SizeMiB: util.IntToPtr(bootV1SizeMiB),
+								},
+								{
+									Label:   util.StrToPtr("root-2"),
+									SizeMiB: util.IntToPtr(16384),
+								},
+								{
+									Label:   util.StrToPtr("data-2"),
+									SizeMiB: util.IntToPtr(10240),
+								},
+							},
+							WipeTable: util.BoolToPtr(true),
+						},
+					},
+					Raid: []types.Raid{
+						{
+							Devices: []types.Device{
+								"/dev/disk/by-partlabel/boot-1",
+								"/dev/disk/by-partlabel/boot-2",
+							},
+							Level:   util.StrToPtr("raid1"),
+							Name:    "md-boot",
+							Options: []types.RaidOption{"--metadata=1.0"},
+						},
+						{
+							Devices: []types.Device{
+								"/dev/disk/by-partlabel/root-1",
+								"/dev/disk/by-partlabel/root-2",
+							},
+							Level: util.StrToPtr("raid1"),
+							Name:  "md-root",
+						},
+						{
+							Devices: []types.Device{
+								"/dev/disk/by-partlabel/data-1",
+								"/dev/disk/by-partlabel/data-2",
+							},
+							Level: util.StrToPtr("raid1"),
+							Name:  "md-data",
+						},
+					},
+					Luks: []types.Luks{
+						{
+							Clevis: types.Clevis{
+								Tpm2: util.BoolToPtr(true),
+							},
+							Device: util.StrToPtr("/dev/md/md-data"),
+							Label:  util.StrToPtr("luks-data"),
+							Name:   "data",
+						},
+					},
+					Filesystems: []types.Filesystem{
+						{
+							Device:         "/dev/disk/by-partlabel/esp-1",
+							Format:         util.StrToPtr("vfat"),
+							Label:          util.StrToPtr("esp-1"),
+							WipeFilesystem: util.BoolToPtr(true),
+						}, {
+							Device:         "/dev/disk/by-partlabel/esp-2",
+							Format:         util.StrToPtr("vfat"),
+							Label:          util.StrToPtr("esp-2"),
+							WipeFilesystem: util.BoolToPtr(true),
+						}, {
+							Device:         "/dev/md/md-boot",
+							Format:         util.StrToPtr("ext4"),
+							Label:          util.StrToPtr("boot"),
+							WipeFilesystem: util.BoolToPtr(true),
+						}, {
+							Device:         "/dev/md/md-root",
+							Format:         util.StrToPtr("xfs"),
+							Label:          util.StrToPtr("root"),
+							WipeFilesystem: util.BoolToPtr(true),
+						}, {
+							Device: "/dev/mapper/data",
+							Format: util.StrToPtr("xfs"),
+							Label:  util.StrToPtr("data"),
+							Path:   util.StrToPtr("/var/lib/data"),
+						},
+					},
+				},
+				Systemd: types.Systemd{
+					Units: []types.Unit{
+						{
+							Name:     "var-lib-data.mount",
+							Enabled:  util.BoolToPtr(true),
+							Contents: util.StrToPtr("# Generated by Butane\n[Unit]\nRequires=systemd-fsck@dev-mapper-data.service\nAfter=systemd-fsck@dev-mapper-data.service\n\n[Mount]\nWhere=/var/lib/data\nWhat=/dev/mapper/data\nType=xfs\n\n[Install]\nRequiredBy=local-fs.target"),
+						},
+					},
+				},
+			},
+			[]translate.Translation{
+				{From: path.New("yaml", "version"), To: path.New("json", "ignition", "version")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 0, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 0, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 0, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 1, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 1, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 1, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 1)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 2, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 2, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 2)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 3, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 3)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 4, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "wipeTable")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "filesystems", 0, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "filesystems", 0, "format")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "filesystems", 0, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "filesystems", 0, "wipeFilesystem")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "filesystems", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 0, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 0, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 0, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 1, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 1, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 1, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 1)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 2, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 2, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 2)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 3, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 3)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 4, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "wipeTable")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "filesystems", 1, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "filesystems", 1, "format")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "filesystems", 1, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "filesystems", 1, "wipeFilesystem")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "filesystems", 1)},
+				{From: path.New("yaml", "boot_device", "mirror", "root_size_mib"), To: path.New("json", "storage", "disks", 0, "partitions", 3, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "root_size_mib"), To: path.New("json", "storage", "disks", 1, "partitions", 3, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0, "size_mib"), To: path.New("json", "storage", "disks", 0, "partitions", 4, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0, "size_mib"), To: path.New("json", "storage", "disks", 1, "partitions", 4, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0, "path"), To: path.New("json", "storage", "filesystems", 4, "path")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0, "luks", "tpm2"), To: path.New("json", "storage", "luks", 0, "clevis", "tpm2")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0, "luks"), To: path.New("json", "storage", "luks", 0, "clevis")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0, "luks"), To: path.New("json", "storage", "luks", 0, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0, "luks"), To: path.New("json", "storage", "luks", 0, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0, "luks"), To: path.New("json", "storage", "luks", 0, "name")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0, "luks"), To: path.New("json", "storage", "luks", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0, "luks"), To: path.New("json", "storage", "luks")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "storage", "disks", 0, "partitions", 4)},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "storage", "disks", 1, "partitions", 4)},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "storage", "filesystems", 4, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "storage", "filesystems", 4, "format")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "storage", "filesystems", 4, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "storage", "filesystems", 4)},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "storage", "filesystems")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "storage", "raid", 2, "devices", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "storage", "raid", 2, "devices", 1)},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "storage", "raid", 2, "devices")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "storage", "raid", 2, "level")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "storage", "raid", 2, "name")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "storage", "raid", 2)},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "systemd", "units", 0, "contents")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "systemd", "units", 0, "enabled")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "systemd", "units", 0, "name")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "systemd", "units", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "systemd", "units")},
+				{From: path.New("yaml", "boot_device", "mirror", "partitions", 0), To: path.New("json", "systemd")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices"), To: path.New("json", "storage", "disks")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "filesystems", 2, "device")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "filesystems", 2, "format")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "filesystems", 2, "label")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "filesystems", 2, "wipeFilesystem")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "filesystems", 2)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "devices", 0)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "devices", 1)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "devices")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "level")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "name")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "options", 0)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "options")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1, "devices", 0)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1, "devices", 1)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1, "devices")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1, "level")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1, "name")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid")},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage", "filesystems", 3, "device")},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage", "filesystems", 3, "format")},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage", "filesystems", 3, "label")},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage", "filesystems", 3, "wipeFilesystem")},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage", "filesystems", 3)},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage")},
+			},
+			report.Report{},
+		},
+		// 5-disk RAID10 mirror with a spare, aarch64
+		{
+			Config{
+				BootDevice: BootDevice{
+					Layout: util.StrToPtr("aarch64"),
+					Mirror: BootDeviceMirror{
+						Devices: []string{"/dev/vda", "/dev/vdb", "/dev/vdc", "/dev/vdd", "/dev/vde"},
+						Level:   util.StrToPtr("raid10"),
+						Spares:  util.IntToPtr(1),
+					},
+				},
+			},
+			types.Config{
+				Ignition: types.Ignition{
+					Version: "3.7.0-experimental",
+				},
+				Storage: types.Storage{
+					Disks: []types.Disk{
+						{
+							Device: "/dev/vda",
+							Partitions: []types.Partition{
+								{
+									Label:    util.StrToPtr("reserved-1"),
+									SizeMiB:  util.IntToPtr(reservedV1SizeMiB),
+									TypeGUID: util.StrToPtr(reservedTypeGuid),
+								},
+								{
+									Label:    util.StrToPtr("esp-1"),
+									SizeMiB:  util.IntToPtr(espV1SizeMiB),
+									TypeGUID: util.StrToPtr(espTypeGuid),
+								},
+								{
+									Label:   util.StrToPtr("boot-1"),
+									SizeMiB: util.IntToPtr(bootV1SizeMiB),
+								},
+								{
+									Label: util.StrToPtr("root-1"),
+								},
+							},
+							WipeTable: util.BoolToPtr(true),
+						},
+						{
+							Device: "/dev/vdb",
+							Partitions: []types.Partition{
+								{
+									Label:    util.StrToPtr("reserved-2"),
+									SizeMiB:  util.IntToPtr(reservedV1SizeMiB),
+									TypeGUID: util.StrToPtr(reservedTypeGuid),
+								},
+								{
+									Label:    util.StrToPtr("esp-2"),
+									SizeMiB:  util.IntToPtr(espV1SizeMiB),
+									TypeGUID: util.StrToPtr(espTypeGuid),
+								},
+								{
+									Label:   util.StrToPtr("boot-2"),
+									SizeMiB: util.IntToPtr(bootV1SizeMiB),
+								},
+								{
+									Label: util.StrToPtr("root-2"),
+								},
+							},
+							WipeTable: util.BoolToPtr(true),
+						},
+						{
+							Device: "/dev/vdc",
+							Partitions: []types.Partition{
+								{
+									Label:    util.StrToPtr("reserved-3"),
+									SizeMiB:  util.IntToPtr(reservedV1SizeMiB),
+									TypeGUID: util.StrToPtr(reservedTypeGuid),
+								},
+								{
+									Label:    util.StrToPtr("esp-3"),
+									SizeMiB:  util.IntToPtr(espV1SizeMiB),
+									TypeGUID: util.StrToPtr(espTypeGuid),
+								},
+								{
+									Label:   util.StrToPtr("boot-3"),
+									SizeMiB: util.IntToPtr(bootV1SizeMiB),
+								},
+								{
+									Label: util.StrToPtr("root-3"),
+								},
+							},
+							WipeTable: util.BoolToPtr(true),
+						},
+						{
+							Device: "/dev/vdd",
+							Partitions: []types.Partition{
+								{
+									Label:    util.StrToPtr("reserved-4"),
+									SizeMiB:  util.IntToPtr(reservedV1SizeMiB),
+									TypeGUID: util.StrToPtr(reservedTypeGuid),
+								},
+								{
+									Label:    util.StrToPtr("esp-4"),
+									SizeMiB:  util.IntToPtr(espV1SizeMiB),
+									TypeGUID: util.StrToPtr(espTypeGuid),
+								},
+								{
+									Label:   util.StrToPtr("boot-4"),
+									SizeMiB: util.IntToPtr(bootV1SizeMiB),
+								},
+								{
+									Label: util.StrToPtr("root-4"),
+								},
+							},
+							WipeTable: util.BoolToPtr(true),
+						},
+						{
+							Device: "/dev/vde",
+							Partitions: []types.Partition{
+								{
+									Label:    util.StrToPtr("reserved-5"),
+									SizeMiB:  util.IntToPtr(reservedV1SizeMiB),
+									TypeGUID: util.StrToPtr(reservedTypeGuid),
+								},
+								{
+									Label:    util.StrToPtr("esp-5"),
+									SizeMiB:  util.IntToPtr(espV1SizeMiB),
+									TypeGUID: util.StrToPtr(espTypeGuid),
+								},
+								{
+									Label:   util.StrToPtr("boot-5"),
+									SizeMiB: util.IntToPtr(bootV1SizeMiB),
+								},
+								{
+									Label: util.StrToPtr("root-5"),
+								},
+							},
+							WipeTable: util.BoolToPtr(true),
+						},
+					},
+					Raid: []types.Raid{
+						{
+							Devices: []types.Device{
+								"/dev/disk/by-partlabel/boot-1",
+								"/dev/disk/by-partlabel/boot-2",
+								"/dev/disk/by-partlabel/boot-3",
+								"/dev/disk/by-partlabel/boot-4",
+								"/dev/disk/by-partlabel/boot-5",
+							},
+							Level:   util.StrToPtr("raid1"),
+							Name:    "md-boot",
+							Options: []types.RaidOption{"--metadata=1.0"},
+							Spares:  util.IntToPtr(1),
+						},
+						{
+							Devices: []types.Device{
+								"/dev/disk/by-partlabel/root-1",
+								"/dev/disk/by-partlabel/root-2",
+								"/dev/disk/by-partlabel/root-3",
+								"/dev/disk/by-partlabel/root-4",
+								"/dev/disk/by-partlabel/root-5",
+							},
+							Level:  util.StrToPtr("raid10"),
+							Name:   "md-root",
+							Spares: util.IntToPtr(1),
+						},
+					},
+					Filesystems: []types.Filesystem{
+						{
+							Device:         "/dev/disk/by-partlabel/esp-1",
+							Format:         util.StrToPtr("vfat"),
+							Label:          util.StrToPtr("esp-1"),
+							WipeFilesystem: util.BoolToPtr(true),
+						}, {
+							Device:         "/dev/disk/by-partlabel/esp-2",
+							Format:         util.StrToPtr("vfat"),
+							Label:          util.StrToPtr("esp-2"),
+							WipeFilesystem: util.BoolToPtr(true),
+						}, {
+							Device:         "/dev/disk/by-partlabel/esp-3",
+							Format:         util.StrToPtr("vfat"),
+							Label:          util.StrToPtr("esp-3"),
+							WipeFilesystem: util.BoolToPtr(true),
+						}, {
+							Device:         "/dev/disk/by-partlabel/esp-4",
+							Format:         util.StrToPtr("vfat"),
+							Label:          util.StrToPtr("esp-4"),
+							WipeFilesystem: util.BoolToPtr(true),
+						}, {
+							Device:         "/dev/disk/by-partlabel/esp-5",
+							Format:         util.StrToPtr("vfat"),
+							Label:          util.StrToPtr("esp-5"),
+							WipeFilesystem: util.BoolToPtr(true),
+						}, {
+							Device:         "/dev/md/md-boot",
+							Format:         util.StrToPtr("ext4"),
+							Label:          util.StrToPtr("boot"),
+							WipeFilesystem: util.BoolToPtr(true),
+						}, {
+							Device:         "/dev/md/md-root",
+							Format:         util.StrToPtr("xfs"),
+							Label:          util.StrToPtr("root"),
+							WipeFilesystem: util.BoolToPtr(true),
+						},
+					},
+				},
+			},
+			[]translate.Translation{
+				{From: path.New("yaml", "version"), To: path.New("json", "ignition", "version")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 0, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 0, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 0, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 1, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 1, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 1, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 1)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 2, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 2, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 2)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 3, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions", 3)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "partitions")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0, "wipeTable")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "disks", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "filesystems", 0, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "filesystems", 0, "format")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "filesystems", 0, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "filesystems", 0, "wipeFilesystem")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 0), To: path.New("json", "storage", "filesystems", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 0, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 0, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 0, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 1, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 1, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 1, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 1)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 2, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 2, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 2)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 3, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions", 3)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "partitions")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1, "wipeTable")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "disks", 1)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "filesystems", 1, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "filesystems", 1, "format")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "filesystems", 1, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "filesystems", 1, "wipeFilesystem")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 1), To: path.New("json", "storage", "filesystems", 1)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions", 0, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions", 0, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions", 0, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions", 1, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions", 1, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions", 1, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions", 1)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions", 2, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions", 2, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions", 2)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions", 3, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions", 3)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "partitions")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2, "wipeTable")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "disks", 2)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "filesystems", 2, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "filesystems", 2, "format")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "filesystems", 2, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "filesystems", 2, "wipeFilesystem")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 2), To: path.New("json", "storage", "filesystems", 2)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions", 0, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions", 0, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions", 0, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions", 1, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions", 1, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions", 1, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions", 1)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions", 2, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions", 2, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions", 2)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions", 3, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions", 3)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "partitions")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3, "wipeTable")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "disks", 3)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "filesystems", 3, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "filesystems", 3, "format")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "filesystems", 3, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "filesystems", 3, "wipeFilesystem")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 3), To: path.New("json", "storage", "filesystems", 3)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions", 0, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions", 0, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions", 0, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions", 0)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions", 1, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions", 1, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions", 1, "typeGuid")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions", 1)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions", 2, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions", 2, "sizeMiB")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions", 2)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions", 3, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions", 3)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "partitions")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4, "wipeTable")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "disks", 4)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "filesystems", 4, "device")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "filesystems", 4, "format")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "filesystems", 4, "label")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "filesystems", 4, "wipeFilesystem")},
+				{From: path.New("yaml", "boot_device", "mirror", "devices", 4), To: path.New("json", "storage", "filesystems", 4)},
+				{From: path.New("yaml", "boot_device", "mirror", "devices"), To: path.New("json", "storage", "disks")},
+				{From: path.New("yaml", "boot_device", "mirror", "level"), To: path.New("json", "storage", "raid", 1, "level")},
+				{From: path.New("yaml", "boot_device", "mirror", "spares"), To: path.New("json", "storage", "raid", 0, "spares")},
+				{From: path.New("yaml", "boot_device", "mirror", "spares"), To: path.New("json", "storage", "raid", 1, "spares")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "filesystems", 5, "device")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "filesystems", 5, "format")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "filesystems", 5, "label")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "filesystems", 5, "wipeFilesystem")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "filesystems", 5)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "devices", 0)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "devices", 1)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "devices", 2)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "devices", 3)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "devices", 4)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "devices")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "level")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "name")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "options", 0)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0, "options")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 0)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1, "devices", 0)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1, "devices", 1)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1, "devices", 2)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1, "devices", 3)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1, "devices", 4)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1, "devices")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1, "name")},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid", 1)},
+				{From: path.New("yaml", "boot_device", "mirror"), To: path.New("json", "storage", "raid")},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage", "filesystems", 6, "device")},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage", "filesystems", 6, "format")},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage", "filesystems", 6, "label")},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage", "filesystems", 6, "wipeFilesystem")},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage", "filesystems", 6)},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage", "filesystems")},
+				{From: path.New("yaml", "boot_device"), To: path.New("json", "storage")},
+			},
+			report.Report{},
+		},
 	}
 
 	// The partition sizes of existing layouts must never change, but
@@ -1506,6 +2242,50 @@ func TestTranslateBootDevice(t *testing.T) {
 	}
 }
 
+// TestTranslateBootDeviceNoWipe tests that the /var and additional
+// mirrored volumes aren't wiped, so they're reused on reprovisioning.
+func TestTranslateBootDeviceNoWipe(t *testing.T) {
+	luks := BootDeviceVolumeLuks{
+		Tpm2: util.BoolToPtr(true),
+	}
+	in := Config{
+		BootDevice: BootDevice{
+			Mirror: BootDeviceMirror{
+				Devices: []string{"/dev/vda", "/dev/vdb"},
+				Partitions: []BootDeviceMirrorPartition{
+					{
+						Label:   "data",
+						SizeMiB: util.IntToPtr(10240),
+						Path:    util.StrToPtr("/var/lib/data"),
+						Luks:    luks,
+					},
+				},
+			},
+			Var: BootDeviceVar{
+				Luks: luks,
+			},
+		},
+	}
+	actual, _, r := in.ToIgn3_7Unvalidated(common.TranslateOptions{})
+	assert.Equal(t, report.Report{}, r, "report mismatch")
+	for _, name := range []string{"var", "data"} {
+		found := false
+		for _, luks := range actual.Storage.Luks {
+			if luks.Name == name {
+				found = true
+				assert.Nil(t, luks.WipeVolume, "LUKS volume %s is wiped", name)
+			}
+		}
+		for _, fs := range actual.Storage.Filesystems {
+			if fs.Label != nil && *fs.Label == name {
+				found = true
+				assert.Nil(t, fs.WipeFilesystem, "filesystem %s is wiped", name)
+			}
+		}
+		assert.True(t, found, "volume %s not found", name)
+	}
+}
+
 // TestTranslateGrub tests translating the Butane config Grub section.
 func TestTranslateGrub(t *testing.T) {
 	// Some tests below have the same translations
@@ -1876,3 +2656,75 @@ func TestRootPartitionConstraints(t *testing.T) {
 		})
 	}
 }
+
+// TestStorageReferences tests that references to devices generated by
+// sugar or present in the OS image aren't reported, but typos are.
+func TestStorageReferences(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     Config
+		report report.Report
+	}{
+		{
+			name: "boot device sugar and OS image",
+			in: Config{
+				Config: base.Config{
+					Storage: base.Storage{
+						Filesystems: []base.Filesystem{
+							{
+								Device: "/dev/disk/by-label/boot",
+								Path:   util.StrToPtr("/boot"),
+							},
+						},
+					},
+				},
+				BootDevice: BootDevice{
+					Layout: util.StrToPtr("x86_64"),
+					Luks: BootDeviceLuks{
+						Tpm2: util.BoolToPtr(true),
+					},
+					Mirror: BootDeviceMirror{
+						Devices: []string{"/dev/vda", "/dev/vdb"},
+					},
+					Var: BootDeviceVar{
+						Luks: BootDeviceVolumeLuks{
+							Tpm2: util.BoolToPtr(true),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "misspelled partition label",
+			in: Config{
+				Config: base.Config{
+					Storage: base.Storage{
+						Filesystems: []base.Filesystem{
+							{
+								Device: "/dev/disk/by-partlabel/roott",
+								Format: util.StrToPtr("xfs"),
+							},
+						},
+					},
+				},
+			},
+			report: report.Report{
+				Entries: []report.Entry{
+					{
+						Kind:    report.Warn,
+						Message: common.ErrUnknownPartLabel.Error(),
+						Context: path.New("yaml", "storage", "filesystems", 0, "device"),
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, translations, r := test.in.ToIgn3_7Unvalidated(common.TranslateOptions{})
+			r = confutil.TranslateReportPaths(r, translations)
+			assert.Equal(t, test.report, r, "report mismatch")
+		})
+	}
+}
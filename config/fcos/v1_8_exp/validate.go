@@ -30,6 +30,11 @@ import (
 const rootDevice = "/dev/disk/by-id/coreos-boot-disk"
 
 var allowedMountpoints = regexp.MustCompile(`^/(etc|var)(/|$)`)
+var mirrorLabelRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+var reservedMirrorLabels = map[string]bool{"bios": true, "boot": true, "esp": true, "prep": true, "reserved": true, "root": true, "var": true}
+
+// minimum number of active devices for each mirror level
+var mirrorLevelDevices = map[string]int{"raid1": 2, "raid10": 4}
 var dasdRe = regexp.MustCompile("(/dev/dasd[a-z]$)")
 var sdRe = regexp.MustCompile("(/dev/sd[a-z]$)")
 
@@ -50,6 +55,28 @@ func (conf Config) Validate(c path.ContextPath) (r report.Report) {
 			r.AddOnError(c.Append("storage", "filesystems", i, "path"), common.ErrMountPointForbidden)
 		}
 	}
+	for i, dd := range conf.Storage.DataDevices {
+		if dd.Path != nil && !allowedMountpoints.MatchString(*dd.Path) {
+			r.AddOnError(c.Append("storage", "data_devices", i, "path"), common.ErrDataDeviceMountPoint)
+		}
+	}
+	for i, vg := range conf.Storage.Lvm {
+		for j, lv := range vg.LogicalVolumes {
+			if lv.Path != nil && !allowedMountpoints.MatchString(*lv.Path) {
+				r.AddOnError(c.Append("storage", "lvm", i, "logical_volumes", j, "path"), common.ErrLvmMountPoint)
+			}
+		}
+	}
+	for i, fs := range conf.Storage.Filesystems {
+		for j, sv := range fs.Subvolumes {
+			if sv.Path != nil && !allowedMountpoints.MatchString(*sv.Path) {
+				r.AddOnError(c.Append("storage", "filesystems", i, "subvolumes", j, "path"), common.ErrSubvolumeMountPoint)
+			}
+		}
+	}
+	if swapFile := conf.Storage.Swap.File.Path; swapFile != nil && !allowedMountpoints.MatchString(*swapFile) {
+		r.AddOnError(c.Append("storage", "swap", "file", "path"), common.ErrSwapFileMountPoint)
+	}
 	return
 }
 
@@ -97,7 +124,18 @@ func (d BootDevice) Validate(c path.ContextPath) (r report.Report) {
 		}
 	}
 
+	// DASDs have at most three partitions
+	if d.Layout != nil && *d.Layout == "s390x-eckd" && d.Var.wanted() {
+		r.AddOnError(c.Append("var"), common.ErrVarNotSupport)
+	}
+
+	// mirrored partitions follow /var, so it can't fill the disk
+	if len(d.Mirror.Partitions) > 0 && d.Var.wanted() && (d.Var.SizeMiB == nil || *d.Var.SizeMiB == 0) {
+		r.AddOnError(c.Append("var", "size_mib"), common.ErrVarSizeRequired)
+	}
+
 	r.Merge(d.Mirror.Validate(c.Append("mirror")))
+	r.Merge(d.Mirror.validateLayout(c.Append("mirror")))
 	return
 }
 
@@ -121,6 +159,22 @@ func (l BootDeviceLuks) Validate(c path.ContextPath) (r report.Report) {
 	return
 }
 
+func (v BootDeviceVar) Validate(c path.ContextPath) (r report.Report) {
+	if v.Format != nil && (*v.Format == "swap" || *v.Format == "none") {
+		r.AddOnError(c.Append("format"), common.ErrVarFormat)
+	}
+	return
+}
+
+func (l BootDeviceVolumeLuks) Validate(c path.ContextPath) (r report.Report) {
+	if util.IsTrue(l.Discard) || l.Threshold != nil {
+		if len(l.Tang) == 0 && !util.IsTrue(l.Tpm2) {
+			r.AddOnError(c, common.ErrNoLuksMethodSpecified)
+		}
+	}
+	return
+}
+
 func (m BootDeviceMirror) Validate(c path.ContextPath) (r report.Report) {
 	if len(m.Devices) == 1 {
 		r.AddOnError(c.Append("devices"), common.ErrTooFewMirrorDevices)
@@ -128,6 +182,61 @@ func (m BootDeviceMirror) Validate(c path.ContextPath) (r report.Report) {
 	return
 }
 
+// validateLayout checks the RAID level, spares, root size, and
+// additional partitions of the mirror.
+func (m BootDeviceMirror) validateLayout(c path.ContextPath) (r report.Report) {
+	if len(m.Devices) == 0 && (m.Level != nil || m.Spares != nil || m.RootSizeMiB != nil || len(m.Partitions) > 0) {
+		r.AddOnError(c.Append("devices"), common.ErrMirrorNoDevices)
+	}
+	minDevices, ok := mirrorLevelDevices[m.level()]
+	if !ok {
+		r.AddOnError(c.Append("level"), common.ErrMirrorLevel)
+	}
+	spares := 0
+	if m.Spares != nil {
+		spares = *m.Spares
+		if spares < 0 || (len(m.Devices) > 0 && spares >= len(m.Devices)) {
+			r.AddOnError(c.Append("spares"), common.ErrMirrorSpares)
+			spares = 0
+		}
+	}
+	// a single device is reported by Validate
+	if ok && len(m.Devices) > 1 && len(m.Devices)-spares < minDevices {
+		r.AddOnError(c.Append("devices"), common.ErrMirrorActiveDevices)
+	}
+	if m.RootSizeMiB != nil {
+		if *m.RootSizeMiB <= 0 {
+			r.AddOnError(c.Append("root_size_mib"), common.ErrMirrorRootSize)
+		} else if *m.RootSizeMiB < rootVarSizeMiB {
+			r.AddOnWarn(c.Append("root_size_mib"), common.ErrRootTooSmall)
+		}
+	}
+	labels := map[string]bool{}
+	for i, part := range m.Partitions {
+		if labels[part.Label] {
+			r.AddOnError(c.Append("partitions", i, "label"), common.ErrMirrorPartitionDuplicate)
+		}
+		labels[part.Label] = true
+		if i < len(m.Partitions)-1 && (part.SizeMiB == nil || *part.SizeMiB == 0) {
+			r.AddOnError(c.Append("partitions", i, "size_mib"), common.ErrMirrorPartitionFill)
+		}
+	}
+	return
+}
+
+func (p BootDeviceMirrorPartition) Validate(c path.ContextPath) (r report.Report) {
+	if !mirrorLabelRe.MatchString(p.Label) || reservedMirrorLabels[p.Label] {
+		r.AddOnError(c.Append("label"), common.ErrMirrorPartitionLabel)
+	}
+	if p.Format != nil && (*p.Format == "swap" || *p.Format == "none") {
+		r.AddOnError(c.Append("format"), common.ErrMirrorPartitionFormat)
+	}
+	if p.Path != nil && !allowedMountpoints.MatchString(*p.Path) {
+		r.AddOnError(c.Append("path"), common.ErrMirrorPartitionPath)
+	}
+	return
+}
+
 func (user GrubUser) Validate(c path.ContextPath) (r report.Report) {
 	if user.Name == "" {
 		r.AddOnError(c.Append("name"), common.ErrGrubUserNameNotSpecified)
@@ -26,6 +26,7 @@ import (
 	"github.com/coreos/ignition/v2/config/util"
 	"github.com/coreos/vcontext/path"
 	"github.com/coreos/vcontext/report"
+	"github.com/coreos/vcontext/validate"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -325,6 +326,202 @@ func TestValidateBootDevice(t *testing.T) {
 			common.ErrMirrorRequiresLayout,
 			path.New("yaml", "mirror"),
 		},
+		// /var partition on DASD
+		{
+			BootDevice{
+				Layout: util.StrToPtr("s390x-eckd"),
+				Luks: BootDeviceLuks{
+					Device: util.StrToPtr("/dev/dasda"),
+					Tpm2:   util.BoolToPtr(true),
+				},
+				Var: BootDeviceVar{
+					SizeMiB: util.IntToPtr(10240),
+				},
+			},
+			common.ErrVarNotSupport,
+			path.New("yaml", "var"),
+		},
+		// mirror with root size and additional partitions
+		{
+			BootDevice{
+				Layout: util.StrToPtr("x86_64"),
+				Mirror: BootDeviceMirror{
+					Devices:     []string{"/dev/vda", "/dev/vdb"},
+					RootSizeMiB: util.IntToPtr(16384),
+					Partitions: []BootDeviceMirrorPartition{
+						{
+							Label:   "data",
+							SizeMiB: util.IntToPtr(10240),
+							Path:    util.StrToPtr("/var/lib/data"),
+						},
+						{
+							Label: "scratch",
+						},
+					},
+				},
+				Var: BootDeviceVar{
+					SizeMiB: util.IntToPtr(10240),
+				},
+			},
+			nil,
+			path.New("yaml"),
+		},
+		// mirror partitions without mirror devices
+		{
+			BootDevice{
+				Mirror: BootDeviceMirror{
+					Partitions: []BootDeviceMirrorPartition{
+						{
+							Label: "data",
+						},
+					},
+				},
+			},
+			common.ErrMirrorNoDevices,
+			path.New("yaml", "mirror", "devices"),
+		},
+		// /var filling the disk before mirror partitions
+		{
+			BootDevice{
+				Layout: util.StrToPtr("x86_64"),
+				Mirror: BootDeviceMirror{
+					Devices: []string{"/dev/vda", "/dev/vdb"},
+					Partitions: []BootDeviceMirrorPartition{
+						{
+							Label: "data",
+						},
+					},
+				},
+				Var: BootDeviceVar{
+					Format: util.StrToPtr("xfs"),
+				},
+			},
+			common.ErrVarSizeRequired,
+			path.New("yaml", "var", "size_mib"),
+		},
+		// invalid root size
+		{
+			BootDevice{
+				Layout: util.StrToPtr("x86_64"),
+				Mirror: BootDeviceMirror{
+					Devices:     []string{"/dev/vda", "/dev/vdb"},
+					RootSizeMiB: util.IntToPtr(0),
+				},
+			},
+			common.ErrMirrorRootSize,
+			path.New("yaml", "mirror", "root_size_mib"),
+		},
+		// non-final partition filling the disk
+		{
+			BootDevice{
+				Layout: util.StrToPtr("x86_64"),
+				Mirror: BootDeviceMirror{
+					Devices: []string{"/dev/vda", "/dev/vdb"},
+					Partitions: []BootDeviceMirrorPartition{
+						{
+							Label: "data",
+						},
+						{
+							Label: "scratch",
+						},
+					},
+				},
+			},
+			common.ErrMirrorPartitionFill,
+			path.New("yaml", "mirror", "partitions", 0, "size_mib"),
+		},
+		// duplicate partition labels
+		{
+			BootDevice{
+				Layout: util.StrToPtr("x86_64"),
+				Mirror: BootDeviceMirror{
+					Devices: []string{"/dev/vda", "/dev/vdb"},
+					Partitions: []BootDeviceMirrorPartition{
+						{
+							Label:   "data",
+							SizeMiB: util.IntToPtr(10240),
+						},
+						{
+							Label: "data",
+						},
+					},
+				},
+			},
+			common.ErrMirrorPartitionDuplicate,
+			path.New("yaml", "mirror", "partitions", 1, "label"),
+		},
+		// RAID10 mirror with a spare
+		{
+			BootDevice{
+				Layout: util.StrToPtr("x86_64"),
+				Mirror: BootDeviceMirror{
+					Devices: []string{"/dev/vda", "/dev/vdb", "/dev/vdc", "/dev/vdd", "/dev/vde"},
+					Level:   util.StrToPtr("raid10"),
+					Spares:  util.IntToPtr(1),
+				},
+			},
+			nil,
+			path.New("yaml"),
+		},
+		// unsupported level
+		{
+			BootDevice{
+				Layout: util.StrToPtr("x86_64"),
+				Mirror: BootDeviceMirror{
+					Devices: []string{"/dev/vda", "/dev/vdb", "/dev/vdc"},
+					Level:   util.StrToPtr("raid5"),
+				},
+			},
+			common.ErrMirrorLevel,
+			path.New("yaml", "mirror", "level"),
+		},
+		// every device is a spare
+		{
+			BootDevice{
+				Layout: util.StrToPtr("x86_64"),
+				Mirror: BootDeviceMirror{
+					Devices: []string{"/dev/vda", "/dev/vdb"},
+					Spares:  util.IntToPtr(2),
+				},
+			},
+			common.ErrMirrorSpares,
+			path.New("yaml", "mirror", "spares"),
+		},
+		// too few active devices for RAID1
+		{
+			BootDevice{
+				Layout: util.StrToPtr("x86_64"),
+				Mirror: BootDeviceMirror{
+					Devices: []string{"/dev/vda", "/dev/vdb"},
+					Spares:  util.IntToPtr(1),
+				},
+			},
+			common.ErrMirrorActiveDevices,
+			path.New("yaml", "mirror", "devices"),
+		},
+		// too few active devices for RAID10
+		{
+			BootDevice{
+				Layout: util.StrToPtr("x86_64"),
+				Mirror: BootDeviceMirror{
+					Devices: []string{"/dev/vda", "/dev/vdb", "/dev/vdc", "/dev/vdd"},
+					Level:   util.StrToPtr("raid10"),
+					Spares:  util.IntToPtr(1),
+				},
+			},
+			common.ErrMirrorActiveDevices,
+			path.New("yaml", "mirror", "devices"),
+		},
+		// level without mirror devices
+		{
+			BootDevice{
+				Mirror: BootDeviceMirror{
+					Level: util.StrToPtr("raid10"),
+				},
+			},
+			common.ErrMirrorNoDevices,
+			path.New("yaml", "mirror", "devices"),
+		},
 	}
 
 	for i, test := range tests {
@@ -338,6 +535,134 @@ func TestValidateBootDevice(t *testing.T) {
 	}
 }
 
+func TestValidateBootDeviceVar(t *testing.T) {
+	tests := []struct {
+		in      BootDeviceVar
+		out     error
+		errPath path.ContextPath
+	}{
+		// complete config
+		{
+			BootDeviceVar{
+				Format: util.StrToPtr("ext4"),
+				Luks: BootDeviceVolumeLuks{
+					Discard: util.BoolToPtr(true),
+					Tpm2:    util.BoolToPtr(true),
+				},
+				SizeMiB: util.IntToPtr(10240),
+			},
+			nil,
+			path.New("yaml"),
+		},
+		// swap
+		{
+			BootDeviceVar{
+				Format: util.StrToPtr("swap"),
+			},
+			common.ErrVarFormat,
+			path.New("yaml", "format"),
+		},
+		// LUKS without a method
+		{
+			BootDeviceVar{
+				Luks: BootDeviceVolumeLuks{
+					Discard: util.BoolToPtr(true),
+				},
+			},
+			common.ErrNoLuksMethodSpecified,
+			path.New("yaml", "luks"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := validate.Validate(test.in, "yaml")
+			baseutil.VerifyReport(t, test.in, actual)
+			expected := report.Report{}
+			expected.AddOnError(test.errPath, test.out)
+			assert.Equal(t, expected, actual, "bad validation report")
+		})
+	}
+}
+
+func TestValidateBootDeviceMirrorPartition(t *testing.T) {
+	tests := []struct {
+		in      BootDeviceMirrorPartition
+		out     error
+		errPath path.ContextPath
+	}{
+		// complete config
+		{
+			BootDeviceMirrorPartition{
+				Format: util.StrToPtr("ext4"),
+				Label:  "data",
+				Luks: BootDeviceVolumeLuks{
+					Tpm2: util.BoolToPtr(true),
+				},
+				Path:    util.StrToPtr("/var/lib/data"),
+				SizeMiB: util.IntToPtr(10240),
+			},
+			nil,
+			path.New("yaml"),
+		},
+		// invalid label
+		{
+			BootDeviceMirrorPartition{
+				Label: "my data",
+			},
+			common.ErrMirrorPartitionLabel,
+			path.New("yaml", "label"),
+		},
+		// reserved label
+		{
+			BootDeviceMirrorPartition{
+				Label: "root",
+			},
+			common.ErrMirrorPartitionLabel,
+			path.New("yaml", "label"),
+		},
+		// swap
+		{
+			BootDeviceMirrorPartition{
+				Format: util.StrToPtr("swap"),
+				Label:  "data",
+			},
+			common.ErrMirrorPartitionFormat,
+			path.New("yaml", "format"),
+		},
+		// path outside /etc and /var
+		{
+			BootDeviceMirrorPartition{
+				Label: "data",
+				Path:  util.StrToPtr("/srv/data"),
+			},
+			common.ErrMirrorPartitionPath,
+			path.New("yaml", "path"),
+		},
+		// LUKS without a method
+		{
+			BootDeviceMirrorPartition{
+				Label: "data",
+				Luks: BootDeviceVolumeLuks{
+					Discard: util.BoolToPtr(true),
+				},
+			},
+			common.ErrNoLuksMethodSpecified,
+			path.New("yaml", "luks"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := validate.Validate(test.in, "yaml")
+			baseutil.VerifyReport(t, test.in, actual)
+			expected := report.Report{}
+			expected.AddOnError(test.errPath, test.out)
+			assert.Equal(t, expected, actual, "bad validation report")
+		})
+	}
+}
+
 func TestValidateGrubUser(t *testing.T) {
 	tests := []struct {
 		in      GrubUser
@@ -508,6 +833,102 @@ func TestValidateMountPoints(t *testing.T) {
 			out:     common.ErrMountPointForbidden,
 			errPath: path.New("yaml", "storage", "filesystems", 0, "path"),
 		},
+		// valid data device
+		{
+			in: Config{
+				Config: base.Config{
+					Storage: base.Storage{
+						DataDevices: []base.DataDevice{
+							{
+								Device: util.StrToPtr("/dev/vdb"),
+								Path:   util.StrToPtr("/var/lib/data"),
+							},
+						},
+					},
+				},
+			},
+		},
+		// invalid data device (path is /srv)
+		{
+			in: Config{
+				Config: base.Config{
+					Storage: base.Storage{
+						DataDevices: []base.DataDevice{
+							{
+								Device: util.StrToPtr("/dev/vdb"),
+								Path:   util.StrToPtr("/srv"),
+							},
+						},
+					},
+				},
+			},
+			out:     common.ErrDataDeviceMountPoint,
+			errPath: path.New("yaml", "storage", "data_devices", 0, "path"),
+		},
+		// invalid logical volume (path is /srv)
+		{
+			in: Config{
+				Config: base.Config{
+					Storage: base.Storage{
+						Lvm: []base.VolumeGroup{
+							{
+								Name:    "vg0",
+								Devices: []base.Device{"/dev/vdb"},
+								LogicalVolumes: []base.LogicalVolume{
+									{
+										Name:   "data",
+										Format: util.StrToPtr("xfs"),
+										Path:   util.StrToPtr("/srv"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			out:     common.ErrLvmMountPoint,
+			errPath: path.New("yaml", "storage", "lvm", 0, "logical_volumes", 0, "path"),
+		},
+		// invalid subvolume (path is /srv)
+		{
+			in: Config{
+				Config: base.Config{
+					Storage: base.Storage{
+						Filesystems: []base.Filesystem{
+							{
+								Device: "/dev/vdb",
+								Format: util.StrToPtr("btrfs"),
+								Subvolumes: []base.Subvolume{
+									{
+										Name: "srv",
+										Path: util.StrToPtr("/srv"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			out:     common.ErrSubvolumeMountPoint,
+			errPath: path.New("yaml", "storage", "filesystems", 0, "subvolumes", 0, "path"),
+		},
+		// invalid swap file (path is /swapfile)
+		{
+			in: Config{
+				Config: base.Config{
+					Storage: base.Storage{
+						Swap: base.Swap{
+							File: base.SwapFile{
+								Path: util.StrToPtr("/swapfile"),
+								Size: util.StrToPtr("1GiB"),
+							},
+						},
+					},
+				},
+			},
+			out:     common.ErrSwapFileMountPoint,
+			errPath: path.New("yaml", "storage", "swap", "file", "path"),
+		},
 	}
 	for i, test := range tests {
 		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
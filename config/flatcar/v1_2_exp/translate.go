@@ -15,8 +15,10 @@
 package v1_2_exp
 
 import (
+	base "github.com/coreos/butane/base/v0_8_exp"
 	"github.com/coreos/butane/config/common"
 	cutil "github.com/coreos/butane/config/util"
+	"github.com/coreos/butane/translate"
 
 	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
 	"github.com/coreos/vcontext/report"
@@ -26,6 +28,12 @@ var (
 	fieldFilters = cutil.NewFilters(types.Config{}, cutil.FilterMap{
 		"storage.luks.cex": common.ErrCexNotSupported,
 	})
+
+	// partitions and filesystems of the OS image
+	imageDevices = base.ImageDevices{
+		PartLabels: []string{"BIOS-BOOT", "EFI-SYSTEM", "OEM", "OEM-CONFIG", "ROOT", "USR-A", "USR-B"},
+		FsLabels:   []string{"EFI-SYSTEM", "OEM", "ROOT"},
+	}
 )
 
 // Return FieldFilters for this spec.
@@ -33,6 +41,19 @@ func (c Config) FieldFilters() *cutil.FieldFilters {
 	return &fieldFilters
 }
 
+// ToIgn3_7Unvalidated translates the config to an Ignition config.  It also
+// returns the set of translations it did so paths in the resultant config
+// can be tracked back to their source in the source config.  No config
+// validation is performed on input or output.
+func (c Config) ToIgn3_7Unvalidated(options common.TranslateOptions) (types.Config, translate.TranslationSet, report.Report) {
+	ret, ts, r := c.Config.ToIgn3_7Unvalidated(options)
+	if r.IsFatal() {
+		return types.Config{}, translate.TranslationSet{}, r
+	}
+	r.Merge(base.CheckStorageReferences(ret, imageDevices))
+	return ret, ts, r
+}
+
 // ToIgn3_5 translates the config to an Ignition config.  It returns a
 // report of any errors or warnings in the source and resultant config.  If
 // the report has fatal errors or it encounters other problems translating,
@@ -52,7 +52,7 @@ func TestTranslation(t *testing.T) {
 							},
 							{
 								Name:   "data-bis",
-								Device: util.StrToPtr("/dev/disk/by-partlabel/USR-B-bis"),
+								Device: util.StrToPtr("/dev/disk/by-partlabel/OEM-CONFIG"),
 								Clevis: base.Clevis{Tpm2: util.BoolToPtr(true)},
 							},
 						},
@@ -61,6 +61,24 @@ func TestTranslation(t *testing.T) {
 			},
 			[]entry{}, // Clevis support was added in 1_2 and we therefore expect no errors.
 		},
+		// partition label not in the Flatcar image
+		{
+			Config{
+				Config: base.Config{
+					Storage: base.Storage{
+						Luks: []base.Luks{
+							{
+								Name:   "data",
+								Device: util.StrToPtr("/dev/disk/by-partlabel/USR-C"),
+							},
+						},
+					},
+				},
+			},
+			[]entry{
+				{report.Warn, common.ErrUnknownPartLabel, path.New("yaml", "storage", "luks", 0, "device")},
+			},
+		},
 	}
 
 	for i, test := range tests {
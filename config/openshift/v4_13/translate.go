@@ -15,7 +15,6 @@
 package v4_13
 
 import (
-	"net/url"
 	"strings"
 
 	"github.com/coreos/butane/config/common"
@@ -56,8 +55,7 @@ const (
 )
 
 var (
-	// See also validateRHCOSSupport() and validateMCOSupport()
-	fieldFilters = cutil.NewFilters(result.MachineConfig{}, cutil.FilterMap{
+	fieldFilters = cutil.NewValueFilters(result.MachineConfig{}, cutil.FilterMap{
 		// IMMUTABLE
 		"spec.config.passwd.groups": common.ErrGroupSupport,
 		// TRIPWIRE
@@ -94,6 +92,23 @@ var (
 		// link support in the MCO, consider what should happen if
 		// the user specifies a storage.tree that includes symlinks.
 		"spec.config.storage.links": common.ErrLinkSupport,
+	}, cutil.ValueFilterMap{
+		// TRIPWIRE
+		"spec.config.passwd.users.name": {
+			{Allowed: cutil.OneOf("core"), Err: common.ErrUserNameSupport},
+		},
+		// FORBIDDEN
+		"spec.config.storage.files.contents.source": {
+			{Allowed: cutil.URLScheme("data"), Err: common.ErrFileSchemeSupport},
+		},
+		// UNPARSABLE
+		"spec.config.storage.files.mode": {
+			{Allowed: cutil.InRange(0, 0777, "%#o"), Err: common.ErrFileSpecialModeSupport},
+		},
+		"spec.config.storage.filesystems.format": {
+			// we don't ship mkfs.btrfs
+			{Allowed: cutil.NoneOf("btrfs"), Err: common.ErrBtrfsSupport},
+		},
 	})
 )
 
@@ -151,10 +166,6 @@ func (c Config) ToMachineConfig4_13Unvalidated(options common.TranslateOptions)
 	// apply FIPS options to LUKS volumes
 	ts.Merge(addLuksFipsOptions(&mc))
 
-	// finally, check the fully desugared config for RHCOS and MCO support
-	r.Merge(validateRHCOSSupport(mc))
-	r.Merge(validateMCOSupport(mc))
-
 	return mc, ts, r
 }
 
@@ -175,6 +186,10 @@ func (c Config) ToIgn3_2Unvalidated(options common.TranslateOptions) (types.Conf
 	mc, ts, r := c.ToMachineConfig4_13Unvalidated(options)
 	cfg := mc.Spec.Config
 
+	// fieldFilters only applies to MachineConfigs, but we still want
+	// to reject values the MCO can't handle
+	r.Merge(cutil.TranslateReportPaths(fieldFilters.VerifyValues(mc), ts))
+
 	// report warnings if there are any non-empty fields in Spec (other
 	// than the Ignition config itself) that we're ignoring
 	mc.Spec.Config = types.Config{}
@@ -236,56 +251,3 @@ OUTER:
 	}
 	return ts
 }
-
-// Error on fields that are rejected by RHCOS.
-//
-// Some of these fields may have been generated by sugar (e.g.
-// boot_device.luks), so we work in JSON (output) space and then translate
-// paths back to YAML (input) space.  That's also the reason we do these
-// checks after translation, rather than during validation.
-func validateRHCOSSupport(mc result.MachineConfig) report.Report {
-	var r report.Report
-	for i, fs := range mc.Spec.Config.Storage.Filesystems {
-		if fs.Format != nil && *fs.Format == "btrfs" {
-			// we don't ship mkfs.btrfs
-			r.AddOnError(path.New("json", "spec", "config", "storage", "filesystems", i, "format"), common.ErrBtrfsSupport)
-		}
-	}
-	return r
-}
-
-// Error on fields that are rejected outright by the MCO, or that are
-// unsupported by the MCO and we want to discourage.
-//
-// https://github.com/openshift/machine-config-operator/blob/d6dabadeca05/MachineConfigDaemon.md#supported-vs-unsupported-ignition-config-changes
-//
-// Some of these fields may have been generated by sugar (e.g. storage.trees),
-// so we work in JSON (output) space and then translate paths back to YAML
-// (input) space.  That's also the reason we do these checks after
-// translation, rather than during validation.
-func validateMCOSupport(mc result.MachineConfig) report.Report {
-	// See also fieldFilters at the top of this file.
-
-	var r report.Report
-	for i, file := range mc.Spec.Config.Storage.Files {
-		if file.Contents.Source != nil {
-			fileSource, err := url.Parse(*file.Contents.Source)
-			// parse errors will be caught by normal config validation
-			if err == nil && fileSource.Scheme != "data" {
-				// FORBIDDEN
-				r.AddOnError(path.New("json", "spec", "config", "storage", "files", i, "contents", "source"), common.ErrFileSchemeSupport)
-			}
-		}
-		if file.Mode != nil && *file.Mode & ^0777 != 0 {
-			// UNPARSABLE
-			r.AddOnError(path.New("json", "spec", "config", "storage", "files", i, "mode"), common.ErrFileSpecialModeSupport)
-		}
-	}
-	for i, user := range mc.Spec.Config.Passwd.Users {
-		if user.Name != "core" {
-			// TRIPWIRE
-			r.AddOnError(path.New("json", "spec", "config", "passwd", "users", i, "name"), common.ErrUserNameSupport)
-		}
-	}
-	return r
-}
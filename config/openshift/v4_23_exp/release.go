@@ -0,0 +1,83 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package v4_23_exp
+
+// Facts about the OpenShift release targeted by this spec version.  Update
+// these when copying the package for a new release.
+
+// RHCOS extensions supported by the Machine Config Operator
+var supportedExtensions = map[string]bool{
+	"ipsec":                true,
+	"kerberos":             true,
+	"kernel-devel":         true,
+	"sandboxed-containers": true,
+	"sysstat":              true,
+	"two-node-ha":          true,
+	"usbguard":             true,
+	"wasm":                 true,
+}
+
+// kernel arguments set by the bootloader configuration or the Machine
+// Config Operator itself, without the "=value" suffix
+var managedKernelArguments = map[string]bool{
+	"BOOT_IMAGE":                       true,
+	"boot":                             true,
+	"fips":                             true,
+	"ignition.platform.id":             true,
+	"ostree":                           true,
+	"root":                             true,
+	"rw":                               true,
+	"systemd.unified_cgroup_hierarchy": true,
+}
+
+// kernel arguments that conflict with the realtime kernel, without the
+// "=value" suffix.  The realtime kernel is always fully preemptible.
+var realtimeConflictingKernelArguments = map[string]bool{
+	"preempt": true,
+}
+
+// files generated by the Machine Config Operator or managed by the kubelet.
+// Paths ending in "/" match everything beneath them.
+var ownedPaths = []string{
+	"/etc/containers/policy.json",
+	"/etc/containers/registries.conf",
+	"/etc/containers/storage.conf",
+	"/etc/crio/crio.conf",
+	"/etc/crio/crio.conf.d/00-default",
+	"/etc/kubernetes/kubeconfig",
+	"/etc/kubernetes/kubelet-ca.crt",
+	"/etc/kubernetes/kubelet.conf",
+	"/etc/kubernetes/manifests/",
+	"/etc/machine-config-daemon/",
+	"/var/lib/kubelet/config.json",
+}
+
+type disruption int
+
+const (
+	// the node is drained and rebooted
+	disruptionReboot disruption = iota
+	// CRI-O is reloaded without draining the node
+	disruptionReload
+)
+
+// how the Machine Config Operator applies changes to files that users
+// might expect to be reloaded without a reboot, or vice versa.  Paths
+// ending in "/" match everything beneath them; the longest match wins.
+var pathDisruptions = map[string]disruption{
+	"/etc/containers/registries.conf.d/": disruptionReboot,
+	"/etc/containers/registries.d/":      disruptionReload,
+	"/etc/crio/crio.conf.d/":             disruptionReboot,
+}
@@ -21,6 +21,12 @@ import (
 const (
 	MC_API_VERSION = "machineconfiguration.openshift.io/v1"
 	MC_KIND        = "MachineConfig"
+
+	KUBELET_CONFIG_KIND           = "KubeletConfig"
+	CONTAINER_RUNTIME_CONFIG_KIND = "ContainerRuntimeConfig"
+
+	// label carried by the MachineConfigPool for each role
+	POOL_LABEL_PREFIX = "pools.operator.machineconfiguration.openshift.io/"
 )
 
 // We round-trip through JSON because Ignition uses `json` struct tags,
@@ -35,14 +41,61 @@ type MachineConfig struct {
 }
 
 type Metadata struct {
-	Name   string            `json:"name"`
-	Labels map[string]string `json:"labels,omitempty"`
+	Name        string            `json:"name"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 type Spec struct {
-	Config          types.Config `json:"config"`
-	KernelArguments []string     `json:"kernelArguments,omitempty"`
-	Extensions      []string     `json:"extensions,omitempty"`
-	FIPS            *bool        `json:"fips,omitempty"`
-	KernelType      *string      `json:"kernelType,omitempty"`
+	Config                         types.Config `json:"config"`
+	KernelArguments                []string     `json:"kernelArguments,omitempty"`
+	Extensions                     []string     `json:"extensions,omitempty"`
+	FIPS                           *bool        `json:"fips,omitempty"`
+	KernelType                     *string      `json:"kernelType,omitempty"`
+	OSImageURL                     *string      `json:"osImageURL,omitempty"`
+	BaseOSExtensionsContainerImage *string      `json:"baseOSExtensionsContainerImage,omitempty"`
+}
+
+type KubeletConfig struct {
+	ApiVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   Metadata          `json:"metadata"`
+	Spec       KubeletConfigSpec `json:"spec"`
+}
+
+type KubeletConfigSpec struct {
+	MachineConfigPoolSelector LabelSelector         `json:"machineConfigPoolSelector"`
+	AutoSizingReserved        *bool                 `json:"autoSizingReserved,omitempty"`
+	LogLevel                  *int                  `json:"logLevel,omitempty"`
+	KubeletConfig             *KubeletConfiguration `json:"kubeletConfig,omitempty"`
+}
+
+// Subset of the upstream KubeletConfiguration supported by the MCO
+type KubeletConfiguration struct {
+	MaxPods        *int              `json:"maxPods,omitempty"`
+	PodPidsLimit   *int              `json:"podPidsLimit,omitempty"`
+	SystemReserved map[string]string `json:"systemReserved,omitempty"`
+}
+
+type ContainerRuntimeConfig struct {
+	ApiVersion string                     `json:"apiVersion"`
+	Kind       string                     `json:"kind"`
+	Metadata   Metadata                   `json:"metadata"`
+	Spec       ContainerRuntimeConfigSpec `json:"spec"`
+}
+
+type ContainerRuntimeConfigSpec struct {
+	MachineConfigPoolSelector LabelSelector                 `json:"machineConfigPoolSelector"`
+	ContainerRuntimeConfig    ContainerRuntimeConfiguration `json:"containerRuntimeConfig"`
+}
+
+type ContainerRuntimeConfiguration struct {
+	DefaultRuntime *string `json:"defaultRuntime,omitempty"`
+	LogLevel       *string `json:"logLevel,omitempty"`
+	LogSizeMax     *string `json:"logSizeMax,omitempty"`
+	OverlaySize    *string `json:"overlaySize,omitempty"`
+}
+
+type LabelSelector struct {
+	MatchLabels map[string]string `json:"matchLabels"`
 }
@@ -29,11 +29,37 @@ type Config struct {
 type Metadata struct {
 	Name   string            `yaml:"name"`
 	Labels map[string]string `yaml:"labels,omitempty"`
+	Roles  []string          `yaml:"roles"`
 }
 
 type OpenShift struct {
-	KernelArguments []string `yaml:"kernel_arguments"`
-	Extensions      []string `yaml:"extensions"`
-	FIPS            *bool    `yaml:"fips"`
-	KernelType      *string  `yaml:"kernel_type"`
+	KernelArguments  []string          `yaml:"kernel_arguments"`
+	Extensions       []string          `yaml:"extensions"`
+	FIPS             *bool             `yaml:"fips"`
+	KernelType       *string           `yaml:"kernel_type"`
+	OSImageURL       *string           `yaml:"os_image_url"`
+	ExtensionsImage  *string           `yaml:"extensions_image"`
+	Kubelet          *Kubelet          `yaml:"kubelet"`
+	ContainerRuntime *ContainerRuntime `yaml:"container_runtime"`
+}
+
+type Kubelet struct {
+	AutoSizingReserved *bool           `yaml:"auto_sizing_reserved"`
+	LogLevel           *int            `yaml:"log_level"`
+	MaxPods            *int            `yaml:"max_pods"`
+	PodPidsLimit       *int            `yaml:"pod_pids_limit"`
+	SystemReserved     *SystemReserved `yaml:"system_reserved"`
+}
+
+type SystemReserved struct {
+	CPU              *string `yaml:"cpu"`
+	Memory           *string `yaml:"memory"`
+	EphemeralStorage *string `yaml:"ephemeral_storage"`
+}
+
+type ContainerRuntime struct {
+	DefaultRuntime *string `yaml:"default_runtime"`
+	LogLevel       *string `yaml:"log_level"`
+	LogSizeMax     *string `yaml:"log_size_max"`
+	OverlaySize    *string `yaml:"overlay_size"`
 }
@@ -15,7 +15,8 @@
 package v4_23_exp
 
 import (
-	"net/url"
+	"fmt"
+	"strings"
 
 	"github.com/coreos/butane/config/common"
 	"github.com/coreos/butane/config/openshift/v4_23_exp/result"
@@ -51,8 +52,8 @@ import (
 // these.
 
 var (
-	// See also validateRHCOSSupport() and validateMCOSupport()
-	fieldFilters = cutil.NewFilters(result.MachineConfig{}, cutil.FilterMap{
+	// See also validateMCOPaths()
+	fieldFilters = cutil.NewValueFilters(result.MachineConfig{}, cutil.FilterMap{
 		// UNPARSABLE, REDUNDANT
 		"spec.config.kernelArguments": common.ErrKernelArgumentSupport,
 		// IMMUTABLE
@@ -91,6 +92,25 @@ var (
 		// link support in the MCO, consider what should happen if
 		// the user specifies a storage.tree that includes symlinks.
 		"spec.config.storage.links": common.ErrLinkSupport,
+	}, cutil.ValueFilterMap{
+		// TRIPWIRE
+		"spec.config.passwd.users.name": {
+			{Allowed: cutil.OneOf("core"), Err: common.ErrUserNameSupport},
+		},
+		// FORBIDDEN
+		"spec.config.storage.files.contents.source": {
+			{Allowed: cutil.URLScheme("data"), Err: common.ErrFileSchemeSupport},
+		},
+		// UNPARSABLE
+		"spec.config.storage.files.mode": {
+			{Allowed: cutil.InRange(0, 0777, "%#o"), Err: common.ErrFileSpecialModeSupport},
+		},
+		"spec.config.storage.filesystems.format": {
+			// we don't ship mkfs.btrfs
+			{Allowed: cutil.NoneOf("btrfs"), Err: common.ErrBtrfsSupport},
+			// UNPARSABLE
+			{Allowed: cutil.NoneOf("none"), Err: common.ErrFilesystemNoneSupport},
+		},
 	})
 )
 
@@ -143,12 +163,13 @@ func (c Config) ToMachineConfig4_23Unvalidated(options common.TranslateOptions)
 	translate.MergeP(tr, ts2, &r2, "fips", &from.FIPS, &to.FIPS)
 	translate.MergeP2(tr, ts2, &r2, "kernel_arguments", &from.KernelArguments, "kernelArguments", &to.KernelArguments)
 	translate.MergeP2(tr, ts2, &r2, "kernel_type", &from.KernelType, "kernelType", &to.KernelType)
+	translate.MergeP2(tr, ts2, &r2, "os_image_url", &from.OSImageURL, "osImageURL", &to.OSImageURL)
+	translate.MergeP2(tr, ts2, &r2, "extensions_image", &from.ExtensionsImage, "baseOSExtensionsContainerImage", &to.BaseOSExtensionsContainerImage)
 	ts.MergeP2("openshift", "spec", ts2)
 	r.Merge(r2)
 
 	// finally, check the fully desugared config for RHCOS and MCO support
-	r.Merge(validateRHCOSSupport(mc))
-	r.Merge(validateMCOSupport(mc))
+	r.Merge(validateMCOPaths(mc))
 
 	return mc, ts, r
 }
@@ -162,6 +183,216 @@ func (c Config) ToMachineConfig4_23(options common.TranslateOptions) (result.Mac
 	return cfg.(result.MachineConfig), r, err
 }
 
+// ToMachineConfigs4_23 translates the config to a list of MachineConfigs,
+// one for each role in metadata.roles, named following the
+// 99-<role>-<name> convention.  If no roles are specified, the list
+// contains the single MachineConfig returned by ToMachineConfig4_23.  It
+// returns a report of any errors or warnings in the source and resultant
+// config.  If the report has fatal errors or it encounters other problems
+// translating, an error is returned.
+func (c Config) ToMachineConfigs4_23(options common.TranslateOptions) ([]result.MachineConfig, report.Report, error) {
+	mc, r, err := c.ToMachineConfig4_23(options)
+	if err != nil {
+		return nil, r, err
+	}
+	if len(c.Metadata.Roles) == 0 {
+		return []result.MachineConfig{mc}, r, nil
+	}
+	var mcs []result.MachineConfig
+	for _, role := range c.Metadata.Roles {
+		roleMC := mc
+		roleMC.Metadata.Name = fmt.Sprintf("99-%s-%s", role, c.Metadata.Name)
+		roleMC.Metadata.Labels = make(map[string]string)
+		for k, v := range mc.Metadata.Labels {
+			roleMC.Metadata.Labels[k] = v
+		}
+		roleMC.Metadata.Labels[ROLE_LABEL_KEY] = role
+		mcs = append(mcs, roleMC)
+	}
+	return mcs, r, nil
+}
+
+// ToManifests4_23 translates the config to the objects to apply to the
+// cluster: the MachineConfigs returned by ToMachineConfigs4_23, followed by
+// a KubeletConfig and a ContainerRuntimeConfig for each role if
+// openshift.kubelet or openshift.container_runtime is specified.  It
+// returns a report of any errors or warnings in the source and resultant
+// config.  If the report has fatal errors or it encounters other problems
+// translating, an error is returned.
+//
+// If there's more than one object, the paths of the translations returned
+// in options.Translations begin with the index of the object.
+func (c Config) ToManifests4_23(options common.TranslateOptions) ([]any, report.Report, error) {
+	// the translations of the MachineConfig are shared by every role
+	var mcTranslations translate.TranslationSet
+	callerTranslations := options.Translations
+	options.Translations = &mcTranslations
+	mcs, r, err := c.ToMachineConfigs4_23(options)
+	if err != nil {
+		return nil, r, err
+	}
+
+	var objects []any
+	var objectTranslations []translate.TranslationSet
+	for i, mc := range mcs {
+		ts := translate.NewTranslationSet("yaml", "json")
+		ts.Merge(mcTranslations)
+		if len(c.Metadata.Roles) > 0 {
+			role := path.New("yaml", "metadata", "roles", i)
+			ts.AddTranslation(role, path.New("json", "metadata", "labels", ROLE_LABEL_KEY))
+			if len(c.Metadata.Labels) == 0 {
+				ts.AddTranslation(role, path.New("json", "metadata", "labels"))
+			}
+		}
+		objects = append(objects, mc)
+		objectTranslations = append(objectTranslations, ts)
+	}
+	for i, mc := range mcs {
+		role := path.New("yaml", "metadata", "labels", ROLE_LABEL_KEY)
+		if len(c.Metadata.Roles) > 0 {
+			role = path.New("yaml", "metadata", "roles", i)
+		}
+		selector := result.LabelSelector{
+			MatchLabels: map[string]string{
+				result.POOL_LABEL_PREFIX + mc.Metadata.Labels[ROLE_LABEL_KEY]: "",
+			},
+		}
+		if k := c.OpenShift.Kubelet; k != nil {
+			kc, ts := translateKubelet(*k, mc.Metadata.Name+"-kubelet", selector)
+			addObjectTranslations(ts, role, selector)
+			objects = append(objects, kc)
+			objectTranslations = append(objectTranslations, ts)
+		}
+		if cr := c.OpenShift.ContainerRuntime; cr != nil {
+			crc, ts, r2 := translateContainerRuntime(*cr, mc.Metadata.Name+"-container-runtime", selector)
+			r.Merge(r2)
+			addObjectTranslations(ts, role, selector)
+			objects = append(objects, crc)
+			objectTranslations = append(objectTranslations, ts)
+		}
+	}
+
+	// check the generated objects and report problems against the
+	// source
+	for i, object := range objects {
+		r.Merge(cutil.TranslateReportPaths(validateObject(object), objectTranslations[i]))
+	}
+	if r.IsFatal() {
+		return nil, r, common.ErrInvalidGeneratedConfig
+	}
+
+	if callerTranslations != nil {
+		if len(objects) == 1 {
+			*callerTranslations = objectTranslations[0]
+		} else {
+			ts := translate.NewTranslationSet("yaml", "json")
+			for i, ots := range objectTranslations {
+				ts.Merge(ots.PrefixPaths(path.New("yaml"), path.New("json", i)))
+			}
+			*callerTranslations = ts
+		}
+	}
+	return objects, r, nil
+}
+
+// addObjectTranslations adds the translations for the metadata and pool
+// selector of a generated KubeletConfig or ContainerRuntimeConfig, where
+// role is the source of the role of the pool.
+func addObjectTranslations(ts translate.TranslationSet, role path.ContextPath, selector result.LabelSelector) {
+	ts.AddTranslation(path.New("yaml", "version"), path.New("json", "apiVersion"))
+	ts.AddTranslation(path.New("yaml", "version"), path.New("json", "kind"))
+	ts.AddTranslation(path.New("yaml", "metadata"), path.New("json", "metadata"))
+	ts.AddTranslation(path.New("yaml", "metadata", "name"), path.New("json", "metadata", "name"))
+	ts.AddTranslation(role, path.New("json", "spec", "machineConfigPoolSelector"))
+	ts.AddTranslation(role, path.New("json", "spec", "machineConfigPoolSelector", "matchLabels"))
+	for label := range selector.MatchLabels {
+		ts.AddTranslation(role, path.New("json", "spec", "machineConfigPoolSelector", "matchLabels", label))
+	}
+}
+
+func translateKubelet(from Kubelet, name string, selector result.LabelSelector) (result.KubeletConfig, translate.TranslationSet) {
+	kc := result.KubeletConfig{
+		ApiVersion: result.MC_API_VERSION,
+		Kind:       result.KUBELET_CONFIG_KIND,
+		Metadata: result.Metadata{
+			Name: name,
+		},
+		Spec: result.KubeletConfigSpec{
+			MachineConfigPoolSelector: selector,
+			AutoSizingReserved:        from.AutoSizingReserved,
+			LogLevel:                  from.LogLevel,
+		},
+	}
+	ts := translate.NewTranslationSet("yaml", "json")
+	yamlPath := path.New("yaml", "openshift", "kubelet")
+	ts.AddTranslation(yamlPath, path.New("json", "spec"))
+	if from.AutoSizingReserved != nil {
+		ts.AddTranslation(yamlPath.Append("auto_sizing_reserved"), path.New("json", "spec", "autoSizingReserved"))
+	}
+	if from.LogLevel != nil {
+		ts.AddTranslation(yamlPath.Append("log_level"), path.New("json", "spec", "logLevel"))
+	}
+	if from.MaxPods != nil || from.PodPidsLimit != nil || from.SystemReserved != nil {
+		kc.Spec.KubeletConfig = &result.KubeletConfiguration{
+			MaxPods:      from.MaxPods,
+			PodPidsLimit: from.PodPidsLimit,
+		}
+		ts.AddTranslation(yamlPath, path.New("json", "spec", "kubeletConfig"))
+		if from.MaxPods != nil {
+			ts.AddTranslation(yamlPath.Append("max_pods"), path.New("json", "spec", "kubeletConfig", "maxPods"))
+		}
+		if from.PodPidsLimit != nil {
+			ts.AddTranslation(yamlPath.Append("pod_pids_limit"), path.New("json", "spec", "kubeletConfig", "podPidsLimit"))
+		}
+	}
+	if sr := from.SystemReserved; sr != nil {
+		reserved := make(map[string]string)
+		srPath := yamlPath.Append("system_reserved")
+		ts.AddTranslation(srPath, path.New("json", "spec", "kubeletConfig", "systemReserved"))
+		for _, field := range []struct {
+			key   string
+			from  string
+			value *string
+		}{
+			{"cpu", "cpu", sr.CPU},
+			{"memory", "memory", sr.Memory},
+			{"ephemeral-storage", "ephemeral_storage", sr.EphemeralStorage},
+		} {
+			if field.value != nil {
+				reserved[field.key] = *field.value
+				ts.AddTranslation(srPath.Append(field.from), path.New("json", "spec", "kubeletConfig", "systemReserved", field.key))
+			}
+		}
+		kc.Spec.KubeletConfig.SystemReserved = reserved
+	}
+	return kc, ts
+}
+
+func translateContainerRuntime(from ContainerRuntime, name string, selector result.LabelSelector) (result.ContainerRuntimeConfig, translate.TranslationSet, report.Report) {
+	crc := result.ContainerRuntimeConfig{
+		ApiVersion: result.MC_API_VERSION,
+		Kind:       result.CONTAINER_RUNTIME_CONFIG_KIND,
+		Metadata: result.Metadata{
+			Name: name,
+		},
+		Spec: result.ContainerRuntimeConfigSpec{
+			MachineConfigPoolSelector: selector,
+		},
+	}
+	tr := translate.NewTranslator("yaml", "json", common.TranslateOptions{})
+	to := &crc.Spec.ContainerRuntimeConfig
+	ts := translate.NewTranslationSet("yaml", "json")
+	var r report.Report
+	translate.MergeP2(tr, ts, &r, "default_runtime", &from.DefaultRuntime, "defaultRuntime", &to.DefaultRuntime)
+	translate.MergeP2(tr, ts, &r, "log_level", &from.LogLevel, "logLevel", &to.LogLevel)
+	translate.MergeP2(tr, ts, &r, "log_size_max", &from.LogSizeMax, "logSizeMax", &to.LogSizeMax)
+	translate.MergeP2(tr, ts, &r, "overlay_size", &from.OverlaySize, "overlaySize", &to.OverlaySize)
+	ts = ts.PrefixPaths(path.New("yaml", "openshift", "container_runtime"), path.New("json", "spec", "containerRuntimeConfig"))
+	ts.AddTranslation(path.New("yaml", "openshift", "container_runtime"), path.New("json", "spec"))
+	ts.AddTranslation(path.New("yaml", "openshift", "container_runtime"), path.New("json", "spec", "containerRuntimeConfig"))
+	return crc, ts, translate.PrefixReport(translate.PrefixReport(r, "container_runtime"), "openshift")
+}
+
 // ToIgn3_7Unvalidated translates the config to an Ignition config.  It also
 // returns the set of translations it did so paths in the resultant config
 // can be tracked back to their source in the source config.  No config
@@ -170,6 +401,10 @@ func (c Config) ToIgn3_7Unvalidated(options common.TranslateOptions) (types.Conf
 	mc, ts, r := c.ToMachineConfig4_23Unvalidated(options)
 	cfg := mc.Spec.Config
 
+	// fieldFilters only applies to MachineConfigs, but we still want
+	// to reject values the MCO can't handle
+	r.Merge(cutil.TranslateReportPaths(fieldFilters.VerifyValues(mc), ts))
+
 	// report warnings if there are any non-empty fields in Spec (other
 	// than the Ignition config itself) that we're ignoring
 	mc.Spec.Config = types.Config{}
@@ -177,6 +412,13 @@ func (c Config) ToIgn3_7Unvalidated(options common.TranslateOptions) (types.Conf
 	// translate from json space into yaml space, since the caller won't
 	// have enough info to do it
 	r.Merge(cutil.TranslateReportPaths(warnings, ts))
+	// the KubeletConfig and ContainerRuntimeConfig are separate objects
+	if c.OpenShift.Kubelet != nil {
+		r.AddOnWarn(path.New("yaml", "openshift", "kubelet"), common.ErrFieldElided)
+	}
+	if c.OpenShift.ContainerRuntime != nil {
+		r.AddOnWarn(path.New("yaml", "openshift", "container_runtime"), common.ErrFieldElided)
+	}
 
 	ts = ts.Descend(path.New("json", "spec", "config"))
 	return cfg, ts, r
@@ -191,74 +433,56 @@ func (c Config) ToIgn3_7(options common.TranslateOptions) (types.Config, report.
 	return cfg.(types.Config), r, err
 }
 
-// ToConfigBytes translates from a v4.23 Butane config to a v4.23 MachineConfig or a v3.7.0-experimental Ignition config. It returns a report of any errors or
-// warnings in the source and resultant config. If the report has fatal errors or it encounters other problems
+// ToConfigBytes translates from a v4.23 Butane config to a v4.23 MachineConfig or a v3.7.0-experimental Ignition config. If there are
+// several objects, such as a MachineConfig per role or a KubeletConfig, they are emitted as a multi-document YAML stream.
+// It returns a report of any errors or warnings in the source and resultant config. If the report has fatal errors or it encounters other problems
 // translating, an error is returned.
 func ToConfigBytes(input []byte, options common.TranslateBytesOptions) ([]byte, report.Report, error) {
 	if options.Raw {
 		return cutil.TranslateBytes(input, &Config{}, "ToIgn3_7", options)
 	} else {
-		return cutil.TranslateBytesYAML(input, &Config{}, "ToMachineConfig4_23", options)
-	}
-}
-
-// Error on fields that are rejected by RHCOS.
-//
-// Some of these fields may have been generated by sugar (e.g.
-// boot_device.luks), so we work in JSON (output) space and then translate
-// paths back to YAML (input) space.  That's also the reason we do these
-// checks after translation, rather than during validation.
-func validateRHCOSSupport(mc result.MachineConfig) report.Report {
-	var r report.Report
-	for i, fs := range mc.Spec.Config.Storage.Filesystems {
-		if fs.Format != nil && *fs.Format == "btrfs" {
-			// we don't ship mkfs.btrfs
-			r.AddOnError(path.New("json", "spec", "config", "storage", "filesystems", i, "format"), common.ErrBtrfsSupport)
-		}
+		return cutil.TranslateBytesYAML(input, &Config{}, "ToManifests4_23", options)
 	}
-	return r
 }
 
-// Error on fields that are rejected outright by the MCO, or that are
-// unsupported by the MCO and we want to discourage.
-//
-// https://github.com/openshift/machine-config-operator/blob/d6dabadeca05/MachineConfigDaemon.md#supported-vs-unsupported-ignition-config-changes
+// Warn about files that the MCO or kubelet own, and about files whose
+// changes reboot the pool or only reload CRI-O.
 //
-// Some of these fields may have been generated by sugar (e.g. storage.trees),
-// so we work in JSON (output) space and then translate paths back to YAML
-// (input) space.  That's also the reason we do these checks after
-// translation, rather than during validation.
-func validateMCOSupport(mc result.MachineConfig) report.Report {
-	// See also fieldFilters at the top of this file.
-
+// Files may have been generated by sugar (e.g. storage.trees), so we work
+// in JSON (output) space and then translate paths back to YAML (input)
+// space.
+func validateMCOPaths(mc result.MachineConfig) report.Report {
 	var r report.Report
-	for i, fs := range mc.Spec.Config.Storage.Filesystems {
-		if fs.Format != nil && *fs.Format == "none" {
-			// UNPARSABLE
-			r.AddOnError(path.New("json", "spec", "config", "storage", "filesystems", i, "format"), common.ErrFilesystemNoneSupport)
-		}
+	var disruptive []string
+	for p := range pathDisruptions {
+		disruptive = append(disruptive, p)
 	}
 	for i, file := range mc.Spec.Config.Storage.Files {
-		if file.Contents.Source != nil {
-			fileSource, err := url.Parse(*file.Contents.Source)
-			// parse errors will be caught by normal config validation
-			if err == nil && fileSource.Scheme != "data" {
-				// FORBIDDEN
-				r.AddOnError(path.New("json", "spec", "config", "storage", "files", i, "contents", "source"), common.ErrFileSchemeSupport)
+		c := path.New("json", "spec", "config", "storage", "files", i, "path")
+		if _, ok := matchPath(file.Path, ownedPaths); ok {
+			r.AddOnWarn(c, common.ErrPathOwned)
+		} else if p, ok := matchPath(file.Path, disruptive); ok {
+			switch pathDisruptions[p] {
+			case disruptionReboot:
+				r.AddOnWarn(c, common.ErrPathReboot)
+			case disruptionReload:
+				r.AddOnWarn(c, common.ErrPathReload)
 			}
 		}
-		if file.Mode != nil && *file.Mode & ^0777 != 0 {
-			// UNPARSABLE
-			r.AddOnError(path.New("json", "spec", "config", "storage", "files", i, "mode"), common.ErrFileSpecialModeSupport)
-		}
 	}
-	for i, user := range mc.Spec.Config.Passwd.Users {
-		if user.Name != "core" {
-			// TRIPWIRE
-			r.AddOnError(path.New("json", "spec", "config", "passwd", "users", i, "name"), common.ErrUserNameSupport)
+	return r
+}
+
+// matchPath returns the longest entry in paths matching p, where entries
+// ending in "/" match everything beneath them.
+func matchPath(p string, paths []string) (string, bool) {
+	var match string
+	for _, candidate := range paths {
+		if (p == candidate || (strings.HasSuffix(candidate, "/") && strings.HasPrefix(p, candidate))) && len(candidate) > len(match) {
+			match = candidate
 		}
 	}
-	return r
+	return match, match != ""
 }
 
 // fcos config generates a user.cfg file using append; however, OpenShift config
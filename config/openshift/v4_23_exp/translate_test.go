@@ -16,6 +16,7 @@ package v4_23_exp
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	baseutil "github.com/coreos/butane/base/util"
@@ -44,6 +45,12 @@ func TestElidedFieldWarning(t *testing.T) {
 			KernelArguments: []string{"a", "b"},
 			FIPS:            util.BoolToPtr(true),
 			KernelType:      util.StrToPtr("realtime"),
+			Kubelet: &Kubelet{
+				MaxPods: util.IntToPtr(500),
+			},
+			ContainerRuntime: &ContainerRuntime{
+				LogLevel: util.StrToPtr("debug"),
+			},
 		},
 	}
 
@@ -51,6 +58,8 @@ func TestElidedFieldWarning(t *testing.T) {
 	expected.AddOnWarn(path.New("yaml", "openshift", "kernel_arguments"), common.ErrFieldElided)
 	expected.AddOnWarn(path.New("yaml", "openshift", "fips"), common.ErrFieldElided)
 	expected.AddOnWarn(path.New("yaml", "openshift", "kernel_type"), common.ErrFieldElided)
+	expected.AddOnWarn(path.New("yaml", "openshift", "kubelet"), common.ErrFieldElided)
+	expected.AddOnWarn(path.New("yaml", "openshift", "container_runtime"), common.ErrFieldElided)
 
 	_, _, r := in.ToIgn3_7Unvalidated(common.TranslateOptions{})
 	assert.Equal(t, expected, r, "report mismatch")
@@ -98,6 +107,50 @@ func TestTranslateConfig(t *testing.T) {
 				{From: path.New("yaml", "version"), To: path.New("json", "spec", "config", "ignition", "version")},
 			},
 		},
+		// layered image
+		{
+			Config{
+				Metadata: Metadata{
+					Name: "z",
+					Labels: map[string]string{
+						ROLE_LABEL_KEY: "z",
+					},
+				},
+				OpenShift: OpenShift{
+					OSImageURL:      util.StrToPtr("quay.io/example/os@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+					ExtensionsImage: util.StrToPtr("quay.io/example/extensions@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+				},
+			},
+			result.MachineConfig{
+				ApiVersion: result.MC_API_VERSION,
+				Kind:       result.MC_KIND,
+				Metadata: result.Metadata{
+					Name: "z",
+					Labels: map[string]string{
+						ROLE_LABEL_KEY: "z",
+					},
+				},
+				Spec: result.Spec{
+					Config: types.Config{
+						Ignition: types.Ignition{
+							Version: "3.7.0-experimental",
+						},
+					},
+					OSImageURL:                     util.StrToPtr("quay.io/example/os@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+					BaseOSExtensionsContainerImage: util.StrToPtr("quay.io/example/extensions@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+				},
+			},
+			[]translate.Translation{
+				{From: path.New("yaml", "version"), To: path.New("json", "apiVersion")},
+				{From: path.New("yaml", "version"), To: path.New("json", "kind")},
+				{From: path.New("yaml", "version"), To: path.New("json", "spec")},
+				{From: path.New("yaml"), To: path.New("json", "spec", "config")},
+				{From: path.New("yaml", "ignition"), To: path.New("json", "spec", "config", "ignition")},
+				{From: path.New("yaml", "version"), To: path.New("json", "spec", "config", "ignition", "version")},
+				{From: path.New("yaml", "openshift", "os_image_url"), To: path.New("json", "spec", "osImageURL")},
+				{From: path.New("yaml", "openshift", "extensions_image"), To: path.New("json", "spec", "baseOSExtensionsContainerImage")},
+			},
+		},
 		// Test Grub config
 		{
 			Config{
@@ -196,6 +249,154 @@ func TestTranslateConfig(t *testing.T) {
 	}
 }
 
+// TestTranslateRoles tests generating a MachineConfig for each role.
+func TestTranslateRoles(t *testing.T) {
+	in := Config{
+		Metadata: Metadata{
+			Name: "chrony",
+			Labels: map[string]string{
+				"a": "b",
+			},
+			Roles: []string{"master", "worker"},
+		},
+	}
+	mc := func(role string) result.MachineConfig {
+		return result.MachineConfig{
+			ApiVersion: result.MC_API_VERSION,
+			Kind:       result.MC_KIND,
+			Metadata: result.Metadata{
+				Name: "99-" + role + "-chrony",
+				Labels: map[string]string{
+					"a":            "b",
+					ROLE_LABEL_KEY: role,
+				},
+			},
+			Spec: result.Spec{
+				Config: types.Config{
+					Ignition: types.Ignition{
+						Version: "3.7.0-experimental",
+					},
+				},
+			},
+		}
+	}
+
+	actual, r, err := in.ToMachineConfigs4_23(common.TranslateOptions{})
+	assert.NoError(t, err, "translation failed")
+	assert.Equal(t, report.Report{}, r, "non-empty report")
+	assert.Equal(t, []result.MachineConfig{mc("master"), mc("worker")}, actual, "translation mismatch")
+
+	// without roles, the role label is used as-is
+	in.Metadata.Name = "99-worker-chrony"
+	in.Metadata.Labels[ROLE_LABEL_KEY] = "worker"
+	in.Metadata.Roles = nil
+	actual, _, err = in.ToMachineConfigs4_23(common.TranslateOptions{})
+	assert.NoError(t, err, "translation failed")
+	assert.Equal(t, []result.MachineConfig{mc("worker")}, actual, "translation mismatch")
+}
+
+// TestTranslateManifests tests generating KubeletConfigs and
+// ContainerRuntimeConfigs alongside the MachineConfigs.
+func TestTranslateManifests(t *testing.T) {
+	in := Config{
+		Metadata: Metadata{
+			Name:  "tuning",
+			Roles: []string{"master", "worker"},
+		},
+		OpenShift: OpenShift{
+			Kubelet: &Kubelet{
+				AutoSizingReserved: util.BoolToPtr(true),
+				MaxPods:            util.IntToPtr(500),
+				SystemReserved: &SystemReserved{
+					EphemeralStorage: util.StrToPtr("1Gi"),
+				},
+			},
+			ContainerRuntime: &ContainerRuntime{
+				LogSizeMax: util.StrToPtr("50Mi"),
+			},
+		},
+	}
+	selector := func(role string) result.LabelSelector {
+		return result.LabelSelector{
+			MatchLabels: map[string]string{
+				result.POOL_LABEL_PREFIX + role: "",
+			},
+		}
+	}
+	kubelet := func(role string) result.KubeletConfig {
+		return result.KubeletConfig{
+			ApiVersion: result.MC_API_VERSION,
+			Kind:       result.KUBELET_CONFIG_KIND,
+			Metadata: result.Metadata{
+				Name: "99-" + role + "-tuning-kubelet",
+			},
+			Spec: result.KubeletConfigSpec{
+				MachineConfigPoolSelector: selector(role),
+				AutoSizingReserved:        util.BoolToPtr(true),
+				KubeletConfig: &result.KubeletConfiguration{
+					MaxPods: util.IntToPtr(500),
+					SystemReserved: map[string]string{
+						"ephemeral-storage": "1Gi",
+					},
+				},
+			},
+		}
+	}
+	containerRuntime := func(role string) result.ContainerRuntimeConfig {
+		return result.ContainerRuntimeConfig{
+			ApiVersion: result.MC_API_VERSION,
+			Kind:       result.CONTAINER_RUNTIME_CONFIG_KIND,
+			Metadata: result.Metadata{
+				Name: "99-" + role + "-tuning-container-runtime",
+			},
+			Spec: result.ContainerRuntimeConfigSpec{
+				MachineConfigPoolSelector: selector(role),
+				ContainerRuntimeConfig: result.ContainerRuntimeConfiguration{
+					LogSizeMax: util.StrToPtr("50Mi"),
+				},
+			},
+		}
+	}
+
+	var ts translate.TranslationSet
+	actual, r, err := in.ToManifests4_23(common.TranslateOptions{Translations: &ts})
+	assert.NoError(t, err, "translation failed")
+	assert.Equal(t, report.Report{}, r, "non-empty report")
+	if assert.Len(t, actual, 6, "wrong number of objects") {
+		assert.Equal(t, "99-master-tuning", actual[0].(result.MachineConfig).Metadata.Name, "bad MachineConfig")
+		assert.Equal(t, "99-worker-tuning", actual[1].(result.MachineConfig).Metadata.Name, "bad MachineConfig")
+		assert.Equal(t, []any{
+			kubelet("master"),
+			containerRuntime("master"),
+			kubelet("worker"),
+			containerRuntime("worker"),
+		}, actual[2:], "translation mismatch")
+		for i, object := range actual {
+			assert.NoError(t, ts.Descend(path.New("json", i)).DebugVerifyCoverage(object), "incomplete TranslationSet coverage for object %d", i)
+		}
+	}
+	// translations are prefixed with the object index
+	for _, tr := range []translate.Translation{
+		{From: path.New("yaml", "metadata", "roles", 1), To: path.New("json", 1, "metadata", "labels", ROLE_LABEL_KEY)},
+		{From: path.New("yaml", "openshift", "kubelet", "max_pods"), To: path.New("json", 4, "spec", "kubeletConfig", "maxPods")},
+		{From: path.New("yaml", "openshift", "kubelet", "system_reserved", "ephemeral_storage"), To: path.New("json", 4, "spec", "kubeletConfig", "systemReserved", "ephemeral-storage")},
+		{From: path.New("yaml", "metadata", "roles", 1), To: path.New("json", 4, "spec", "machineConfigPoolSelector", "matchLabels", result.POOL_LABEL_PREFIX+"worker")},
+		{From: path.New("yaml", "openshift", "container_runtime", "log_size_max"), To: path.New("json", 5, "spec", "containerRuntimeConfig", "logSizeMax")},
+	} {
+		assert.Equal(t, tr, ts.Set[tr.To.String()], "bad translation")
+	}
+
+	// errors in generated objects are reported against the source
+	in.Metadata.Name = strings.Repeat("a", 240)
+	in.Metadata.Roles = []string{"worker"}
+	in.OpenShift.ContainerRuntime = nil
+	_, r, err = in.ToManifests4_23(common.TranslateOptions{})
+	assert.ErrorIs(t, err, common.ErrInvalidGeneratedConfig, "bad error")
+	var expected report.Report
+	expected.AddOnError(path.New("yaml", "metadata", "name"), common.ErrObjectName)
+	assert.Equal(t, expected, r, "bad report")
+}
+
 // Test post-translation validation of RHCOS/MCO support for Ignition config fields.
 func TestValidateSupport(t *testing.T) {
 	type entry struct {
@@ -379,13 +580,6 @@ func TestValidateSupport(t *testing.T) {
 				},
 			},
 			[]entry{
-				// code
-				{report.Error, common.ErrBtrfsSupport, path.New("yaml", "storage", "filesystems", 0, "format")},
-				{report.Error, common.ErrFilesystemNoneSupport, path.New("yaml", "storage", "filesystems", 1, "format")},
-				{report.Error, common.ErrFileSchemeSupport, path.New("yaml", "storage", "files", 2, "contents", "source")},
-				{report.Error, common.ErrFileSpecialModeSupport, path.New("yaml", "storage", "files", 2, "mode")},
-				{report.Error, common.ErrUserNameSupport, path.New("yaml", "passwd", "users", 1, "name")},
-				// filters
 				{report.Error, common.ErrKernelArgumentSupport, path.New("yaml", "kernel_arguments")},
 				{report.Error, common.ErrGroupSupport, path.New("yaml", "passwd", "groups")},
 				{report.Error, common.ErrUserFieldSupport, path.New("yaml", "passwd", "users", 0, "gecos")},
@@ -399,12 +593,69 @@ func TestValidateSupport(t *testing.T) {
 				{report.Error, common.ErrUserFieldSupport, path.New("yaml", "passwd", "users", 0, "should_exist")},
 				{report.Error, common.ErrUserFieldSupport, path.New("yaml", "passwd", "users", 0, "system")},
 				{report.Error, common.ErrUserFieldSupport, path.New("yaml", "passwd", "users", 0, "uid")},
+				{report.Error, common.ErrUserNameSupport, path.New("yaml", "passwd", "users", 1, "name")},
 				{report.Error, common.ErrDirectorySupport, path.New("yaml", "storage", "directories")},
 				{report.Error, common.ErrFileAppendSupport, path.New("yaml", "storage", "files", 1, "append")},
+				{report.Error, common.ErrFileSchemeSupport, path.New("yaml", "storage", "files", 2, "contents", "source")},
+				{report.Error, common.ErrFileSpecialModeSupport, path.New("yaml", "storage", "files", 2, "mode")},
 				{report.Error, common.ErrFileHeaderSupport, path.New("yaml", "storage", "files", 3, "contents", "http_headers")},
+				{report.Error, common.ErrBtrfsSupport, path.New("yaml", "storage", "filesystems", 0, "format")},
+				{report.Error, common.ErrFilesystemNoneSupport, path.New("yaml", "storage", "filesystems", 1, "format")},
 				{report.Error, common.ErrLinkSupport, path.New("yaml", "storage", "links")},
 			},
 		},
+		// owned paths, and paths that reboot or reload
+		{
+			Config{
+				Metadata: Metadata{
+					Name: "z",
+					Labels: map[string]string{
+						ROLE_LABEL_KEY: "z",
+					},
+				},
+				Config: fcos.Config{
+					Config: base.Config{
+						Storage: base.Storage{
+							Files: []base.File{
+								{
+									Path: "/etc/kubernetes/kubelet.conf",
+								},
+								{
+									Path: "/etc/kubernetes/manifests/pod.yaml",
+								},
+								{
+									Path: "/etc/crio/crio.conf.d/00-default",
+								},
+								{
+									Path: "/etc/crio/crio.conf.d/10-custom",
+								},
+								{
+									Path: "/etc/containers/registries.conf",
+								},
+								{
+									Path: "/etc/containers/registries.conf.d/mirror.conf",
+								},
+								{
+									Path: "/etc/containers/registries.d/example.yaml",
+								},
+								{
+									Path: "/etc/motd",
+								},
+							},
+						},
+					},
+				},
+			},
+			[]entry{
+				{report.Warn, common.ErrPathOwned, path.New("yaml", "storage", "files", 0, "path")},
+				{report.Warn, common.ErrPathOwned, path.New("yaml", "storage", "files", 1, "path")},
+				{report.Warn, common.ErrPathOwned, path.New("yaml", "storage", "files", 2, "path")},
+				{report.Warn, common.ErrPathReboot, path.New("yaml", "storage", "files", 3, "path")},
+				{report.Warn, common.ErrPathOwned, path.New("yaml", "storage", "files", 4, "path")},
+				{report.Warn, common.ErrPathReboot, path.New("yaml", "storage", "files", 5, "path")},
+				{report.Warn, common.ErrPathReload, path.New("yaml", "storage", "files", 6, "path")},
+			},
+		},
 	}
 
 	for i, test := range tests {
@@ -15,20 +15,45 @@
 package v4_23_exp
 
 import (
+	"regexp"
 	"slices"
+	"strings"
 
 	"github.com/coreos/butane/config/common"
+	"github.com/coreos/butane/config/openshift/v4_23_exp/result"
 	"github.com/coreos/ignition/v2/config/util"
 
 	"github.com/coreos/vcontext/path"
 	"github.com/coreos/vcontext/report"
 )
 
+var (
+	// Kubernetes resource quantity, without exponent notation
+	quantityRe = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?(m|k|M|G|T|P|E|Ki|Mi|Gi|Ti|Pi|Ei)?$`)
+	// container image reference pinned by digest
+	digestRe = regexp.MustCompile(`^[^\s@]+@sha256:[0-9a-f]{64}$`)
+	// Kubernetes object name, a DNS subdomain
+	objectNameRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
 func (m Metadata) Validate(c path.ContextPath) (r report.Report) {
 	if m.Name == "" {
 		r.AddOnError(c.Append("name"), common.ErrNameRequired)
 	}
-	if m.Labels[ROLE_LABEL_KEY] == "" {
+	if len(m.Roles) > 0 {
+		if _, ok := m.Labels[ROLE_LABEL_KEY]; ok {
+			r.AddOnError(c.Append("labels", ROLE_LABEL_KEY), common.ErrRolesWithRoleLabel)
+		}
+		seen := make(map[string]bool)
+		for i, role := range m.Roles {
+			if role == "" {
+				r.AddOnError(c.Append("roles", i), common.ErrRoleRequired)
+			} else if seen[role] {
+				r.AddOnError(c.Append("roles", i), common.ErrDuplicateRole)
+			}
+			seen[role] = true
+		}
+	} else if m.Labels[ROLE_LABEL_KEY] == "" {
 		r.AddOnError(c.Append("labels"), common.ErrRoleRequired)
 	}
 	return
@@ -42,9 +67,101 @@ func (os OpenShift) Validate(c path.ContextPath) (r report.Report) {
 			r.AddOnError(c.Append("kernel_type"), common.ErrInvalidKernelType)
 		}
 	}
+	for i, extension := range os.Extensions {
+		if !supportedExtensions[extension] {
+			r.AddOnWarn(c.Append("extensions", i), common.ErrUnknownExtension)
+		}
+	}
+	realtime := os.KernelType != nil && *os.KernelType == "realtime"
+	seen := make(map[string]bool)
+	for i, arg := range os.KernelArguments {
+		key, _, _ := strings.Cut(arg, "=")
+		if seen[arg] {
+			r.AddOnWarn(c.Append("kernel_arguments", i), common.ErrDuplicateKernelArgument)
+		}
+		seen[arg] = true
+		if managedKernelArguments[key] {
+			r.AddOnWarn(c.Append("kernel_arguments", i), common.ErrKernelArgumentManaged)
+		}
+		if realtime && realtimeConflictingKernelArguments[key] {
+			r.AddOnError(c.Append("kernel_arguments", i), common.ErrKernelArgumentRealtime)
+		}
+	}
+	// on-cluster layering requires images to be pinned by digest
+	if os.OSImageURL != nil && !digestRe.MatchString(*os.OSImageURL) {
+		r.AddOnError(c.Append("os_image_url"), common.ErrImageNotPinned)
+	}
+	if os.ExtensionsImage != nil && !digestRe.MatchString(*os.ExtensionsImage) {
+		r.AddOnError(c.Append("extensions_image"), common.ErrImageNotPinned)
+	}
+	return
+}
+
+func (k Kubelet) Validate(c path.ContextPath) (r report.Report) {
+	if k.LogLevel != nil && (*k.LogLevel < 0 || *k.LogLevel > 10) {
+		r.AddOnError(c.Append("log_level"), common.ErrInvalidKubeletLogLevel)
+	}
+	if k.MaxPods != nil && *k.MaxPods <= 0 {
+		r.AddOnError(c.Append("max_pods"), common.ErrNotPositive)
+	}
+	if k.PodPidsLimit != nil && *k.PodPidsLimit <= 0 {
+		r.AddOnError(c.Append("pod_pids_limit"), common.ErrNotPositive)
+	}
+	return
+}
+
+func (sr SystemReserved) Validate(c path.ContextPath) (r report.Report) {
+	validateQuantity(&r, c.Append("cpu"), sr.CPU)
+	validateQuantity(&r, c.Append("memory"), sr.Memory)
+	validateQuantity(&r, c.Append("ephemeral_storage"), sr.EphemeralStorage)
 	return
 }
 
+func (cr ContainerRuntime) Validate(c path.ContextPath) (r report.Report) {
+	if cr.DefaultRuntime != nil {
+		switch *cr.DefaultRuntime {
+		case "runc", "crun":
+		default:
+			r.AddOnError(c.Append("default_runtime"), common.ErrInvalidDefaultRuntime)
+		}
+	}
+	if cr.LogLevel != nil {
+		switch *cr.LogLevel {
+		case "trace", "debug", "info", "warn", "error", "fatal", "panic":
+		default:
+			r.AddOnError(c.Append("log_level"), common.ErrInvalidCrioLogLevel)
+		}
+	}
+	validateQuantity(&r, c.Append("log_size_max"), cr.LogSizeMax)
+	validateQuantity(&r, c.Append("overlay_size"), cr.OverlaySize)
+	return
+}
+
+// validateObject checks a generated MachineConfig, KubeletConfig, or
+// ContainerRuntimeConfig.  The Ignition config of a MachineConfig is
+// validated separately.  Paths are in JSON (output) space.
+func validateObject(object any) (r report.Report) {
+	var name string
+	switch o := object.(type) {
+	case result.MachineConfig:
+		name = o.Metadata.Name
+	case result.KubeletConfig:
+		name = o.Metadata.Name
+	case result.ContainerRuntimeConfig:
+		name = o.Metadata.Name
+	}
+	if len(name) > 253 || !objectNameRe.MatchString(name) {
+		r.AddOnError(path.New("json", "metadata", "name"), common.ErrObjectName)
+	}
+	return
+}
+
+func validateQuantity(r *report.Report, c path.ContextPath, quantity *string) {
+	if quantity != nil && !quantityRe.MatchString(*quantity) {
+		r.AddOnError(c, common.ErrInvalidQuantity)
+	}
+}
+
 // Validate that we have the required kernel argument pointing to the key file
 // if we have CEX support enabled. We only do this in the openshift spec as
 // this is implemented differently in the fcos one.
@@ -63,6 +180,17 @@ func (conf Config) Validate(c path.ContextPath) (r report.Report) {
 	if cex && !slices.Contains(conf.OpenShift.KernelArguments, "rd.luks.key=/etc/luks/cex.key") {
 		r.AddOnError(c.Append("openshift", "kernel_arguments"), common.ErrMissingKernelArgumentCex)
 	}
+	// the MCO doesn't know about volumes created outside Ignition
+	if len(conf.Storage.Lvm) > 0 {
+		r.AddOnError(c.Append("storage", "lvm"), common.ErrLvmSupport)
+	}
+	swap := conf.Storage.Swap
+	if swap.File.Path != nil || swap.File.Size != nil {
+		r.AddOnError(c.Append("storage", "swap", "file"), common.ErrSwapSupport)
+	}
+	if swap.Zram.Algorithm != nil || swap.Zram.Size != nil {
+		r.AddOnError(c.Append("storage", "swap", "zram"), common.ErrSwapSupport)
+	}
 
 	return
 }
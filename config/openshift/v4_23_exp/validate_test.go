@@ -65,6 +65,45 @@ func TestValidateMetadata(t *testing.T) {
 			common.ErrRoleRequired,
 			path.New("yaml", "labels"),
 		},
+		// roles
+		{
+			Metadata{
+				Name:  "n",
+				Roles: []string{"master", "worker"},
+			},
+			nil,
+			path.New("yaml"),
+		},
+		// roles and role label
+		{
+			Metadata{
+				Name: "n",
+				Labels: map[string]string{
+					ROLE_LABEL_KEY: "worker",
+				},
+				Roles: []string{"worker"},
+			},
+			common.ErrRolesWithRoleLabel,
+			path.New("yaml", "labels", ROLE_LABEL_KEY),
+		},
+		// empty role
+		{
+			Metadata{
+				Name:  "n",
+				Roles: []string{""},
+			},
+			common.ErrRoleRequired,
+			path.New("yaml", "roles", 0),
+		},
+		// duplicate role
+		{
+			Metadata{
+				Name:  "n",
+				Roles: []string{"worker", "worker"},
+			},
+			common.ErrDuplicateRole,
+			path.New("yaml", "roles", 1),
+		},
 	}
 
 	for i, test := range tests {
@@ -98,6 +137,239 @@ func TestValidateOpenShift(t *testing.T) {
 			common.ErrInvalidKernelType,
 			path.New("yaml", "kernel_type"),
 		},
+		// pinned images
+		{
+			OpenShift{
+				OSImageURL:      util.StrToPtr("quay.io/example/os@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+				ExtensionsImage: util.StrToPtr("quay.io/example/extensions@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+			},
+			nil,
+			path.New("yaml"),
+		},
+		// image pinned by tag
+		{
+			OpenShift{
+				OSImageURL: util.StrToPtr("quay.io/example/os:latest"),
+			},
+			common.ErrImageNotPinned,
+			path.New("yaml", "os_image_url"),
+		},
+		// image with truncated digest
+		{
+			OpenShift{
+				ExtensionsImage: util.StrToPtr("quay.io/example/extensions@sha256:0123"),
+			},
+			common.ErrImageNotPinned,
+			path.New("yaml", "extensions_image"),
+		},
+		// kernel argument conflicting with the realtime kernel
+		{
+			OpenShift{
+				KernelType:      util.StrToPtr("realtime"),
+				KernelArguments: []string{"isolcpus=1-3", "preempt=voluntary"},
+			},
+			common.ErrKernelArgumentRealtime,
+			path.New("yaml", "kernel_arguments", 1),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := test.in.Validate(path.New("yaml"))
+			baseutil.VerifyReport(t, test.in, actual)
+			expected := report.Report{}
+			expected.AddOnError(test.errPath, test.out)
+			assert.Equal(t, expected, actual, "bad report")
+		})
+	}
+}
+
+func TestValidateOpenShiftWarnings(t *testing.T) {
+	tests := []struct {
+		in  OpenShift
+		out report.Report
+	}{
+		// known extensions and unmanaged kernel arguments
+		{
+			OpenShift{
+				Extensions:      []string{"usbguard", "kernel-devel"},
+				KernelArguments: []string{"preempt=voluntary", "console=ttyS0", "console=tty0"},
+			},
+			report.Report{},
+		},
+		// unknown extension, duplicate and managed kernel arguments
+		{
+			OpenShift{
+				Extensions:      []string{"usbgaurd"},
+				KernelArguments: []string{"loglevel=7", "loglevel=7", "ostree=/ostree/boot.1", "rw"},
+			},
+			report.Report{
+				Entries: []report.Entry{
+					{
+						Kind:    report.Warn,
+						Message: common.ErrUnknownExtension.Error(),
+						Context: path.New("yaml", "extensions", 0),
+					},
+					{
+						Kind:    report.Warn,
+						Message: common.ErrDuplicateKernelArgument.Error(),
+						Context: path.New("yaml", "kernel_arguments", 1),
+					},
+					{
+						Kind:    report.Warn,
+						Message: common.ErrKernelArgumentManaged.Error(),
+						Context: path.New("yaml", "kernel_arguments", 2),
+					},
+					{
+						Kind:    report.Warn,
+						Message: common.ErrKernelArgumentManaged.Error(),
+						Context: path.New("yaml", "kernel_arguments", 3),
+					},
+				},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := test.in.Validate(path.New("yaml"))
+			baseutil.VerifyReport(t, test.in, actual)
+			assert.Equal(t, test.out, actual, "bad report")
+		})
+	}
+}
+
+func TestValidateKubelet(t *testing.T) {
+	tests := []struct {
+		in      Kubelet
+		out     error
+		errPath path.ContextPath
+	}{
+		// valid
+		{
+			Kubelet{
+				LogLevel:     util.IntToPtr(4),
+				MaxPods:      util.IntToPtr(500),
+				PodPidsLimit: util.IntToPtr(4096),
+			},
+			nil,
+			path.New("yaml"),
+		},
+		// bad log level
+		{
+			Kubelet{
+				LogLevel: util.IntToPtr(11),
+			},
+			common.ErrInvalidKubeletLogLevel,
+			path.New("yaml", "log_level"),
+		},
+		// bad max pods
+		{
+			Kubelet{
+				MaxPods: util.IntToPtr(0),
+			},
+			common.ErrNotPositive,
+			path.New("yaml", "max_pods"),
+		},
+		// bad pids limit
+		{
+			Kubelet{
+				PodPidsLimit: util.IntToPtr(-1),
+			},
+			common.ErrNotPositive,
+			path.New("yaml", "pod_pids_limit"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := test.in.Validate(path.New("yaml"))
+			baseutil.VerifyReport(t, test.in, actual)
+			expected := report.Report{}
+			expected.AddOnError(test.errPath, test.out)
+			assert.Equal(t, expected, actual, "bad report")
+		})
+	}
+}
+
+func TestValidateSystemReserved(t *testing.T) {
+	tests := []struct {
+		in      SystemReserved
+		out     error
+		errPath path.ContextPath
+	}{
+		// valid
+		{
+			SystemReserved{
+				CPU:              util.StrToPtr("500m"),
+				Memory:           util.StrToPtr("1.5Gi"),
+				EphemeralStorage: util.StrToPtr("1000000"),
+			},
+			nil,
+			path.New("yaml"),
+		},
+		// bad quantity
+		{
+			SystemReserved{
+				Memory: util.StrToPtr("1 GiB"),
+			},
+			common.ErrInvalidQuantity,
+			path.New("yaml", "memory"),
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("validate %d", i), func(t *testing.T) {
+			actual := test.in.Validate(path.New("yaml"))
+			baseutil.VerifyReport(t, test.in, actual)
+			expected := report.Report{}
+			expected.AddOnError(test.errPath, test.out)
+			assert.Equal(t, expected, actual, "bad report")
+		})
+	}
+}
+
+func TestValidateContainerRuntime(t *testing.T) {
+	tests := []struct {
+		in      ContainerRuntime
+		out     error
+		errPath path.ContextPath
+	}{
+		// valid
+		{
+			ContainerRuntime{
+				DefaultRuntime: util.StrToPtr("crun"),
+				LogLevel:       util.StrToPtr("debug"),
+				LogSizeMax:     util.StrToPtr("50Mi"),
+				OverlaySize:    util.StrToPtr("10G"),
+			},
+			nil,
+			path.New("yaml"),
+		},
+		// bad runtime
+		{
+			ContainerRuntime{
+				DefaultRuntime: util.StrToPtr("kata"),
+			},
+			common.ErrInvalidDefaultRuntime,
+			path.New("yaml", "default_runtime"),
+		},
+		// bad log level
+		{
+			ContainerRuntime{
+				LogLevel: util.StrToPtr("verbose"),
+			},
+			common.ErrInvalidCrioLogLevel,
+			path.New("yaml", "log_level"),
+		},
+		// bad quantity
+		{
+			ContainerRuntime{
+				OverlaySize: util.StrToPtr("big"),
+			},
+			common.ErrInvalidQuantity,
+			path.New("yaml", "overlay_size"),
+		},
 	}
 
 	for i, test := range tests {
@@ -230,6 +502,68 @@ func TestValidateConfig(t *testing.T) {
 			common.ErrMissingKernelArgumentCex,
 			path.New("yaml", "openshift", "kernel_arguments"),
 		},
+		// LVM
+		{
+			Config{
+				Config: fcos.Config{
+					Config: base.Config{
+						Storage: base.Storage{
+							Lvm: []base.VolumeGroup{
+								{
+									Name:    "vg0",
+									Devices: []base.Device{"/dev/vdb"},
+									LogicalVolumes: []base.LogicalVolume{
+										{
+											Name:   "data",
+											Format: util.StrToPtr("xfs"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			common.ErrLvmSupport,
+			path.New("yaml", "storage", "lvm"),
+		},
+		// swap file
+		{
+			Config{
+				Config: fcos.Config{
+					Config: base.Config{
+						Storage: base.Storage{
+							Swap: base.Swap{
+								File: base.SwapFile{
+									Path: util.StrToPtr("/var/swapfile"),
+									Size: util.StrToPtr("4GiB"),
+								},
+							},
+						},
+					},
+				},
+			},
+			common.ErrSwapSupport,
+			path.New("yaml", "storage", "swap", "file"),
+		},
+		// zram swap
+		{
+			Config{
+				Config: fcos.Config{
+					Config: base.Config{
+						Storage: base.Storage{
+							Swap: base.Swap{
+								Zram: base.SwapZram{
+									Size: util.StrToPtr("50%"),
+								},
+							},
+						},
+					},
+				},
+			},
+			common.ErrSwapSupport,
+			path.New("yaml", "storage", "swap", "zram"),
+		},
 	}
 
 	for i, test := range tests {
@@ -0,0 +1,344 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/coreos/butane/config/common"
+	cutil "github.com/coreos/butane/config/util"
+	"github.com/coreos/butane/translate"
+
+	v3_0 "github.com/coreos/ignition/v2/config/v3_0/types"
+	v3_1 "github.com/coreos/ignition/v2/config/v3_1/types"
+	v3_2 "github.com/coreos/ignition/v2/config/v3_2/types"
+	v3_3 "github.com/coreos/ignition/v2/config/v3_3/types"
+	v3_4 "github.com/coreos/ignition/v2/config/v3_4/types"
+	v3_5 "github.com/coreos/ignition/v2/config/v3_5/types"
+	v3_6 "github.com/coreos/ignition/v2/config/v3_6/types"
+	v3_7_exp "github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	ignvalidate "github.com/coreos/ignition/v2/config/validate"
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	"github.com/coreos/vcontext/validate"
+	vyaml "github.com/coreos/vcontext/yaml"
+)
+
+const (
+	// translator plugins are executables named with this prefix
+	// followed by the variant
+	PluginPrefix = "butane-translator-"
+
+	// version of the plugin protocol described by PluginRequest and
+	// PluginResponse
+	PluginProtocolVersion = 1
+)
+
+// PluginRequest is written as JSON to the standard input of a translator
+// plugin.
+type PluginRequest struct {
+	Protocol int           `json:"protocol"`
+	Config   string        `json:"config"`
+	Options  PluginOptions `json:"options"`
+}
+
+type PluginOptions struct {
+	FilesDir                  string `json:"files_dir,omitempty"`
+	NoResourceAutoCompression bool   `json:"no_resource_auto_compression,omitempty"`
+	NoCompressUnder           int    `json:"no_compress_under,omitempty"`
+	Flatten                   bool   `json:"flatten,omitempty"`
+	Pretty                    bool   `json:"pretty,omitempty"`
+	Raw                       bool   `json:"raw,omitempty"`
+	Canonical                 bool   `json:"canonical,omitempty"`
+}
+
+// PluginResponse is read as JSON from the standard output of a translator
+// plugin.  A plugin that can't translate the config, for example because
+// it doesn't support its version, sets Error or reports an error.
+type PluginResponse struct {
+	Output string `json:"output"`
+	// "ignition" (the default) for an Ignition config, or
+	// "machineconfig" for a stream of Kubernetes objects whose
+	// MachineConfigs contain Ignition configs
+	OutputType   string              `json:"output_type,omitempty"`
+	Report       []PluginReportEntry `json:"report,omitempty"`
+	Translations []PluginTranslation `json:"translations,omitempty"`
+	Error        string              `json:"error,omitempty"`
+}
+
+type PluginReportEntry struct {
+	// "error", "warning", or "info"
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	// "yaml" for paths in the config or "json" for paths in the
+	// output; defaults to "yaml"
+	Tag  string `json:"tag,omitempty"`
+	Path []any  `json:"path"`
+}
+
+// PluginTranslation records that the field at From in the config
+// generated the field at To in the output.
+type PluginTranslation struct {
+	From []any `json:"from"`
+	To   []any `json:"to"`
+}
+
+// ignitionConfigs returns an empty config for each Ignition spec version
+// that a plugin can generate.
+var ignitionConfigs = map[string]func() any{
+	"3.0.0":              func() any { return &v3_0.Config{} },
+	"3.1.0":              func() any { return &v3_1.Config{} },
+	"3.2.0":              func() any { return &v3_2.Config{} },
+	"3.3.0":              func() any { return &v3_3.Config{} },
+	"3.4.0":              func() any { return &v3_4.Config{} },
+	"3.5.0":              func() any { return &v3_5.Config{} },
+	"3.6.0":              func() any { return &v3_6.Config{} },
+	"3.7.0-experimental": func() any { return &v3_7_exp.Config{} },
+}
+
+// findPlugin searches pluginPath for a translator plugin for variant.
+func findPlugin(pluginPath, variant string) (string, bool) {
+	if pluginPath == "" || strings.ContainsAny(variant, `/\`) {
+		return "", false
+	}
+	for _, dir := range filepath.SplitList(pluginPath) {
+		if dir == "" {
+			continue
+		}
+		p := filepath.Join(dir, PluginPrefix+variant)
+		if info, err := os.Stat(p); err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0 {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// pluginTranslator returns a translator that runs the translator plugin
+// executable exe.
+func pluginTranslator(exe string) translator {
+	name := filepath.Base(exe)
+	return func(input []byte, options common.TranslateBytesOptions) ([]byte, report.Report, error) {
+		if options.Provenance != nil {
+			return nil, report.Report{}, common.ErrPluginProvenance
+		}
+		request, err := json.Marshal(PluginRequest{
+			Protocol: PluginProtocolVersion,
+			Config:   string(input),
+			Options: PluginOptions{
+				FilesDir:                  options.FilesDir,
+				NoResourceAutoCompression: options.NoResourceAutoCompression,
+				NoCompressUnder:           options.NoCompressUnder,
+				Flatten:                   options.Flatten,
+				Pretty:                    options.Pretty,
+				Raw:                       options.Raw,
+				Canonical:                 options.Canonical,
+			},
+		})
+		if err != nil {
+			return nil, report.Report{}, err
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(exe)
+		cmd.Stdin = bytes.NewReader(request)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			detail := err.Error()
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				detail = fmt.Sprintf("%s: %s", detail, msg)
+			}
+			return nil, report.Report{}, common.ErrPlugin{Plugin: name, Detail: detail}
+		}
+		var response PluginResponse
+		decoder := json.NewDecoder(&stdout)
+		decoder.UseNumber()
+		if err := decoder.Decode(&response); err != nil {
+			return nil, report.Report{}, common.ErrPlugin{Plugin: name, Detail: fmt.Sprintf("parsing response: %v", err)}
+		}
+
+		ts := translate.NewTranslationSet("yaml", "json")
+		for _, t := range response.Translations {
+			from, err := pluginPath(t.From)
+			if err != nil {
+				return nil, report.Report{}, common.ErrPlugin{Plugin: name, Detail: err.Error()}
+			}
+			to, err := pluginPath(t.To)
+			if err != nil {
+				return nil, report.Report{}, common.ErrPlugin{Plugin: name, Detail: err.Error()}
+			}
+			ts.AddTranslation(path.New("yaml", from...), path.New("json", to...))
+		}
+		var r report.Report
+		for _, entry := range response.Report {
+			var kind report.EntryKind
+			switch entry.Kind {
+			case "error":
+				kind = report.Error
+			case "warning":
+				kind = report.Warn
+			case "info":
+				kind = report.Info
+			default:
+				return nil, report.Report{}, common.ErrPlugin{Plugin: name, Detail: fmt.Sprintf("invalid report entry kind %q", entry.Kind)}
+			}
+			tag := entry.Tag
+			switch tag {
+			case "":
+				tag = "yaml"
+			case "yaml", "json":
+			default:
+				return nil, report.Report{}, common.ErrPlugin{Plugin: name, Detail: fmt.Sprintf("invalid report entry tag %q", entry.Tag)}
+			}
+			p, err := pluginPath(entry.Path)
+			if err != nil {
+				return nil, report.Report{}, common.ErrPlugin{Plugin: name, Detail: err.Error()}
+			}
+			r.AddOn(path.New(tag, p...), errors.New(entry.Message), kind)
+		}
+		r = cutil.TranslateReportPaths(r, ts)
+		contextTree, treeErr := vyaml.UnmarshalToContext(input)
+		if treeErr == nil {
+			r.Correlate(contextTree)
+		}
+		if options.Translations != nil {
+			*options.Translations = ts
+		}
+
+		if response.Error != "" {
+			return nil, r, common.ErrPlugin{Plugin: name, Detail: response.Error}
+		}
+		if r.IsFatal() {
+			return nil, r, common.ErrInvalidSourceConfig
+		}
+
+		// check the output like that of a built-in variant
+		outputType := response.OutputType
+		if options.Raw {
+			// MachineConfig variants output the bare Ignition config
+			outputType = "ignition"
+		}
+		outputReport, err := validatePluginOutput([]byte(response.Output), outputType, ts)
+		if err != nil {
+			return nil, r, common.ErrPlugin{Plugin: name, Detail: err.Error()}
+		}
+		if treeErr == nil {
+			outputReport.Correlate(contextTree)
+		}
+		r.Merge(outputReport)
+		if r.IsFatal() {
+			return nil, r, common.ErrInvalidGeneratedConfig
+		}
+		return []byte(response.Output), r, nil
+	}
+}
+
+// validatePluginOutput checks that output is a valid Ignition config, or
+// a stream of objects whose MachineConfigs contain valid Ignition
+// configs, according to outputType, and returns a report against the
+// source config.
+func validatePluginOutput(output []byte, outputType string, ts translate.TranslationSet) (report.Report, error) {
+	switch outputType {
+	case "", "ignition":
+		r, err := validateIgnition(output)
+		if err != nil {
+			return report.Report{}, fmt.Errorf("invalid output: %w", err)
+		}
+		return cutil.TranslateReportPaths(r, ts), nil
+	case "machineconfig":
+		docs, err := cutil.SplitDocuments(output, ts)
+		if err != nil {
+			return report.Report{}, fmt.Errorf("invalid output: %w", err)
+		}
+		var r report.Report
+		for i, doc := range docs {
+			if doc.Value["kind"] != "MachineConfig" {
+				continue
+			}
+			spec, _ := doc.Value["spec"].(map[string]any)
+			if spec["config"] == nil {
+				continue
+			}
+			config, err := json.Marshal(spec["config"])
+			if err != nil {
+				return report.Report{}, err
+			}
+			configReport, err := validateIgnition(config)
+			if err != nil {
+				return report.Report{}, fmt.Errorf("invalid MachineConfig in output document %d: %w", i, err)
+			}
+			configReport = translate.PrefixReport(configReport, "config")
+			configReport = translate.PrefixReport(configReport, "spec")
+			r.Merge(cutil.TranslateReportPaths(configReport, doc.Translations))
+		}
+		return r, nil
+	default:
+		return report.Report{}, fmt.Errorf("invalid output type %q", outputType)
+	}
+}
+
+// validateIgnition validates the Ignition config in raw against the spec
+// version it declares.
+func validateIgnition(raw []byte) (report.Report, error) {
+	var header struct {
+		Ignition struct {
+			Version string `json:"version"`
+		} `json:"ignition"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return report.Report{}, err
+	}
+	newConfig, ok := ignitionConfigs[header.Ignition.Version]
+	if !ok {
+		return report.Report{}, fmt.Errorf("unsupported Ignition spec version %q", header.Ignition.Version)
+	}
+	cfg := newConfig()
+	if err := json.Unmarshal(raw, cfg); err != nil {
+		return report.Report{}, err
+	}
+	value := reflect.ValueOf(cfg).Elem().Interface()
+	r := validate.ValidateCustom(value, "json", ignvalidate.ValidateDups)
+	r.Merge(validate.Validate(value, "json"))
+	return r, nil
+}
+
+// pluginPath converts a path from a plugin response, whose elements are
+// field names and list indexes, to path elements.
+func pluginPath(elems []any) ([]any, error) {
+	ret := make([]any, 0, len(elems))
+	for _, elem := range elems {
+		switch e := elem.(type) {
+		case string:
+			ret = append(ret, e)
+		case json.Number:
+			i, err := e.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("invalid path element %v", e)
+			}
+			ret = append(ret, int(i))
+		default:
+			return nil, fmt.Errorf("invalid path element %v", e)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coreos/butane/config/common"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/coreos/ignition/v2/config/shared/errors"
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	"github.com/coreos/vcontext/tree"
+	"github.com/stretchr/testify/assert"
+)
+
+// when set, the test binary acts as a translator plugin
+const testPluginEnv = "BUTANE_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(testPluginEnv) != "" {
+		os.Exit(runTestPlugin())
+	}
+	os.Exit(m.Run())
+}
+
+// runTestPlugin implements a translator plugin that fails if the config
+// contains "fail", and otherwise writes a config with a file under the
+// files dir, or with a relative path if the config contains "relative",
+// and reports on the greeting field.  If the config contains
+// "machineconfig", the config is wrapped in a MachineConfig.
+func runTestPlugin() int {
+	var request PluginRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if strings.Contains(request.Config, "fail") {
+		fmt.Fprintln(os.Stderr, "told to fail")
+		return 1
+	}
+	filePath := request.Options.FilesDir + "/greeting"
+	if strings.Contains(request.Config, "relative") {
+		filePath = "greeting"
+	}
+	output := fmt.Sprintf(`{"ignition":{"version":"3.4.0"},"storage":{"files":[{"path":%q}]}}`, filePath)
+	var outputType string
+	var prefix []any
+	if strings.Contains(request.Config, "machineconfig") {
+		output = fmt.Sprintf(`{"apiVersion":"machineconfiguration.openshift.io/v1","kind":"MachineConfig","metadata":{"name":"99-worker-test"},"spec":{"config":%s}}`, output)
+		outputType = "machineconfig"
+		prefix = []any{"spec", "config"}
+	}
+	generated := append(prefix, "storage", "files", 0, "path")
+	response := PluginResponse{
+		Output:     output,
+		OutputType: outputType,
+		Report: []PluginReportEntry{
+			{Kind: "warning", Message: "greeting is informal", Path: []any{"greeting"}},
+			{Kind: "info", Message: "generated", Tag: "json", Path: generated},
+		},
+		Translations: []PluginTranslation{
+			{From: []any{"greeting"}, To: generated},
+		},
+	}
+	if strings.Contains(request.Config, "error") {
+		response.Error = "unsupported version"
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(response); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func TestPlugin(t *testing.T) {
+	exe, err := os.Executable()
+	if !assert.NoError(t, err) {
+		return
+	}
+	dir := t.TempDir()
+	if !assert.NoError(t, os.Symlink(exe, filepath.Join(dir, PluginPrefix+"test-plugin"))) {
+		return
+	}
+	t.Setenv(testPluginEnv, "1")
+	options := common.TranslateBytesOptions{
+		TranslateOptions: common.TranslateOptions{
+			FilesDir: "/files",
+		},
+		PluginPath: strings.Join([]string{t.TempDir(), dir}, string(os.PathListSeparator)),
+	}
+
+	out, r, err := TranslateBytes([]byte("variant: test-plugin\nversion: 1.0.0\ngreeting: hi\n"), options)
+	assert.NoError(t, err, "translation failed")
+	assert.Equal(t, `{"ignition":{"version":"3.4.0"},"storage":{"files":[{"path":"/files/greeting"}]}}`, string(out), "bad output")
+	for i := range r.Entries {
+		// markers depend on the input layout
+		r.Entries[i].Marker = tree.Marker{}
+	}
+	assert.Equal(t, []report.Entry{
+		{
+			Kind:    report.Warn,
+			Message: "greeting is informal",
+			Context: path.New("yaml", "greeting"),
+		},
+		{
+			Kind:    report.Info,
+			Message: "generated",
+			Context: path.New("yaml", "greeting"),
+		},
+	}, r.Entries, "bad report")
+
+	// invalid generated configs are reported against the source
+	for _, config := range []string{"relative: true\n", "relative: true\nmachineconfig: true\n"} {
+		_, r, err = TranslateBytes([]byte("variant: test-plugin\nversion: 1.0.0\ngreeting: hi\n"+config), options)
+		assert.Equal(t, common.ErrInvalidGeneratedConfig, err, "bad error for invalid output")
+		assert.Equal(t, []report.Entry{
+			{
+				Kind:    report.Error,
+				Message: errors.ErrPathRelative.Error(),
+				Context: path.New("yaml", "greeting"),
+				Marker:  tree.Marker{StartP: &tree.Pos{Line: 3, Column: 11}},
+			},
+		}, r.Entries[2:], "bad report for invalid output")
+	}
+
+	_, _, err = TranslateBytes([]byte("variant: test-plugin\nversion: 1.0.0\nerror: true\n"), options)
+	assert.Equal(t, common.ErrPlugin{Plugin: PluginPrefix + "test-plugin", Detail: "unsupported version"}, err, "bad error for plugin error")
+
+	_, _, err = TranslateBytes([]byte("variant: test-plugin\nversion: 1.0.0\nfail: true\n"), options)
+	assert.Equal(t, common.ErrPlugin{Plugin: PluginPrefix + "test-plugin", Detail: "exit status 1: told to fail"}, err, "bad error for plugin failure")
+
+	options.Provenance = &common.Provenance{}
+	_, _, err = TranslateBytes([]byte("variant: test-plugin\nversion: 1.0.0\n"), options)
+	assert.Equal(t, common.ErrPluginProvenance, err, "bad error for provenance")
+
+	_, _, err = TranslateBytes([]byte("variant: test-missing\nversion: 1.0.0\n"), common.TranslateBytesOptions{PluginPath: dir})
+	assert.Equal(t, common.ErrUnknownVersion{Variant: "test-missing", Version: *semver.New("1.0.0")}, err, "bad error for missing plugin")
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package util
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UnorderedLists maps Ignition config lists whose order isn't significant
+// to the field that uniquely identifies each entry.  Ignition rejects
+// duplicate keys, so sorting by these fields gives a total order.  Lists
+// not named here (for example partitions or config merges) are ordered
+// and must be left alone.
+var UnorderedLists = map[string]string{
+	"passwd.groups":         "name",
+	"passwd.users":          "name",
+	"storage.directories":   "path",
+	"storage.disks":         "device",
+	"storage.files":         "path",
+	"storage.filesystems":   "device",
+	"storage.links":         "path",
+	"storage.luks":          "name",
+	"storage.raid":          "name",
+	"systemd.units":         "name",
+	"systemd.units.dropins": "name",
+}
+
+// Canonicalize sorts the unordered lists in the translated config v, so
+// that equivalent configs are serialized identically, and returns the
+// result.  Slices and pointers are copied before sorting, so v itself is
+// not modified.  v can be an Ignition config or a wrapper which embeds the
+// Ignition config in spec.config, such as a MachineConfig.
+func Canonicalize(v any) any {
+	value := reflect.New(reflect.TypeOf(v)).Elem()
+	value.Set(reflect.ValueOf(v))
+	canonicalize(value, "")
+	return value.Interface()
+}
+
+func canonicalize(v reflect.Value, p string) {
+	switch v.Kind() {
+	case reflect.Struct:
+		typ := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if typ.Field(i).Anonymous {
+				canonicalize(v.Field(i), p)
+			} else {
+				canonicalize(v.Field(i), strings.TrimPrefix(fmt.Sprintf("%s.%s", p, getTag(typ.Field(i))), "."))
+			}
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			// don't modify the caller's pointee
+			elem := reflect.New(v.Type().Elem())
+			elem.Elem().Set(v.Elem())
+			canonicalize(elem.Elem(), p)
+			v.Set(elem)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			elem := reflect.New(v.Elem().Type()).Elem()
+			elem.Set(v.Elem())
+			canonicalize(elem, p)
+			v.Set(elem)
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		// don't sort the caller's backing array
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(copied, v)
+		v.Set(copied)
+		for i := 0; i < v.Len(); i++ {
+			canonicalize(v.Index(i), p)
+		}
+		// MachineConfigs carry the Ignition config in spec.config
+		if key, ok := UnorderedLists[strings.TrimPrefix(p, "spec.config.")]; ok {
+			sort.SliceStable(v.Interface(), func(i, j int) bool {
+				return listKey(v.Index(i), key) < listKey(v.Index(j), key)
+			})
+		}
+	}
+}
+
+// listKey returns the string value of the field tagged key in the struct v,
+// descending into embedded structs and dereferencing pointers.  It returns
+// "" if the field is missing or nil.
+func listKey(v reflect.Value, key string) string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	typ := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous {
+			if ret := listKey(v.Field(i), key); ret != "" {
+				return ret
+			}
+			continue
+		}
+		if getTag(field) != key {
+			continue
+		}
+		f := v.Field(i)
+		if f.Kind() == reflect.Ptr {
+			if f.IsNil() {
+				return ""
+			}
+			f = f.Elem()
+		}
+		if f.Kind() == reflect.String {
+			return f.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package util
+
+import (
+	"testing"
+
+	"github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type wrapper struct {
+	Spec wrapperSpec `json:"spec"`
+}
+
+type wrapperSpec struct {
+	Config     types.Config `json:"config"`
+	Extensions []string     `json:"extensions"`
+}
+
+func TestCanonicalize(t *testing.T) {
+	in := types.Config{
+		Ignition: types.Ignition{
+			Config: types.IgnitionConfig{
+				Merge: []types.Resource{
+					{Source: util.StrToPtr("https://example.com/b")},
+					{Source: util.StrToPtr("https://example.com/a")},
+				},
+			},
+		},
+		Passwd: types.Passwd{
+			Users: []types.PasswdUser{
+				{Name: "core"},
+				{Name: "admin"},
+			},
+		},
+		Storage: types.Storage{
+			Disks: []types.Disk{
+				{
+					Device: "/dev/vdb",
+					Partitions: []types.Partition{
+						{Label: util.StrToPtr("z")},
+						{Label: util.StrToPtr("a")},
+					},
+				},
+				{
+					Device: "/dev/vda",
+				},
+			},
+			Files: []types.File{
+				{Node: types.Node{Path: "/etc/z"}},
+				{Node: types.Node{Path: "/etc/a"}},
+				{Node: types.Node{Path: "/etc/m"}},
+			},
+		},
+		Systemd: types.Systemd{
+			Units: []types.Unit{
+				{
+					Name: "z.service",
+					Dropins: []types.Dropin{
+						{Name: "b.conf"},
+						{Name: "a.conf"},
+					},
+				},
+				{Name: "a.service"},
+			},
+		},
+	}
+	expected := types.Config{
+		Ignition: types.Ignition{
+			Config: types.IgnitionConfig{
+				Merge: []types.Resource{
+					{Source: util.StrToPtr("https://example.com/b")},
+					{Source: util.StrToPtr("https://example.com/a")},
+				},
+			},
+		},
+		Passwd: types.Passwd{
+			Users: []types.PasswdUser{
+				{Name: "admin"},
+				{Name: "core"},
+			},
+		},
+		Storage: types.Storage{
+			Disks: []types.Disk{
+				{
+					Device: "/dev/vda",
+				},
+				{
+					Device: "/dev/vdb",
+					Partitions: []types.Partition{
+						{Label: util.StrToPtr("z")},
+						{Label: util.StrToPtr("a")},
+					},
+				},
+			},
+			Files: []types.File{
+				{Node: types.Node{Path: "/etc/a"}},
+				{Node: types.Node{Path: "/etc/m"}},
+				{Node: types.Node{Path: "/etc/z"}},
+			},
+		},
+		Systemd: types.Systemd{
+			Units: []types.Unit{
+				{Name: "a.service"},
+				{
+					Name: "z.service",
+					Dropins: []types.Dropin{
+						{Name: "a.conf"},
+						{Name: "b.conf"},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, Canonicalize(in))
+	// the input is left alone
+	assert.Equal(t, "core", in.Passwd.Users[0].Name)
+	assert.Equal(t, "b.conf", in.Systemd.Units[0].Dropins[0].Name)
+
+	// wrapped config
+	wrapped := Canonicalize(wrapper{
+		Spec: wrapperSpec{
+			Config:     in,
+			Extensions: []string{"z", "a"},
+		},
+	})
+	assert.Equal(t, wrapper{
+		Spec: wrapperSpec{
+			Config:     expected,
+			Extensions: []string{"z", "a"},
+		},
+	}, wrapped)
+}
@@ -16,7 +16,10 @@ package util
 
 import (
 	"fmt"
+	"net/url"
 	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/coreos/ignition/v2/config/util"
@@ -26,8 +29,31 @@ import (
 
 type FilterMap map[string]error
 
+// ValueFilterMap maps dot-separated field paths in the generated config
+// to the restrictions on the field's values.
+type ValueFilterMap map[string]ValueFilter
+
+// ValueFilter rejects values of a primitive field, or of the elements of
+// a list of primitives, that fail one of its rules.  Unset and zero values
+// aren't checked.
+type ValueFilter []ValueRule
+
+type ValueRule struct {
+	Allowed ValuePredicate
+	Err     error
+}
+
+// ValuePredicate decides whether a primitive field value is allowed.
+type ValuePredicate interface {
+	Allows(v any) bool
+	// Describe describes the allowed values for documentation, e.g.
+	// "must be one of `a`, `b`"
+	Describe() string
+}
+
 type FieldFilters struct {
 	filters FilterMap
+	values  ValueFilterMap
 	// openshift 4.8 and 4.9 specs want to filter out the compression
 	// field but ignore a pointer to a zero value (StrToPtr(""))
 	// because those are generated automatically by desugaring.  Provide
@@ -40,17 +66,37 @@ func NewFilters(v any, filters FilterMap) FieldFilters {
 }
 
 func NewFiltersIgnoreZero(v any, filters FilterMap, ignoreZero []string) FieldFilters {
+	return newFilters(v, filters, ValueFilterMap{}, ignoreZero)
+}
+
+// NewValueFilters is like NewFilters but also restricts the values of the
+// fields in values.
+func NewValueFilters(v any, filters FilterMap, values ValueFilterMap) FieldFilters {
+	return newFilters(v, filters, values, []string{})
+}
+
+func NewValueFiltersIgnoreZero(v any, filters FilterMap, values ValueFilterMap, ignoreZero []string) FieldFilters {
+	return newFilters(v, filters, values, ignoreZero)
+}
+
+func newFilters(v any, filters FilterMap, values ValueFilterMap, ignoreZero []string) FieldFilters {
 	for filter := range filters {
 		if !isValidFilter(reflect.TypeOf(v), filter) {
 			panic(fmt.Errorf("invalid filter path: %s", filter))
 		}
 	}
+	for filter := range values {
+		if !isValidFilter(reflect.TypeOf(v), filter) {
+			panic(fmt.Errorf("invalid value filter path: %s", filter))
+		}
+	}
 	ignore := make(map[string]struct{})
 	for _, value := range ignoreZero {
 		ignore[value] = struct{}{}
 	}
 	return FieldFilters{
 		filters:    filters,
+		values:     values,
 		ignoreZero: ignore,
 	}
 }
@@ -81,6 +127,9 @@ func isValidFilter(typ reflect.Type, filter string) bool {
 		return false
 	case kind == reflect.Slice, kind == reflect.Ptr:
 		return isValidFilter(typ.Elem(), filter)
+	case kind == reflect.Map, kind == reflect.Interface:
+		// untyped; any path might exist
+		return true
 	default:
 		panic(fmt.Errorf("%v has kind %v", typ.Name(), kind))
 	}
@@ -90,6 +139,16 @@ func (ff FieldFilters) Verify(v any) report.Report {
 	return ff.verify(reflect.ValueOf(v), "", path.New("json"))
 }
 
+// VerifyValues is like Verify but only checks the value filters.
+func (ff FieldFilters) VerifyValues(v any) report.Report {
+	values := FieldFilters{
+		filters:    FilterMap{},
+		values:     ff.values,
+		ignoreZero: ff.ignoreZero,
+	}
+	return values.Verify(v)
+}
+
 func (ff FieldFilters) verify(v reflect.Value, filter string, p path.ContextPath) (r report.Report) {
 	if err := ff.Lookup(filter); err != nil {
 		// This object is filtered.  Add an error if it's non-empty,
@@ -104,6 +163,14 @@ func (ff FieldFilters) verify(v reflect.Value, filter string, p path.ContextPath
 	kind := typ.Kind()
 	switch {
 	case util.IsPrimitive(kind):
+		if v.IsZero() {
+			return
+		}
+		for _, rule := range ff.LookupValues(filter) {
+			if !rule.Allowed.Allows(v.Interface()) {
+				r.AddOnError(p, rule.Err)
+			}
+		}
 	case kind == reflect.Struct:
 		for i := 0; i < v.NumField(); i++ {
 			field := v.Field(i)
@@ -122,8 +189,15 @@ func (ff FieldFilters) verify(v reflect.Value, filter string, p path.ContextPath
 		if !v.IsNil() {
 			r.Merge(ff.verify(v.Elem(), filter, p))
 		}
+	case kind == reflect.Interface:
+		if !v.IsNil() {
+			r.Merge(ff.verify(v.Elem(), filter, p))
+		}
 	case kind == reflect.Map:
-		// not supported in filters; ignore
+		// untyped objects, e.g. JSON decoded into map[string]any
+		for _, key := range sortedKeys(v) {
+			r.Merge(ff.verify(v.MapIndex(key), fmt.Sprintf("%s.%s", filter, key), p.Append(key.String())))
+		}
 	default:
 		panic(fmt.Errorf("%v has kind %v", typ.Name(), kind))
 	}
@@ -165,8 +239,14 @@ func (ff FieldFilters) isEmpty(v reflect.Value, filter string) bool {
 			}
 		}
 		return false
+	case kind == reflect.Interface:
+		return v.IsNil() || ff.isEmpty(v.Elem(), filter)
 	case kind == reflect.Map:
-		// not supported in filters; prune
+		for _, key := range v.MapKeys() {
+			if !ff.isEmpty(v.MapIndex(key), fmt.Sprintf("%s.%s", filter, key)) {
+				return false
+			}
+		}
 		return true
 	default:
 		panic(fmt.Errorf("%v has kind %v", typ.Name(), kind))
@@ -177,6 +257,30 @@ func (ff FieldFilters) Lookup(filter string) error {
 	return ff.filters[strings.TrimPrefix(filter, ".")]
 }
 
+// LookupValues returns the rules restricting the values of a field.
+func (ff FieldFilters) LookupValues(filter string) ValueFilter {
+	return ff.values[strings.TrimPrefix(filter, ".")]
+}
+
+// ValueFilterPaths returns the sorted paths of the fields with
+// restricted values.
+func (ff FieldFilters) ValueFilterPaths() []string {
+	var paths []string
+	for filter := range ff.values {
+		paths = append(paths, filter)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func sortedKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
+
 func getTag(field reflect.StructField) string {
 	tag, ok := field.Tag.Lookup("json")
 	if !ok {
@@ -184,3 +288,112 @@ func getTag(field reflect.StructField) string {
 	}
 	return strings.Split(tag, ",")[0]
 }
+
+// MatchesPattern allows strings matching the regular expression pattern.
+func MatchesPattern(pattern string) ValuePredicate {
+	return patternPredicate{regexp.MustCompile(pattern)}
+}
+
+type patternPredicate struct {
+	re *regexp.Regexp
+}
+
+func (p patternPredicate) Allows(v any) bool {
+	return p.re.MatchString(fmt.Sprint(v))
+}
+
+func (p patternPredicate) Describe() string {
+	return fmt.Sprintf("must match `%s`", p.re)
+}
+
+// OneOf allows the listed values.
+func OneOf(values ...string) ValuePredicate {
+	return enumPredicate{values: values, allowed: true}
+}
+
+// NoneOf allows values other than those listed.
+func NoneOf(values ...string) ValuePredicate {
+	return enumPredicate{values: values}
+}
+
+type enumPredicate struct {
+	values  []string
+	allowed bool
+}
+
+func (p enumPredicate) Allows(v any) bool {
+	s := fmt.Sprint(v)
+	for _, value := range p.values {
+		if s == value {
+			return p.allowed
+		}
+	}
+	return !p.allowed
+}
+
+func (p enumPredicate) Describe() string {
+	list := fmt.Sprintf("`%s`", strings.Join(p.values, "`, `"))
+	switch {
+	case p.allowed && len(p.values) == 1:
+		return "must be " + list
+	case p.allowed:
+		return "must be one of " + list
+	case len(p.values) == 1:
+		return "must not be " + list
+	default:
+		return "must not be any of " + list
+	}
+}
+
+// InRange allows integers from min to max inclusive.  format is the
+// fmt verb used to describe the bounds, such as "%d" or "%#o".
+func InRange(min, max int64, format string) ValuePredicate {
+	return rangePredicate{min, max, format}
+}
+
+type rangePredicate struct {
+	min, max int64
+	format   string
+}
+
+func (p rangePredicate) Allows(v any) bool {
+	value := reflect.ValueOf(v)
+	if !value.CanInt() {
+		return false
+	}
+	return value.Int() >= p.min && value.Int() <= p.max
+}
+
+func (p rangePredicate) Describe() string {
+	return fmt.Sprintf("must be between "+p.format+" and "+p.format, p.min, p.max)
+}
+
+// URLScheme allows URLs with one of the listed schemes.  Unparseable URLs
+// are allowed, since config validation reports them.
+func URLScheme(schemes ...string) ValuePredicate {
+	return schemePredicate{schemes}
+}
+
+type schemePredicate struct {
+	schemes []string
+}
+
+func (p schemePredicate) Allows(v any) bool {
+	u, err := url.Parse(fmt.Sprint(v))
+	if err != nil {
+		return true
+	}
+	for _, scheme := range p.schemes {
+		if u.Scheme == scheme {
+			return true
+		}
+	}
+	return false
+}
+
+func (p schemePredicate) Describe() string {
+	if len(p.schemes) == 1 {
+		return fmt.Sprintf("must use the `%s` URL scheme", p.schemes[0])
+	}
+	return fmt.Sprintf("must use one of the `%s` URL schemes", strings.Join(p.schemes, "`, `"))
+}
@@ -15,6 +15,7 @@
 package util
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -151,3 +152,106 @@ func TestFilter(t *testing.T) {
 		"ssb.sp": ErrSP,
 	}).Verify(obj))
 }
+
+func TestValueFilter(t *testing.T) {
+	obj := StrA{
+		I: 7,
+		S: "file:///etc/motd",
+		SB: StrB{
+			IP: util.IntToPtr(0755),
+			SP: util.StrToPtr("btrfs"),
+		},
+		SSB: []StrB{
+			{
+				IP: util.IntToPtr(04755),
+				SP: util.StrToPtr("xfs"),
+			},
+			{
+				SP: util.StrToPtr("none"),
+			},
+		},
+	}
+	filters := NewValueFilters(StrA{}, FilterMap{
+		"b": ErrB,
+	}, ValueFilterMap{
+		"i": {
+			{Allowed: InRange(1, 5, "%d"), Err: ErrI},
+		},
+		"s": {
+			{Allowed: URLScheme("data", "https"), Err: ErrS},
+		},
+		"sb.ip": {
+			{Allowed: InRange(0, 0777, "%#o"), Err: ErrIP},
+		},
+		"sb.sp": {
+			{Allowed: NoneOf("btrfs"), Err: ErrSB},
+			{Allowed: MatchesPattern("^b"), Err: ErrSP},
+		},
+		"ssb.ip": {
+			{Allowed: InRange(0, 0777, "%#o"), Err: ErrIP},
+		},
+		"ssb.sp": {
+			{Allowed: OneOf("xfs", "ext4"), Err: ErrSSB},
+		},
+	})
+
+	var expected report.Report
+	expected.AddOnError(path.New("json", "i"), ErrI)
+	expected.AddOnError(path.New("json", "s"), ErrS)
+	expected.AddOnError(path.New("json", "sb", "sp"), ErrSB)
+	expected.AddOnError(path.New("json", "ssb", 0, "ip"), ErrIP)
+	expected.AddOnError(path.New("json", "ssb", 1, "sp"), ErrSSB)
+	assert.Equal(t, expected, filters.Verify(obj))
+
+	// presence filters are skipped
+	obj.B = true
+	assert.Equal(t, expected, filters.VerifyValues(obj))
+
+	// value filters don't hide fields
+	assert.Nil(t, filters.Lookup("sb.sp"))
+	assert.Equal(t, ErrB, filters.Lookup("b"))
+	assert.Len(t, filters.LookupValues("sb.sp"), 2)
+	assert.Equal(t, []string{"i", "s", "sb.ip", "sb.sp", "ssb.ip", "ssb.sp"}, filters.ValueFilterPaths())
+
+	for _, test := range []struct {
+		predicate ValuePredicate
+		desc      string
+	}{
+		{MatchesPattern("^b"), "must match `^b`"},
+		{OneOf("core"), "must be `core`"},
+		{OneOf("xfs", "ext4"), "must be one of `xfs`, `ext4`"},
+		{NoneOf("btrfs"), "must not be `btrfs`"},
+		{NoneOf("btrfs", "none"), "must not be any of `btrfs`, `none`"},
+		{InRange(0, 0777, "%#o"), "must be between 0 and 0777"},
+		{URLScheme("data"), "must use the `data` URL scheme"},
+		{URLScheme("data", "https"), "must use one of the `data`, `https` URL schemes"},
+	} {
+		assert.Equal(t, test.desc, test.predicate.Describe())
+	}
+}
+
+func TestUntypedFilters(t *testing.T) {
+	var obj map[string]any
+	err := json.Unmarshal([]byte(`{"b": true, "sb": {"sp": "btrfs"}, "ssb": [{"sp": "xfs"}, {"sp": "none", "bp": false}], "x": null}`), &obj)
+	if !assert.NoError(t, err) {
+		return
+	}
+	filters := NewValueFilters(map[string]any{}, FilterMap{
+		"b":      ErrB,
+		"ssb.bp": ErrBP,
+		"x":      ErrS,
+	}, ValueFilterMap{
+		"sb.sp": {
+			{Allowed: NoneOf("btrfs"), Err: ErrSB},
+		},
+		"ssb.sp": {
+			{Allowed: OneOf("xfs", "ext4"), Err: ErrSSB},
+		},
+	})
+
+	var expected report.Report
+	expected.AddOnError(path.New("json", "b"), ErrB)
+	expected.AddOnError(path.New("json", "sb", "sp"), ErrSB)
+	expected.AddOnError(path.New("json", "ssb", 1, "sp"), ErrSSB)
+	assert.Equal(t, expected, filters.Verify(obj))
+}
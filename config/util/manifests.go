@@ -0,0 +1,218 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coreos/butane/translate"
+
+	"github.com/coreos/vcontext/path"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// first line of every YAML config generated by Butane
+	GeneratedHeader = "# Generated by Butane; do not edit\n"
+
+	// openshift-install manifests subdirectory for MachineConfigs and
+	// their companion objects
+	OpenShiftManifestsDir = "openshift"
+)
+
+var (
+	ErrNotManifest         = errors.New("config doesn't generate Kubernetes manifests")
+	ErrInvalidManifestName = errors.New("manifest name can't be used as a filename")
+	ErrManifestNotButane   = errors.New("refusing to overwrite file not generated by Butane")
+)
+
+// Manifest is a single Kubernetes object generated by Butane.
+type Manifest struct {
+	Kind     string
+	Name     string
+	Contents []byte
+}
+
+// Path returns the path of the manifest relative to an openshift-install
+// manifests directory.
+func (m Manifest) Path() string {
+	return filepath.Join(OpenShiftManifestsDir, m.Name+".yaml")
+}
+
+// SplitManifests splits YAML output from TranslateBytesYAML into one
+// manifest per object.
+func SplitManifests(output []byte) ([]Manifest, error) {
+	if !bytes.HasPrefix(output, []byte(GeneratedHeader)) {
+		return nil, ErrNotManifest
+	}
+	var manifests []Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(output))
+	for {
+		var obj map[string]interface{}
+		if err := decoder.Decode(&obj); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		kind, _ := obj["kind"].(string)
+		metadata, _ := obj["metadata"].(map[string]interface{})
+		name, _ := metadata["name"].(string)
+		if kind == "" || metadata == nil {
+			return nil, ErrNotManifest
+		}
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidManifestName, name)
+		}
+
+		var buf bytes.Buffer
+		buf.WriteString(GeneratedHeader)
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(obj); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, Manifest{
+			Kind:     kind,
+			Name:     name,
+			Contents: buf.Bytes(),
+		})
+	}
+	return manifests, nil
+}
+
+// Document is a single object in the output of TranslateBytes or
+// TranslateBytesYAML, along with the translations that produced it.
+type Document struct {
+	Value        map[string]interface{}
+	Translations translate.TranslationSet
+}
+
+// SplitDocuments splits translated output into its documents.  ts is the
+// TranslationSet returned via TranslateOptions.Translations, whose To
+// paths are prefixed by document index if there are several documents.
+func SplitDocuments(output []byte, ts translate.TranslationSet) ([]Document, error) {
+	var docs []Document
+	// JSON is a subset of YAML
+	decoder := yaml.NewDecoder(bytes.NewReader(output))
+	for {
+		var obj map[string]interface{}
+		if err := decoder.Decode(&obj); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		docs = append(docs, Document{Value: obj})
+	}
+	for i := range docs {
+		if len(docs) > 1 {
+			docs[i].Translations = ts.Descend(path.New(ts.ToTag, i))
+		} else {
+			docs[i].Translations = ts
+		}
+	}
+	return docs, nil
+}
+
+// CheckManifestWritable returns an error if writing the manifest into
+// the manifests directory dir would overwrite a file not generated by
+// Butane.
+func CheckManifestWritable(dir string, m Manifest) error {
+	existing, err := os.ReadFile(filepath.Join(dir, m.Path()))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(existing, []byte(GeneratedHeader)) {
+		return fmt.Errorf("%w: %s", ErrManifestNotButane, m.Path())
+	}
+	return nil
+}
+
+// WriteManifest writes the manifest into the manifests directory dir,
+// creating the subdirectory if needed.  It refuses to overwrite files not
+// generated by Butane.
+func WriteManifest(dir string, m Manifest) error {
+	return WriteManifests(dir, []Manifest{m})
+}
+
+// WriteManifests writes the manifests into the manifests directory dir
+// like WriteManifest.  If any manifest can't be written, none are: each
+// is written to a temporary file that is renamed into place only after
+// all have been written.  Only a failed rename, which is unlikely, can
+// leave some manifests updated and others not.
+func WriteManifests(dir string, manifests []Manifest) error {
+	for _, m := range manifests {
+		if err := CheckManifestWritable(dir, m); err != nil {
+			return err
+		}
+	}
+	var temps []string
+	defer func() {
+		// no-op for files already renamed
+		for _, temp := range temps {
+			os.Remove(temp)
+		}
+	}()
+	for _, m := range manifests {
+		temp, err := writeTemp(filepath.Join(dir, m.Path()), m.Contents)
+		if err != nil {
+			return err
+		}
+		temps = append(temps, temp)
+	}
+	for i, m := range manifests {
+		if err := os.Rename(temps[i], filepath.Join(dir, m.Path())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTemp writes contents to a new temporary file in the directory of
+// p, creating the directory if needed, and returns the file's path.
+func writeTemp(p string, contents []byte) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(filepath.Dir(p), "."+filepath.Base(p)+".*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.Write(contents); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	// CreateTemp uses mode 0600
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
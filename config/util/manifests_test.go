@@ -0,0 +1,149 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package util
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/butane/translate"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitManifests(t *testing.T) {
+	output := GeneratedHeader + `apiVersion: machineconfiguration.openshift.io/v1
+kind: MachineConfig
+metadata:
+  name: 99-worker-example
+---
+apiVersion: machineconfiguration.openshift.io/v1
+kind: KubeletConfig
+metadata:
+  name: 99-worker-example-kubelet
+`
+	manifests, err := SplitManifests([]byte(output))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Manifest{
+		{
+			Kind: "MachineConfig",
+			Name: "99-worker-example",
+			Contents: []byte(GeneratedHeader + `apiVersion: machineconfiguration.openshift.io/v1
+kind: MachineConfig
+metadata:
+  name: 99-worker-example
+`),
+		},
+		{
+			Kind: "KubeletConfig",
+			Name: "99-worker-example-kubelet",
+			Contents: []byte(GeneratedHeader + `apiVersion: machineconfiguration.openshift.io/v1
+kind: KubeletConfig
+metadata:
+  name: 99-worker-example-kubelet
+`),
+		},
+	}, manifests)
+	assert.Equal(t, filepath.Join("openshift", "99-worker-example.yaml"), manifests[0].Path())
+
+	_, err = SplitManifests([]byte(`{"ignition":{"version":"3.4.0"}}`))
+	assert.Equal(t, ErrNotManifest, err, "bad error for Ignition config")
+	_, err = SplitManifests([]byte(GeneratedHeader + "kind: MachineConfig\nmetadata:\n  name: ../x\n"))
+	assert.True(t, errors.Is(err, ErrInvalidManifestName), "bad error for invalid name: %v", err)
+}
+
+func TestSplitDocuments(t *testing.T) {
+	// a single document keeps its translations
+	ts := translate.NewTranslationSet("yaml", "json")
+	ts.AddTranslation(path.New("yaml", "storage"), path.New("json", "storage"))
+	docs, err := SplitDocuments([]byte(`{"ignition":{"version":"3.4.0"}}`), ts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Document{
+		{
+			Value: map[string]interface{}{
+				"ignition": map[string]interface{}{
+					"version": "3.4.0",
+				},
+			},
+			Translations: ts,
+		},
+	}, docs)
+
+	// several documents get the translations of their index
+	ts = translate.NewTranslationSet("yaml", "json")
+	ts.AddTranslation(path.New("yaml", "metadata", "name"), path.New("json", 0, "metadata", "name"))
+	ts.AddTranslation(path.New("yaml", "openshift", "kubelet"), path.New("json", 1, "spec"))
+	docs, err = SplitDocuments([]byte(GeneratedHeader+"kind: MachineConfig\n---\nkind: KubeletConfig\n"), ts)
+	if !assert.NoError(t, err) || !assert.Len(t, docs, 2) {
+		return
+	}
+	assert.Equal(t, map[string]interface{}{"kind": "MachineConfig"}, docs[0].Value)
+	assert.Equal(t, map[string]interface{}{"kind": "KubeletConfig"}, docs[1].Value)
+	expected0 := translate.NewTranslationSet("yaml", "json")
+	expected0.AddTranslation(path.New("yaml", "metadata", "name"), path.New("json", "metadata", "name"))
+	assert.Equal(t, expected0, docs[0].Translations)
+	expected1 := translate.NewTranslationSet("yaml", "json")
+	expected1.AddTranslation(path.New("yaml", "openshift", "kubelet"), path.New("json", "spec"))
+	assert.Equal(t, expected1, docs[1].Translations)
+}
+
+func TestWriteManifest(t *testing.T) {
+	dir := t.TempDir()
+	m := Manifest{
+		Kind:     "MachineConfig",
+		Name:     "99-worker-example",
+		Contents: []byte(GeneratedHeader + "kind: MachineConfig\n"),
+	}
+
+	// new and previously generated files are written
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, WriteManifest(dir, m))
+		contents, err := os.ReadFile(filepath.Join(dir, m.Path()))
+		assert.NoError(t, err)
+		assert.Equal(t, m.Contents, contents)
+	}
+
+	// other files are left alone
+	if err := os.WriteFile(filepath.Join(dir, m.Path()), []byte("kind: MachineConfig\n"), 0644); err != nil {
+		t.Error(err)
+		return
+	}
+	err := WriteManifest(dir, m)
+	assert.True(t, errors.Is(err, ErrManifestNotButane), "bad error: %v", err)
+	contents, err := os.ReadFile(filepath.Join(dir, m.Path()))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("kind: MachineConfig\n"), contents)
+
+	// nothing is written if anything can't be
+	other := Manifest{
+		Kind:     "MachineConfig",
+		Name:     "99-master-example",
+		Contents: []byte(GeneratedHeader + "kind: MachineConfig\n"),
+	}
+	err = WriteManifests(dir, []Manifest{other, m})
+	assert.True(t, errors.Is(err, ErrManifestNotButane), "bad error: %v", err)
+	_, err = os.Stat(filepath.Join(dir, other.Path()))
+	assert.True(t, errors.Is(err, os.ErrNotExist), "manifest was written: %v", err)
+	entries, err := os.ReadDir(filepath.Join(dir, OpenShiftManifestsDir))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "unexpected files")
+}
@@ -0,0 +1,205 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/coreos/butane/config/common"
+	"github.com/coreos/butane/translate"
+
+	"github.com/clarketm/json"
+	"github.com/coreos/vcontext/path"
+	"github.com/vincent-petithory/dataurl"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// path of the provenance file in Ignition configs
+	ProvenancePath = "/etc/butane/provenance.json"
+
+	// provenance annotations in MachineConfigs
+	ProvenanceVersionAnnotation = "butane.coreos.com/version"
+	ProvenanceSourceAnnotation  = "butane.coreos.com/source-sha256"
+	ProvenanceFilesAnnotation   = "butane.coreos.com/files"
+)
+
+var (
+	ErrNoProvenance = errors.New("config doesn't contain provenance metadata")
+)
+
+// Sha256 returns the hex-encoded SHA-256 digest of contents.
+func Sha256(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+// embedProvenance adds prov to the translated config v, which is an
+// Ignition config, a wrapper with Kubernetes-style metadata, or a slice
+// of wrappers, and returns the result and the translations of the added
+// fields, which all derive from the root of the source config.
+func embedProvenance(v any, prov common.Provenance) (any, translate.TranslationSet, error) {
+	ts := translate.NewTranslationSet("yaml", "json")
+	value := reflect.New(reflect.TypeOf(v)).Elem()
+	value.Set(reflect.ValueOf(v))
+
+	// lists of objects, such as MachineConfigs for several roles, whose
+	// translations are prefixed by the object index if there are several
+	if value.Kind() == reflect.Slice {
+		for i := 0; i < value.Len(); i++ {
+			item, itemTranslations, err := embedProvenance(value.Index(i).Interface(), prov)
+			if err != nil {
+				return nil, ts, err
+			}
+			value.Index(i).Set(reflect.ValueOf(item))
+			if value.Len() == 1 {
+				ts.Merge(itemTranslations)
+			} else {
+				ts.Merge(itemTranslations.PrefixPaths(path.New("yaml"), path.New("json", i)))
+			}
+		}
+		return value.Interface(), ts, nil
+	}
+
+	if metadata := value.FieldByName("Metadata"); metadata.IsValid() {
+		annotations := metadata.FieldByName("Annotations")
+		if !annotations.IsValid() {
+			return nil, ts, common.ErrProvenanceUnsupported
+		}
+		if annotations.IsNil() {
+			annotations.Set(reflect.ValueOf(map[string]string{}))
+		}
+		annotations.SetMapIndex(reflect.ValueOf(ProvenanceVersionAnnotation), reflect.ValueOf(prov.ButaneVersion))
+		annotations.SetMapIndex(reflect.ValueOf(ProvenanceSourceAnnotation), reflect.ValueOf(prov.SourceSha256))
+		if len(prov.Files) > 0 {
+			files, err := json.Marshal(prov.Files)
+			if err != nil {
+				return nil, ts, err
+			}
+			annotations.SetMapIndex(reflect.ValueOf(ProvenanceFilesAnnotation), reflect.ValueOf(string(files)))
+		}
+		ts.AddFromCommonSource(path.New("yaml"), path.New("json", "metadata", "annotations"), annotations.Interface())
+		ts.AddTranslation(path.New("yaml"), path.New("json", "metadata"))
+		return value.Interface(), ts, nil
+	}
+
+	files := value.FieldByName("Storage").FieldByName("Files")
+	if !files.IsValid() {
+		return nil, ts, common.ErrProvenanceUnsupported
+	}
+	for i := 0; i < files.Len(); i++ {
+		if files.Index(i).FieldByName("Path").String() == ProvenancePath {
+			return nil, ts, common.ErrProvenanceFileExists
+		}
+	}
+	contents, err := json.Marshal(prov)
+	if err != nil {
+		return nil, ts, err
+	}
+	source := dataurl.EncodeBytes(contents)
+	mode := 0644
+	file := reflect.New(files.Type().Elem()).Elem()
+	file.FieldByName("Path").SetString(ProvenancePath)
+	file.FieldByName("Contents").FieldByName("Source").Set(reflect.ValueOf(&source))
+	file.FieldByName("Mode").Set(reflect.ValueOf(&mode))
+	ts.AddFromCommonSource(path.New("yaml"), path.New("json", "storage", "files", files.Len()), file.Interface())
+	ts.AddTranslation(path.New("yaml"), path.New("json", "storage", "files"))
+	ts.AddTranslation(path.New("yaml"), path.New("json", "storage"))
+	files.Set(reflect.Append(files, file))
+	return value.Interface(), ts, nil
+}
+
+// ReadProvenance returns the provenance metadata embedded in a config
+// generated by Butane, which can be an Ignition config or a
+// MachineConfig in JSON or YAML.
+func ReadProvenance(output []byte) (common.Provenance, error) {
+	var prov common.Provenance
+	var cfg struct {
+		Metadata struct {
+			Annotations map[string]string `yaml:"annotations"`
+		} `yaml:"metadata"`
+		Storage struct {
+			Files []struct {
+				Path     string `yaml:"path"`
+				Contents struct {
+					Source *string `yaml:"source"`
+				} `yaml:"contents"`
+			} `yaml:"files"`
+		} `yaml:"storage"`
+	}
+	// JSON is a subset of YAML
+	if err := yaml.Unmarshal(output, &cfg); err != nil {
+		return prov, err
+	}
+
+	if annotations := cfg.Metadata.Annotations; annotations[ProvenanceSourceAnnotation] != "" {
+		prov.ButaneVersion = annotations[ProvenanceVersionAnnotation]
+		prov.SourceSha256 = annotations[ProvenanceSourceAnnotation]
+		if files, ok := annotations[ProvenanceFilesAnnotation]; ok {
+			if err := json.Unmarshal([]byte(files), &prov.Files); err != nil {
+				return prov, fmt.Errorf("parsing %s annotation: %w", ProvenanceFilesAnnotation, err)
+			}
+		}
+		return prov, nil
+	}
+
+	for _, file := range cfg.Storage.Files {
+		if file.Path != ProvenancePath || file.Contents.Source == nil {
+			continue
+		}
+		url, err := dataurl.DecodeString(*file.Contents.Source)
+		if err != nil {
+			return prov, fmt.Errorf("decoding %s: %w", ProvenancePath, err)
+		}
+		if err := json.Unmarshal(url.Data, &prov); err != nil {
+			return prov, fmt.Errorf("parsing %s: %w", ProvenancePath, err)
+		}
+		return prov, nil
+	}
+	return prov, ErrNoProvenance
+}
+
+// VerifyProvenance recomputes the hashes recorded in prov from the source
+// config and the files under filesDir, and returns a description of each
+// mismatch.
+func VerifyProvenance(prov common.Provenance, source []byte, filesDir string) []string {
+	var problems []string
+	if actual := Sha256(source); actual != prov.SourceSha256 {
+		problems = append(problems, fmt.Sprintf("source config: expected sha256 %s, found %s", prov.SourceSha256, actual))
+	}
+	var paths []string
+	for p := range prov.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		contents, err := os.ReadFile(filepath.Join(filesDir, filepath.FromSlash(p)))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", p, err))
+			continue
+		}
+		if actual := Sha256(contents); actual != prov.Files[p] {
+			problems = append(problems, fmt.Sprintf("%s: expected sha256 %s, found %s", p, prov.Files[p], actual))
+		}
+	}
+	return problems
+}
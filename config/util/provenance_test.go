@@ -0,0 +1,157 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/butane/config/common"
+
+	"github.com/clarketm/json"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/vcontext/path"
+	"github.com/stretchr/testify/assert"
+)
+
+type annotated struct {
+	Metadata annotatedMetadata `json:"metadata"`
+}
+
+type annotatedMetadata struct {
+	Name        string            `json:"name"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type unannotated struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+}
+
+// TestProvenance tests embedding provenance metadata and reading it back.
+func TestProvenance(t *testing.T) {
+	prov := common.Provenance{
+		ButaneVersion: "v0.29.0",
+		SourceSha256:  Sha256([]byte("source")),
+		Files: map[string]string{
+			"dir/file": Sha256([]byte("contents")),
+		},
+	}
+
+	tests := []struct {
+		in         any
+		translated path.ContextPath
+		err        error
+	}{
+		{
+			types.Config{
+				Storage: types.Storage{
+					Files: []types.File{
+						{Node: types.Node{Path: "/etc/motd"}},
+					},
+				},
+			},
+			path.New("json", "storage", "files", 1, "contents", "source"),
+			nil,
+		},
+		{
+			annotated{
+				Metadata: annotatedMetadata{
+					Name: "config",
+				},
+			},
+			path.New("json", "metadata", "annotations", ProvenanceFilesAnnotation),
+			nil,
+		},
+		{
+			[]annotated{
+				{Metadata: annotatedMetadata{Name: "a"}},
+				{Metadata: annotatedMetadata{Name: "b"}},
+			},
+			path.New("json", 1, "metadata", "annotations", ProvenanceSourceAnnotation),
+			nil,
+		},
+		{
+			types.Config{
+				Storage: types.Storage{
+					Files: []types.File{
+						{Node: types.Node{Path: ProvenancePath}},
+					},
+				},
+			},
+			path.ContextPath{},
+			common.ErrProvenanceFileExists,
+		},
+		{
+			unannotated{},
+			path.ContextPath{},
+			common.ErrProvenanceUnsupported,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("provenance %d", i), func(t *testing.T) {
+			out, translations, err := embedProvenance(test.in, prov)
+			assert.Equal(t, test.err, err, "bad error")
+			if err != nil {
+				return
+			}
+			assert.Equal(t, path.New("yaml"), translations.Set[test.translated.String()].From, "bad translation")
+			if objects, ok := out.([]annotated); ok {
+				// ReadProvenance reads a single object
+				out = objects[len(objects)-1]
+			}
+			serialized, err := json.Marshal(out)
+			if !assert.NoError(t, err, "marshaling config") {
+				return
+			}
+			actual, err := ReadProvenance(serialized)
+			assert.NoError(t, err, "reading provenance")
+			assert.Equal(t, prov, actual, "bad provenance")
+		})
+	}
+
+	_, err := ReadProvenance([]byte(`{"ignition":{"version":"3.7.0-experimental"}}`))
+	assert.Equal(t, ErrNoProvenance, err, "bad error for missing provenance")
+}
+
+func TestVerifyProvenance(t *testing.T) {
+	filesDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(filesDir, "dir"), 0755); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(filesDir, "dir", "file"), []byte("changed"), 0644); err != nil {
+		t.Error(err)
+		return
+	}
+	prov := common.Provenance{
+		SourceSha256: Sha256([]byte("source")),
+		Files: map[string]string{
+			"dir/file": Sha256([]byte("contents")),
+		},
+	}
+
+	assert.Equal(t, []string{
+		fmt.Sprintf("dir/file: expected sha256 %s, found %s", Sha256([]byte("contents")), Sha256([]byte("changed"))),
+	}, VerifyProvenance(prov, []byte("source"), filesDir))
+
+	prov.Files["dir/file"] = Sha256([]byte("changed"))
+	assert.Empty(t, VerifyProvenance(prov, []byte("source"), filesDir))
+	assert.Len(t, VerifyProvenance(prov, []byte("other"), filesDir), 1)
+}
@@ -71,6 +71,9 @@ func Translate(cfg Config, translateMethod string, options common.TranslateOptio
 	if r.IsFatal() {
 		return zeroValue, r, common.ErrInvalidSourceConfig
 	}
+	if options.Translations != nil {
+		*options.Translations = translations
+	}
 	if options.DebugPrintTranslations {
 		fmt.Fprint(os.Stderr, translations)
 		if err := translations.DebugVerifyCoverage(final); err != nil {
@@ -127,6 +130,11 @@ func TranslateBytes(input []byte, container interface{}, translateMethod string,
 		return nil, r, common.ErrInvalidSourceConfig
 	}
 
+	// Record the local files read during translation.
+	if options.Provenance != nil && options.LocalFiles == nil {
+		options.LocalFiles = map[string]string{}
+	}
+
 	// Perform the translation.
 	translateRet := reflect.ValueOf(cfg).MethodByName(translateMethod).Call([]reflect.Value{reflect.ValueOf(options.TranslateOptions)})
 	final := translateRet[0].Interface()
@@ -141,8 +149,34 @@ func TranslateBytes(input []byte, container interface{}, translateMethod string,
 		return nil, r, common.ErrInvalidSourceConfig
 	}
 
+	// Embed provenance metadata.
+	if options.Provenance != nil {
+		prov := *options.Provenance
+		prov.SourceSha256 = Sha256(input)
+		if len(options.LocalFiles) > 0 {
+			prov.Files = options.LocalFiles
+		}
+		var provTranslations translate.TranslationSet
+		if final, provTranslations, err = embedProvenance(final, prov); err != nil {
+			return nil, r, err
+		}
+		if options.Translations != nil {
+			// keep the existing translations of parent fields
+			for key, t := range provTranslations.Set {
+				if _, ok := options.Translations.Set[key]; !ok {
+					options.Translations.AddTranslation(t.From, t.To)
+				}
+			}
+		}
+	}
+
+	// Sort unordered lists for stable output.
+	if options.Canonical {
+		final = Canonicalize(final)
+	}
+
 	// Marshal the JSON.
-	outbytes, err := marshal(final, options.Pretty)
+	outbytes, err := marshal(final, options.Pretty || options.Canonical)
 	return outbytes, r, err
 }
 
@@ -160,12 +194,20 @@ func TranslateBytesYAML(input []byte, container interface{}, translateMethod str
 		return []byte{}, r, err
 	}
 
+	// a list of objects is written as a multi-document stream
+	docs, ok := ifaceCfg.([]interface{})
+	if !ok {
+		docs = []interface{}{ifaceCfg}
+	}
+
 	var yamlCfgBuf bytes.Buffer
-	yamlCfgBuf.WriteString("# Generated by Butane; do not edit\n")
+	yamlCfgBuf.WriteString(GeneratedHeader)
 	encoder := yaml.NewEncoder(&yamlCfgBuf)
 	encoder.SetIndent(2)
-	if err := encoder.Encode(ifaceCfg); err != nil {
-		return []byte{}, r, err
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return []byte{}, r, err
+		}
 	}
 	if err := encoder.Close(); err != nil {
 		return []byte{}, r, err
@@ -0,0 +1,796 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+// Package diff compares the Ignition configs produced from two Butane
+// configs and describes the differences in terms of the resulting node.
+package diff
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/coreos/butane/config"
+	"github.com/coreos/butane/config/common"
+	cutil "github.com/coreos/butane/config/util"
+	"github.com/coreos/butane/translate"
+
+	"github.com/clarketm/json"
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	"github.com/coreos/vcontext/tree"
+	vyaml "github.com/coreos/vcontext/yaml"
+	"github.com/vincent-petithory/dataurl"
+)
+
+type Kind int
+
+const (
+	Added Kind = iota
+	Removed
+	Modified
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// Location is the position in a Butane config that produced part of the
+// output.  Line is zero if the position isn't known.
+type Location struct {
+	File string
+	Path path.ContextPath
+	Line int64
+}
+
+func (l Location) String() string {
+	if l.Line == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", l.File, l.Line)
+}
+
+// Change is a single difference between two translated configs.
+type Change struct {
+	Kind Kind
+	// Node describes the affected object on the node, such as
+	// "file /etc/motd", or is empty for a change to a top-level setting.
+	Node string
+	// Field is the changed field of Node in Butane naming, or empty if
+	// the whole node was added or removed.
+	Field string
+	// Detail is a description of the new value, or a line diff of
+	// the contents.
+	Detail string
+	Old    Location
+	New    Location
+	// Disruptive is set for changes to a MachineConfig that the
+	// Machine Config Operator can't apply to an existing node.
+	Disruptive bool
+}
+
+func (c Change) String() string {
+	var b strings.Builder
+	b.WriteString(c.Kind.String())
+	b.WriteString(" ")
+	b.WriteString(c.Node)
+	if c.Field != "" {
+		if c.Node != "" {
+			b.WriteString(": ")
+		}
+		b.WriteString(c.Field)
+	}
+	if c.Detail != "" && !strings.Contains(c.Detail, "\n") {
+		fmt.Fprintf(&b, ": %s", c.Detail)
+	}
+	var locs []string
+	for _, l := range []Location{c.Old, c.New} {
+		if s := l.String(); s != "" {
+			locs = append(locs, s)
+		}
+	}
+	if len(locs) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(locs, " → "))
+	}
+	if strings.Contains(c.Detail, "\n") {
+		for _, line := range strings.Split(strings.TrimSuffix(c.Detail, "\n"), "\n") {
+			fmt.Fprintf(&b, "\n    %s", line)
+		}
+	}
+	if c.Disruptive {
+		b.WriteString("\n    ! the Machine Config Operator can't apply this change to an existing node")
+	}
+	return b.String()
+}
+
+// Config is a translated Butane config, along with the information
+// needed to map its output back to the source.
+type Config struct {
+	name   string
+	source tree.Node
+	docs   []*document
+}
+
+// document is a single object in the output of a Config.
+type document struct {
+	config       *Config
+	translations translate.TranslationSet
+	output       map[string]interface{}
+}
+
+// Translate translates the Butane config in input for comparison.  name
+// is used to refer to the config in change locations.
+func Translate(name string, input []byte, options common.TranslateBytesOptions) (*Config, report.Report, error) {
+	ret := Config{
+		name: name,
+	}
+	var translations translate.TranslationSet
+	options.Raw = false
+	options.Translations = &translations
+	output, r, err := config.TranslateBytes(input, options)
+	if err != nil {
+		return nil, r, err
+	}
+	if ret.source, err = vyaml.UnmarshalToContext(input); err != nil {
+		return nil, r, err
+	}
+	// openshift configs can produce several objects
+	docs, err := cutil.SplitDocuments(output, translations)
+	if err != nil {
+		return nil, r, err
+	}
+	for _, doc := range docs {
+		ret.docs = append(ret.docs, &document{
+			config:       &ret,
+			translations: doc.Translations,
+			output:       doc.Value,
+		})
+	}
+	return &ret, r, nil
+}
+
+// locate maps a path in the output document to its position in the
+// source, falling back to the closest ancestor with a known source.
+func (c *document) locate(p path.ContextPath) Location {
+	for {
+		if t, ok := c.translations.Set[p.String()]; ok {
+			node := c.config.source
+			from := t.From
+			for {
+				if n, err := c.config.source.Get(from); err == nil {
+					node = n
+					break
+				}
+				if from.Len() == 0 {
+					break
+				}
+				from = from.Pop()
+			}
+			line, _ := node.Start()
+			return Location{
+				File: c.config.name,
+				Path: t.From,
+				Line: line,
+			}
+		}
+		if p.Len() == 0 {
+			return Location{File: c.config.name}
+		}
+		p = p.Pop()
+	}
+}
+
+// kind returns the Kubernetes kind of the document, or the empty string
+// for an Ignition config.
+func (c *document) kind() string {
+	kind, _ := c.output["kind"].(string)
+	return kind
+}
+
+// key identifies the document among the documents of its config.
+func (c *document) key() string {
+	metadata, _ := c.output["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	return strings.TrimSpace(c.kind() + " " + name)
+}
+
+// isMachineConfig reports whether the output is a MachineConfig wrapping
+// the Ignition config.
+func (c *document) isMachineConfig() bool {
+	return c.kind() == "MachineConfig"
+}
+
+// ignitionRoot returns the path of the Ignition config in the output.
+func (c *document) ignitionRoot() path.ContextPath {
+	if c.isMachineConfig() {
+		return path.New("json", "spec", "config")
+	}
+	return path.New("json")
+}
+
+// nodeLists are the Ignition config lists whose entries are reported as
+// individual objects on the node, in reporting order.  Entries are
+// matched using the keys in cutil.UnorderedLists.
+var nodeLists = []struct {
+	list string
+	noun string
+}{
+	{"storage.disks", "disk"},
+	{"storage.raid", "RAID array"},
+	{"storage.luks", "LUKS volume"},
+	{"storage.filesystems", "filesystem"},
+	{"storage.directories", "directory"},
+	{"storage.files", "file"},
+	{"storage.links", "link"},
+	{"systemd.units", "unit"},
+	{"passwd.groups", "group"},
+	{"passwd.users", "user"},
+}
+
+// nestedLists maps lists within node entries to the field identifying
+// each entry.  Partitions can omit the label, so number is used as a
+// fallback.
+var nestedLists = map[string][]string{
+	"dropins":    {"name"},
+	"partitions": {"label", "number"},
+}
+
+type differ struct {
+	old, new *document
+	changes  []Change
+	// whether the MCO can apply changes to the current node
+	reconcilable func(field string) bool
+}
+
+// Compare returns the differences between the output of old and new.
+// Documents are matched by kind and name, and their changes are reported
+// in the order of the documents in new, followed by removed documents.
+// Within a document, changes are ordered by node type and then by node
+// name.
+func Compare(old, new *Config) []Change {
+	// a config producing a single document is compared with its
+	// replacement even if the kind or name changed
+	if len(old.docs) == 1 && len(new.docs) == 1 {
+		return compareDocuments(old.docs[0], new.docs[0])
+	}
+	var changes []Change
+	oldDocs := map[string]*document{}
+	for _, doc := range old.docs {
+		oldDocs[doc.key()] = doc
+	}
+	seen := map[string]bool{}
+	for _, doc := range new.docs {
+		seen[doc.key()] = true
+		if o, ok := oldDocs[doc.key()]; ok {
+			changes = append(changes, compareDocuments(o, doc)...)
+		} else {
+			changes = append(changes, Change{
+				Kind: Added,
+				Node: doc.key(),
+				New:  doc.locate(path.New("json", "metadata", "name")),
+			})
+		}
+	}
+	for _, doc := range old.docs {
+		if !seen[doc.key()] {
+			changes = append(changes, Change{
+				Kind: Removed,
+				Node: doc.key(),
+				Old:  doc.locate(path.New("json", "metadata", "name")),
+			})
+		}
+	}
+	return changes
+}
+
+// compareDocuments returns the differences between the documents old
+// and new.
+func compareDocuments(old, new *document) []Change {
+	d := differ{
+		old: old,
+		new: new,
+	}
+	if kind := new.kind(); kind != "" && kind != "MachineConfig" && kind == old.kind() {
+		// companion objects such as KubeletConfigs are applied by
+		// the MCO like any other MachineConfig setting
+		d.reconcilable = func(field string) bool {
+			return true
+		}
+		skip := map[string]bool{
+			"apiVersion": true,
+			"kind":       true,
+		}
+		d.compareSettings(old.output, new.output, path.New("json"), path.New("json"), skip, "")
+		return d.changes
+	}
+	oldRoot := old.ignitionRoot()
+	newRoot := new.ignitionRoot()
+	oldIgn := lookup(old.output, oldRoot)
+	newIgn := lookup(new.output, newRoot)
+	checkMCO := new.isMachineConfig()
+
+	skip := map[string]bool{}
+	for _, l := range nodeLists {
+		skip[l.list] = true
+		listPath := strings.Split(l.list, ".")
+		oldList, _ := lookup(oldIgn, path.New("", toIfaces(listPath)...)).([]interface{})
+		newList, _ := lookup(newIgn, path.New("", toIfaces(listPath)...)).([]interface{})
+		key := cutil.UnorderedLists[l.list]
+		oldEntries := index(oldList, []string{key})
+		newEntries := index(newList, []string{key})
+		for _, name := range sortedKeys(oldEntries, newEntries) {
+			o, oOk := oldEntries[name]
+			n, nOk := newEntries[name]
+			oldPath := oldRoot.Append(toIfaces(listPath)...).Append(o.index)
+			newPath := newRoot.Append(toIfaces(listPath)...).Append(n.index)
+			node := fmt.Sprintf("%s %s", l.noun, name)
+			d.reconcilable = func(field string) bool {
+				return !checkMCO || mcoReconcilable(l.list, name, field)
+			}
+			switch {
+			case !oOk:
+				d.add(Change{
+					Kind:   Added,
+					Node:   node,
+					Detail: summarize(n.value),
+					New:    new.locate(newPath),
+				}, "")
+			case !nOk:
+				d.add(Change{
+					Kind: Removed,
+					Node: node,
+					Old:  old.locate(oldPath),
+				}, "")
+			default:
+				d.compare(node, "", oldPath, newPath, withoutKey(o.value, key), withoutKey(n.value, key))
+			}
+		}
+	}
+
+	// everything else is compared as individual settings
+	d.reconcilable = func(field string) bool {
+		return !checkMCO
+	}
+	d.compareSettings(oldIgn, newIgn, oldRoot, newRoot, skip, "")
+	if checkMCO {
+		d.reconcilable = func(field string) bool {
+			// everything outside the Ignition config except FIPS
+			// is reconcilable, possibly with a reboot
+			return lastField(field) != "fips"
+		}
+		skip := map[string]bool{
+			"apiVersion":  true,
+			"kind":        true,
+			"spec.config": true,
+		}
+		var oldMC map[string]interface{}
+		if old.isMachineConfig() {
+			oldMC = old.output
+		}
+		d.compareSettings(oldMC, new.output, path.New("json"), path.New("json"), skip, "")
+	}
+	return d.changes
+}
+
+// compareSettings recursively compares the maps old and new, skipping
+// dotted paths in skip.
+func (d *differ) compareSettings(old, new interface{}, oldPath, newPath path.ContextPath, skip map[string]bool, prefix string) {
+	oldMap, _ := old.(map[string]interface{})
+	newMap, _ := new.(map[string]interface{})
+	for _, k := range sortedKeys(oldMap, newMap) {
+		p := strings.TrimPrefix(prefix+"."+k, ".")
+		if skip[p] {
+			continue
+		}
+		o := oldMap[k]
+		n := newMap[k]
+		// recurse into sections, including added or removed ones
+		oIsMap := isMap(o) || (o == nil && isMap(n))
+		nIsMap := isMap(n) || (n == nil && isMap(o))
+		if oIsMap && nIsMap && !isResource(o) && !isResource(n) {
+			d.compareSettings(o, n, oldPath.Append(k), newPath.Append(k), skip, p)
+			continue
+		}
+		if reflect.DeepEqual(o, n) {
+			continue
+		}
+		// name the setting after its source
+		loc := d.new.locate(newPath.Append(k))
+		if n == nil {
+			loc = d.old.locate(oldPath.Append(k))
+		}
+		field := snakeDotted(p)
+		if loc.Path.Len() > 0 {
+			field = dotted(loc.Path)
+		}
+		d.compare("", field, oldPath.Append(k), newPath.Append(k), o, n)
+	}
+}
+
+// compare records the differences between old and new, which are values
+// of field in node.
+func (d *differ) compare(node, field string, oldPath, newPath path.ContextPath, old, new interface{}) {
+	if reflect.DeepEqual(old, new) {
+		return
+	}
+	change := Change{
+		Node:  node,
+		Field: field,
+		Old:   d.old.locate(oldPath),
+		New:   d.new.locate(newPath),
+	}
+	switch {
+	case isResource(old) || isResource(new):
+		d.compareResource(change, oldPath, newPath, old, new)
+		return
+	case isMap(old) && isMap(new):
+		oldMap := old.(map[string]interface{})
+		newMap := new.(map[string]interface{})
+		for _, k := range sortedKeys(oldMap, newMap) {
+			d.compare(node, joinField(field, cutil.Snake(k)), oldPath.Append(k), newPath.Append(k), oldMap[k], newMap[k])
+		}
+		return
+	case isList(old) || isList(new):
+		oldList, _ := old.([]interface{})
+		newList, _ := new.([]interface{})
+		if isScalarList(oldList) && isScalarList(newList) {
+			d.compareSet(change, oldPath, newPath, oldList, newList)
+			return
+		}
+		keys := nestedLists[lastField(field)]
+		oldEntries := index(oldList, keys)
+		newEntries := index(newList, keys)
+		for _, name := range sortedKeys(oldEntries, newEntries) {
+			o, oOk := oldEntries[name]
+			n, nOk := newEntries[name]
+			entryField := fmt.Sprintf("%s[%s]", field, name)
+			switch {
+			case !oOk:
+				d.add(Change{
+					Kind:   Added,
+					Node:   node,
+					Field:  entryField,
+					Detail: summarize(n.value),
+					New:    d.new.locate(newPath.Append(n.index)),
+				}, field)
+			case !nOk:
+				d.add(Change{
+					Kind:  Removed,
+					Node:  node,
+					Field: entryField,
+					Old:   d.old.locate(oldPath.Append(o.index)),
+				}, field)
+			default:
+				d.compare(node, entryField, oldPath.Append(o.index), newPath.Append(n.index), o.value, n.value)
+			}
+		}
+		return
+	}
+
+	switch {
+	case old == nil:
+		change.Kind = Added
+		change.Old = Location{}
+	case new == nil:
+		change.Kind = Removed
+		change.New = Location{}
+	default:
+		change.Kind = Modified
+	}
+	oldStr, oOk := old.(string)
+	newStr, nOk := new.(string)
+	if (oOk || old == nil) && (nOk || new == nil) && (strings.Contains(oldStr, "\n") || strings.Contains(newStr, "\n")) {
+		change.Detail = lineDiff(oldStr, newStr)
+	} else {
+		change.Detail = fmt.Sprintf("%s → %s", formatValue(field, old), formatValue(field, new))
+	}
+	d.add(change, field)
+}
+
+// compareResource records the differences between the Ignition resources
+// old and new, either of which might be nil.  The contents of data URLs
+// are compared directly.
+func (d *differ) compareResource(change Change, oldPath, newPath path.ContextPath, old, new interface{}) {
+	oldMap, _ := old.(map[string]interface{})
+	newMap, _ := new.(map[string]interface{})
+	oldData, oOk := decodeResource(oldMap)
+	newData, nOk := decodeResource(newMap)
+	if (oOk || old == nil) && (nOk || new == nil) {
+		if !bytes.Equal(oldData, newData) {
+			change.Kind = Modified
+			if utf8.Valid(oldData) && utf8.Valid(newData) {
+				change.Detail = lineDiff(string(oldData), string(newData))
+			} else {
+				change.Detail = fmt.Sprintf("binary contents changed (%d → %d bytes)", len(oldData), len(newData))
+			}
+			d.add(change, change.Field)
+		}
+		// the encoding of inline contents isn't interesting, but
+		// everything else is
+		oldMap = withoutKey(withoutKey(oldMap, "source"), "compression")
+		newMap = withoutKey(withoutKey(newMap, "source"), "compression")
+	}
+	for _, k := range sortedKeys(oldMap, newMap) {
+		d.compare(change.Node, joinField(change.Field, cutil.Snake(k)), oldPath.Append(k), newPath.Append(k), oldMap[k], newMap[k])
+	}
+}
+
+// compareSet records the values added to and removed from a list of
+// scalars, such as SSH keys or kernel arguments.
+func (d *differ) compareSet(change Change, oldPath, newPath path.ContextPath, old, new []interface{}) {
+	oldIndex := map[string]int{}
+	newIndex := map[string]int{}
+	for i, v := range old {
+		oldIndex[formatValue(change.Field, v)] = i
+	}
+	for i, v := range new {
+		newIndex[formatValue(change.Field, v)] = i
+	}
+	for _, v := range sortedKeys(oldIndex, newIndex) {
+		o, oOk := oldIndex[v]
+		n, nOk := newIndex[v]
+		c := change
+		c.Detail = v
+		switch {
+		case !oOk:
+			c.Kind = Added
+			c.Old = Location{}
+			c.New = d.new.locate(newPath.Append(n))
+		case !nOk:
+			c.Kind = Removed
+			c.Old = d.old.locate(oldPath.Append(o))
+			c.New = Location{}
+		default:
+			continue
+		}
+		d.add(c, change.Field)
+	}
+}
+
+func (d *differ) add(change Change, field string) {
+	change.Disruptive = !d.reconcilable(field)
+	d.changes = append(d.changes, change)
+}
+
+// mcoReconcilable reports whether the Machine Config Operator can apply
+// a change to field of the entry named name in the Ignition config list.
+// The MCO refuses to modify storage other than files, and users other
+// than the SSH keys and password of the core user.
+func mcoReconcilable(list, name, field string) bool {
+	switch list {
+	case "storage.files", "systemd.units":
+		return true
+	case "passwd.users":
+		return name == "core" && (field == "ssh_authorized_keys" || field == "password_hash")
+	}
+	return false
+}
+
+type entry struct {
+	index int
+	value map[string]interface{}
+}
+
+// index maps the entries of list to the value of the first nonempty
+// field in keys, falling back to the list index.
+func index(list []interface{}, keys []string) map[string]entry {
+	ret := map[string]entry{}
+	for i, v := range list {
+		m, _ := v.(map[string]interface{})
+		name := fmt.Sprint(i)
+		for _, key := range keys {
+			if k, ok := m[key]; ok && k != nil {
+				name = fmt.Sprint(k)
+				break
+			}
+		}
+		ret[name] = entry{i, m}
+	}
+	return ret
+}
+
+// lookup returns the value at p in the generic value v, or nil.
+func lookup(v interface{}, p path.ContextPath) interface{} {
+	for _, e := range p.Path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[e.(string)]
+	}
+	return v
+}
+
+// decodeResource returns the contents of a resource with a data URL
+// source.
+func decodeResource(res map[string]interface{}) ([]byte, bool) {
+	source, _ := res["source"].(string)
+	if !strings.HasPrefix(source, "data:") {
+		return nil, false
+	}
+	url, err := dataurl.DecodeString(source)
+	if err != nil {
+		return nil, false
+	}
+	if compression, _ := res["compression"].(string); compression == "gzip" {
+		reader, err := gzip.NewReader(bytes.NewReader(url.Data))
+		if err != nil {
+			return nil, false
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, false
+		}
+		return data, true
+	}
+	return url.Data, true
+}
+
+// summarize returns a short description of a new node.
+func summarize(v map[string]interface{}) string {
+	var ret []string
+	if enabled, ok := v["enabled"].(bool); ok {
+		if enabled {
+			ret = append(ret, "enabled")
+		} else {
+			ret = append(ret, "disabled")
+		}
+	}
+	if mask, _ := v["mask"].(bool); mask {
+		ret = append(ret, "masked")
+	}
+	if keys, _ := v["sshAuthorizedKeys"].([]interface{}); len(keys) > 0 {
+		ret = append(ret, fmt.Sprintf("%d SSH keys", len(keys)))
+	}
+	if target, ok := v["target"].(string); ok {
+		ret = append(ret, "→ "+target)
+	}
+	return strings.Join(ret, ", ")
+}
+
+// formatValue formats a scalar for display.  File modes are shown in
+// octal, as they're written in Butane configs.
+func formatValue(field string, v interface{}) string {
+	if v == nil {
+		return "(unset)"
+	}
+	if lastField(field) == "mode" {
+		if mode, ok := toInt(v); ok {
+			return fmt.Sprintf("%04o", mode)
+		}
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(out)
+}
+
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+func isResource(v interface{}) bool {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = m["source"]
+	return ok
+}
+
+func isMap(v interface{}) bool {
+	_, ok := v.(map[string]interface{})
+	return ok
+}
+
+func isList(v interface{}) bool {
+	_, ok := v.([]interface{})
+	return ok
+}
+
+func isScalarList(l []interface{}) bool {
+	for _, v := range l {
+		if isMap(v) || isList(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func withoutKey(m map[string]interface{}, key string) map[string]interface{} {
+	ret := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if k != key {
+			ret[k] = v
+		}
+	}
+	return ret
+}
+
+func sortedKeys[V1, V2 any](a map[string]V1, b map[string]V2) []string {
+	var ret []string
+	for k := range a {
+		ret = append(ret, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			ret = append(ret, k)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func joinField(field, child string) string {
+	if field == "" {
+		return child
+	}
+	return field + "." + child
+}
+
+// lastField returns the last element of a dotted field name, without
+// any list key.
+func lastField(field string) string {
+	field = field[strings.LastIndex(field, ".")+1:]
+	if i := strings.Index(field, "["); i >= 0 {
+		field = field[:i]
+	}
+	return field
+}
+
+func snakeDotted(p string) string {
+	parts := strings.Split(p, ".")
+	for i := range parts {
+		parts[i] = cutil.Snake(parts[i])
+	}
+	return strings.Join(parts, ".")
+}
+
+func dotted(p path.ContextPath) string {
+	var parts []string
+	for _, e := range p.Path {
+		parts = append(parts, fmt.Sprint(e))
+	}
+	return strings.Join(parts, ".")
+}
+
+func toIfaces(s []string) []interface{} {
+	ret := make([]interface{}, len(s))
+	for i, v := range s {
+		ret[i] = v
+	}
+	return ret
+}
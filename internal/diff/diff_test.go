@@ -0,0 +1,250 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package diff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/coreos/butane/config/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// summary is a Change without the source paths, for comparison
+type summary struct {
+	Kind       Kind
+	Node       string
+	Field      string
+	Detail     string
+	OldLine    int64
+	NewLine    int64
+	Disruptive bool
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		old string
+		new string
+		out []summary
+	}{
+		// no changes
+		{
+			`variant: fcos
+version: 1.6.0
+storage:
+  files:
+    - path: /etc/motd
+      contents:
+        inline: hi`,
+			`variant: fcos
+version: 1.6.0
+storage:
+  files:
+    - path: /etc/motd
+      contents:
+        inline: hi`,
+			nil,
+		},
+		// files, units, users, partitions
+		{
+			`variant: fcos
+version: 1.6.0
+passwd:
+  users:
+    - name: core
+      ssh_authorized_keys:
+        - key1
+    - name: bob
+storage:
+  disks:
+    - device: /dev/vdb
+      wipe_table: true
+      partitions:
+        - label: data
+          size_mib: 1000
+  files:
+    - path: /etc/motd
+      mode: 0644
+      contents:
+        inline: |
+          hello
+          world
+systemd:
+  units:
+    - name: foo.service
+      enabled: true`,
+			`variant: fcos
+version: 1.6.0
+passwd:
+  users:
+    - name: core
+      ssh_authorized_keys:
+        - key2
+storage:
+  disks:
+    - device: /dev/vdb
+      wipe_table: true
+      partitions:
+        - label: data
+          size_mib: 2000
+        - label: more
+  files:
+    - path: /etc/motd
+      mode: 0600
+      contents:
+        inline: |
+          hello
+          there
+          world
+    - path: /etc/new
+systemd:
+  units:
+    - name: foo.service
+      enabled: false`,
+			[]summary{
+				{Modified, "disk /dev/vdb", "partitions[data].size_mib", "1000 → 2000", 15, 14, false},
+				{Added, "disk /dev/vdb", "partitions[more]", "", 0, 15, false},
+				{Modified, "file /etc/motd", "contents", " hello\n+there\n world\n", 20, 20, false},
+				{Modified, "file /etc/motd", "mode", "0644 → 0600", 18, 18, false},
+				{Added, "file /etc/new", "", "", 0, 24, false},
+				{Modified, "unit foo.service", "enabled", "true → false", 26, 28, false},
+				{Removed, "user bob", "", "", 8, 0, false},
+				{Removed, "user core", "ssh_authorized_keys", "key1", 7, 0, false},
+				{Added, "user core", "ssh_authorized_keys", "key2", 0, 7, false},
+			},
+		},
+		// MachineConfig changes
+		{
+			`variant: openshift
+version: 4.23.0-experimental
+metadata:
+  name: 99-worker-x
+  labels:
+    machineconfiguration.openshift.io/role: worker
+passwd:
+  users:
+    - name: core
+      ssh_authorized_keys:
+        - key1
+openshift:
+  kernel_arguments:
+    - a=1`,
+			`variant: openshift
+version: 4.23.0-experimental
+metadata:
+  name: 99-worker-x
+  labels:
+    machineconfiguration.openshift.io/role: worker
+passwd:
+  users:
+    - name: core
+      ssh_authorized_keys:
+        - key1
+      password_hash: hash
+storage:
+  disks:
+    - device: /dev/vdb
+      wipe_table: true
+openshift:
+  fips: true
+  kernel_arguments:
+    - a=1`,
+			[]summary{
+				{Added, "disk /dev/vdb", "", "", 0, 15, true},
+				{Added, "user core", "password_hash", "(unset) → hash", 0, 12, false},
+				{Added, "", "openshift.fips", "(unset) → true", 0, 18, true},
+			},
+		},
+		// MachineConfigs with companion objects
+		{
+			`variant: openshift
+version: 4.23.0-experimental
+metadata:
+  name: 99-worker-x
+  labels:
+    machineconfiguration.openshift.io/role: worker
+openshift:
+  kubelet:
+    max_pods: 100
+  container_runtime:
+    log_level: info`,
+			`variant: openshift
+version: 4.23.0-experimental
+metadata:
+  name: 99-worker-x
+  labels:
+    machineconfiguration.openshift.io/role: worker
+openshift:
+  kernel_arguments:
+    - a=1
+  kubelet:
+    max_pods: 200`,
+			[]summary{
+				{Added, "", "openshift.kernel_arguments", "a=1", 0, 9, false},
+				{Modified, "", "openshift.kubelet.max_pods", "100 → 200", 9, 11, false},
+				{Removed, "ContainerRuntimeConfig 99-worker-x-container-runtime", "", "", 4, 0, false},
+			},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("compare %d", i), func(t *testing.T) {
+			old, _, err := Translate("old.bu", []byte(test.old), common.TranslateBytesOptions{})
+			if !assert.NoError(t, err, "translating old config") {
+				return
+			}
+			new, _, err := Translate("new.bu", []byte(test.new), common.TranslateBytesOptions{})
+			if !assert.NoError(t, err, "translating new config") {
+				return
+			}
+			var actual []summary
+			for _, c := range Compare(old, new) {
+				actual = append(actual, summary{c.Kind, c.Node, c.Field, c.Detail, c.Old.Line, c.New.Line, c.Disruptive})
+			}
+			assert.Equal(t, test.out, actual, "bad changes")
+		})
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		old string
+		new string
+		out string
+	}{
+		{
+			"",
+			"a\n",
+			"+a\n",
+		},
+		{
+			"a\nb\n",
+			"a\nc\n",
+			" a\n-b\n+c\n",
+		},
+		{
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			"0\n1\n2\n3\n4\n5\n6\n7\n8\n",
+			"+0\n 1\n 2\n...\n 7\n 8\n-9\n",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("diff %d", i), func(t *testing.T) {
+			assert.Equal(t, test.out, lineDiff(test.old, test.new))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package diff
+
+import (
+	"strings"
+)
+
+// number of unchanged lines shown around each change
+const diffContext = 2
+
+// lineDiff returns a line-oriented diff of old and new, with removed
+// lines prefixed by "-", added lines by "+", and unchanged context lines
+// by " ".  Runs of unchanged lines away from any change are elided.
+func lineDiff(old, new string) string {
+	a := splitLines(old)
+	b := splitLines(new)
+
+	// longest common subsequence, computed from the end so the diff
+	// can be emitted from the start
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, " "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-"+a[i])
+			i++
+		default:
+			lines = append(lines, "+"+b[j])
+			j++
+		}
+	}
+
+	// keep context lines near a change
+	keep := make([]bool, len(lines))
+	for n, line := range lines {
+		if line[0] != ' ' {
+			for k := max(0, n-diffContext); k <= min(len(lines)-1, n+diffContext); k++ {
+				keep[k] = true
+			}
+		}
+	}
+	var b2 strings.Builder
+	elided := false
+	for n, line := range lines {
+		if !keep[n] {
+			if !elided {
+				b2.WriteString("...\n")
+				elided = true
+			}
+			continue
+		}
+		elided = false
+		b2.WriteString(line)
+		b2.WriteString("\n")
+	}
+	return b2.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
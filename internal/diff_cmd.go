@@ -0,0 +1,75 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/coreos/butane/config/common"
+	"github.com/coreos/butane/internal/diff"
+)
+
+func diffMain(args []string) {
+	var helpFlag bool
+	var variantDir string
+	options := common.TranslateBytesOptions{}
+	flags := pflag.NewFlagSet("diff", pflag.ExitOnError)
+	flags.BoolVarP(&helpFlag, "help", "h", false, "show usage and exit")
+	flags.StringVarP(&options.FilesDir, "files-dir", "d", "", "allow embedding local files from this directory")
+	flags.StringVar(&variantDir, "variant-dir", "", "load custom variant definitions from this directory")
+	flags.StringVar(&options.PluginPath, "plugin-path", os.Getenv("BUTANE_PLUGIN_PATH"), "search these directories for translator plugins for unknown variants")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s diff [options] old-file new-file\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Options:\n")
+		flags.PrintDefaults()
+	}
+	// ExitOnError
+	_ = flags.Parse(args)
+
+	if helpFlag {
+		flags.SetOutput(os.Stdout)
+		flags.Usage()
+		os.Exit(0)
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	loadVariants(variantDir)
+
+	var configs [2]*diff.Config
+	for i, name := range flags.Args() {
+		dataIn, err := os.ReadFile(name)
+		if err != nil {
+			fail("failed to read %s: %v\n", name, err)
+		}
+		cfg, r, err := diff.Translate(name, dataIn, options)
+		if len(r.Entries) > 0 {
+			fmt.Fprintf(os.Stderr, "%s:\n%s", name, r.String())
+		}
+		if err != nil {
+			fail("Error translating %s: %v\n", name, err)
+		}
+		configs[i] = cfg
+	}
+
+	for _, change := range diff.Compare(configs[0], configs[1]) {
+		fmt.Println(change)
+	}
+}
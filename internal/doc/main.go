@@ -19,6 +19,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -28,6 +29,7 @@ import (
 	"github.com/coreos/go-semver/semver"
 	"github.com/coreos/ignition/v2/config/doc"
 	"github.com/coreos/ignition/v2/config/util"
+	"github.com/spf13/pflag"
 	"gopkg.in/yaml.v3"
 
 	"github.com/coreos/butane/config"
@@ -80,11 +82,25 @@ var (
 )
 
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <directory>\n", os.Args[0])
+	var variantDir string
+	pflag.StringVar(&variantDir, "variant-dir", "", "also document the custom variants defined in this directory")
+	pflag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [--variant-dir <variant-directory>] <directory>\n", os.Args[0])
+	}
+	pflag.Parse()
+	if pflag.NArg() != 1 {
+		pflag.Usage()
 		os.Exit(1)
 	}
-	if err := generate(os.Args[1]); err != nil {
+	var customs []config.VariantDefinition
+	if variantDir != "" {
+		var err error
+		if customs, err = config.LoadVariantDir(variantDir); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+	}
+	if err := generate(pflag.Arg(0), customs); err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", err)
 		os.Exit(1)
 	}
@@ -101,7 +117,7 @@ type version struct {
 	config  buUtil.Config
 }
 
-func generate(dir string) error {
+func generate(dir string, customs []config.VariantDefinition) error {
 	configs := []variant{
 		// alphabetical order
 		{
@@ -174,10 +190,61 @@ func generate(dir string) error {
 		},
 	}
 
+	// custom variants are documented as their base variant versions,
+	// after the built-in ones
+	customByVariant := make(map[string]*config.VariantDefinition)
+	for i := range customs {
+		def := &customs[i]
+		if err := config.RegisterVariant(*def); err != nil {
+			return fmt.Errorf("registering %s %s: %w", def.Variant, def.Version, err)
+		}
+		baseConfig := findConfig(configs, def.Base.Variant, def.Base.Version)
+		if baseConfig == nil {
+			return fmt.Errorf("%s %s: %w", def.Variant, def.Version, common.ErrVariantBase)
+		}
+		desc := def.Description
+		if desc == "" {
+			desc = def.Variant
+		}
+		configs = append(configs, variant{desc, def.Variant, []version{{def.Version, baseConfig}}})
+		customByVariant[def.Variant+"+"+def.Version] = def
+	}
+
+	comps, err := loadComponents()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for i, variant := range configs {
+		for j, version := range variant.versions {
+			custom := customByVariant[variant.variant+"+"+version.version]
+			versionComps := comps
+			if custom != nil {
+				// the base variant's docs describe its own
+				// variant and version
+				if versionComps, err = loadComponents(); err != nil {
+					return err
+				}
+				describeVariant(versionComps, custom.Variant, custom.Version)
+			}
+			if err := generateOne(dir, versionComps, variant, version, custom, 50*(i+1)-j); err != nil {
+				return fmt.Errorf("generating docs for %s %s: %w", variant.variant, version.version, err)
+			}
+		}
+	}
+	return nil
+}
+
+// loadComponents parses the Ignition and Butane doc components.
+func loadComponents() (doc.Components, error) {
 	// parse and snakify Ignition components
 	comps, err := doc.IgnitionComponents()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for name, comp := range comps {
 		snakify(&comp)
@@ -187,20 +254,45 @@ func generate(dir string) error {
 	// parse and merge Butane DocFile
 	butaneComps, err := doc.ParseComponents(bytes.NewBuffer(butaneDocs))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if err := comps.Merge(butaneComps); err != nil {
-		return err
+		return nil, err
 	}
+	return comps, nil
+}
 
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
+// describeVariant replaces the variant-specific transforms of the variant
+// and version fields in comps, so they document the specified variant and
+// version.
+func describeVariant(comps doc.Components, variantName, ver string) {
+	replacements := map[string]doc.Transform{
+		"variant": {Regex: "%VARIANT%", Replacement: escapeReplacement(variantName)},
+		"version": {Regex: "%VERSION%", Replacement: escapeReplacement(ver)},
 	}
+	root := comps[doc.ROOT_COMPONENT]
+	for i := range root.Children {
+		if xfrm, ok := replacements[root.Children[i].Name]; ok {
+			root.Children[i].Transforms = []doc.Transform{xfrm}
+		}
+	}
+}
 
-	for i, variant := range configs {
-		for j, version := range variant.versions {
-			if err := generateOne(dir, comps, variant, version, 50*(i+1)-j); err != nil {
-				return fmt.Errorf("generating docs for %s %s: %w", variant.variant, version.version, err)
+// escapeReplacement escapes s for use as a regexp replacement string.
+func escapeReplacement(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+// findConfig returns the config of the specified built-in variant version,
+// or nil.
+func findConfig(configs []variant, variantName, ver string) buUtil.Config {
+	for _, variant := range configs {
+		if variant.variant != variantName {
+			continue
+		}
+		for _, version := range variant.versions {
+			if version.version == ver {
+				return version.config
 			}
 		}
 	}
@@ -214,7 +306,9 @@ func snakify(node *doc.DocNode) {
 	}
 }
 
-func generateOne(dir string, comps doc.Components, variant variant, version version, navOrder int) error {
+// generateOne writes the docs for a variant version.  custom is the
+// definition of a custom variant, or nil.
+func generateOne(dir string, comps doc.Components, variant variant, version version, custom *config.VariantDefinition, navOrder int) error {
 	ver := *semver.New(version.version)
 
 	// clean up any previous experimental spec doc, for
@@ -255,35 +349,106 @@ func generateOne(dir string, comps doc.Components, variant variant, version vers
 	}
 
 	// write docs
-	ignVer, err := getIgnitionVersion(variant.variant, ver)
+	docVariant, docVer := variant.variant, ver
+	if custom != nil {
+		docVariant, docVer = custom.Base.Variant, *semver.New(custom.Base.Version)
+	}
+	ignVer, err := getIgnitionVersion(docVariant, docVer)
 	if err != nil {
 		return err
 	}
 	vers := doc.VariantVersions{
 		doc.IGNITION_VARIANT: ignVer,
-		variant.variant:      ver,
+		docVariant:           docVer,
 	}
 	ignore := func(path []string) bool {
-		filters := version.config.FieldFilters()
-		if filters == nil {
-			return false
-		}
 		var camelPath []string
 		for _, el := range path {
 			camelPath = append(camelPath, buUtil.Camel(el))
 		}
 		pathStr := strings.Join(camelPath, ".")
-		if variant.variant == "openshift" {
+		if docVariant == "openshift" {
 			pathStr = fmt.Sprintf("spec.config.%s", pathStr)
 		}
+		if custom != nil {
+			for _, rule := range custom.Forbidden {
+				if rule.Path == pathStr {
+					return true
+				}
+			}
+		}
+		filters := version.config.FieldFilters()
+		if filters == nil {
+			return false
+		}
 		return filters.Lookup(pathStr) != nil
 	}
 	if err := comps.Generate(vers, version.config, ignore, f); err != nil {
 		return fmt.Errorf("generating: %w", err)
 	}
+	if err := writeValueRestrictions(f, version.config.FieldFilters(), docVariant); err != nil {
+		return fmt.Errorf("writing value restrictions: %w", err)
+	}
+	if custom != nil {
+		if err := writeRestrictions(f, *custom); err != nil {
+			return fmt.Errorf("writing restrictions: %w", err)
+		}
+	}
 	return nil
 }
 
+// writeValueRestrictions documents the values rejected by the spec's field
+// filters.
+func writeValueRestrictions(w io.Writer, filters *buUtil.FieldFilters, docVariant string) error {
+	if filters == nil {
+		return nil
+	}
+	var lines []string
+	for _, filter := range filters.ValueFilterPaths() {
+		docPath := filter
+		if docVariant == "openshift" {
+			docPath = strings.TrimPrefix(docPath, "spec.config.")
+		}
+		var snakePath []string
+		for _, el := range strings.Split(docPath, ".") {
+			snakePath = append(snakePath, buUtil.Snake(el))
+		}
+		for _, rule := range filters.LookupValues(filter) {
+			lines = append(lines, fmt.Sprintf("* `%s` %s.", strings.Join(snakePath, "."), rule.Allowed.Describe()))
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "\n## Value restrictions\n\nThis spec version rejects some values that the fields above would otherwise accept:\n\n%s\n", strings.Join(lines, "\n"))
+	return err
+}
+
+// writeRestrictions documents the rules of a custom variant that can't be
+// expressed by omitting fields.
+func writeRestrictions(w io.Writer, def config.VariantDefinition) error {
+	var lines []string
+	for _, rule := range def.Required {
+		lines = append(lines, fmt.Sprintf("* `%s` is required%s.", rule.Path, explanation(rule)))
+	}
+	for _, constraint := range def.Constraints {
+		lines = append(lines, fmt.Sprintf("* `%s` %s%s.", constraint.Path, constraint.Predicate().Describe(), explanation(constraint.VariantRule)))
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "\n## Restrictions\n\nThis variant is based on %s %s. Paths refer to fields of the generated config.\n\n%s\n",
+		def.Base.Variant, def.Base.Version, strings.Join(lines, "\n"))
+	return err
+}
+
+func explanation(rule config.VariantRule) string {
+	if rule.Message == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", rule.Message)
+}
+
 func getIgnitionVersion(variant string, version semver.Version) (semver.Version, error) {
 	// generate an empty Butane config with this variant/version
 	// use a random OpenShift spec as a representative structure
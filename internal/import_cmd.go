@@ -0,0 +1,72 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/coreos/butane/internal/mcimport"
+)
+
+func importMachineConfigMain(args []string) {
+	var helpFlag bool
+	var output string
+	flags := pflag.NewFlagSet("import-machineconfig", pflag.ExitOnError)
+	flags.BoolVarP(&helpFlag, "help", "h", false, "show usage and exit")
+	flags.StringVarP(&output, "output", "o", "", "write to output file instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s import-machineconfig [options] input-file\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Options:\n")
+		flags.PrintDefaults()
+	}
+	// ExitOnError
+	_ = flags.Parse(args)
+
+	if helpFlag {
+		flags.SetOutput(os.Stdout)
+		flags.Usage()
+		os.Exit(0)
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	input := flags.Arg(0)
+	dataIn, err := os.ReadFile(input)
+	if err != nil {
+		fail("failed to read %s: %v\n", input, err)
+	}
+	dataOut, r, err := mcimport.Import(dataIn)
+	fmt.Fprintf(os.Stderr, "%s", r.String())
+	if err != nil {
+		fail("Error importing MachineConfig: %v\n", err)
+	}
+
+	outfile := os.Stdout
+	if output != "" {
+		outfile, err = os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			fail("failed to open %s: %v\n", output, err)
+		}
+		defer outfile.Close()
+	}
+	if _, err := outfile.Write(dataOut); err != nil {
+		fail("Failed to write config to %s: %v\n", outfile.Name(), err)
+	}
+}
@@ -0,0 +1,516 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+// Package layout simulates the GPT partitioning that Ignition will perform
+// for the disks in a translated config, so that partitions which overlap
+// or don't fit can be found without provisioning a machine.
+package layout
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	baseutil "github.com/coreos/butane/base/util"
+	fcos "github.com/coreos/butane/config/fcos/v1_8_exp"
+	"github.com/coreos/butane/config/openshift/v4_23_exp/result"
+	cutil "github.com/coreos/butane/config/util"
+	"github.com/coreos/butane/translate"
+
+	"github.com/clarketm/json"
+	"github.com/coreos/ignition/v2/config/util"
+	"github.com/coreos/ignition/v2/config/v3_7_experimental/types"
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	vyaml "github.com/coreos/vcontext/yaml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// BootDisk is the device path of the CoreOS boot disk.
+	BootDisk = "/dev/disk/by-id/coreos-boot-disk"
+
+	// the first MiB holds the protective MBR and the primary GPT, and
+	// the backup GPT occupies the end of the last MiB
+	firstUsableMiB = 1
+)
+
+var (
+	ErrOverlap         = errors.New("partition overlaps partition")
+	ErrNoFit           = errors.New("partition doesn't fit on the disk")
+	ErrNoSpace         = errors.New("no free space for partition")
+	ErrUnknownStart    = errors.New("partition will start after the root partition, whose size in the OS image is unknown; specify start_mib or resize the root partition")
+	ErrMismatch        = errors.New("existing partition doesn't match the config and wipe_partition_entry is false; Ignition will fail")
+	ErrRecreated       = errors.New("existing partition doesn't match the config and will be deleted and recreated")
+	ErrDeleteForbidden = errors.New("existing partition should not exist but wipe_partition_entry is false; Ignition will fail")
+	ErrDeleted         = errors.New("existing partition will be deleted")
+	ErrRenumbered      = errors.New("partition label matches existing partition")
+	ErrBootDiskWiped   = errors.New("wipe_table will erase the OS partitions on the boot disk")
+)
+
+// Status describes what Ignition does to a partition.
+type Status int
+
+const (
+	// existing partition that is left alone
+	Existing Status = iota
+	// existing partition that the OS grows to fill the following free
+	// space on first boot
+	Grown
+	// existing partition resized by the config
+	Resized
+	// partition created by the config
+	Created
+	// existing partition deleted and created again by the config
+	Recreated
+)
+
+func (s Status) String() string {
+	switch s {
+	case Existing:
+		return "existing"
+	case Grown:
+		return "existing, grown"
+	case Resized:
+		return "resized"
+	case Created:
+		return "new"
+	case Recreated:
+		return "recreated"
+	default:
+		return "unknown"
+	}
+}
+
+// Partition is a partition in the simulated layout of a disk.
+type Partition struct {
+	Number   int
+	Label    string
+	StartMiB int
+	// zero if the size is unknown, in which case only the start of the
+	// partition is known to be allocated
+	SizeMiB int
+	Status  Status
+}
+
+func (p Partition) endMiB() int {
+	return p.StartMiB + p.SizeMiB
+}
+
+// Disk is the simulated layout of a disk.
+type Disk struct {
+	Device string
+	// zero if the size of the disk wasn't specified, in which case
+	// the layout isn't simulated
+	SizeMiB int
+	// ordered by start
+	Partitions []Partition
+}
+
+func (d Disk) lastUsableMiB() int {
+	return d.SizeMiB - 1
+}
+
+// Check simulates the partitioning of the disks in output, the Ignition
+// config or MachineConfigs translated from the Butane config input with
+// the translations ts.  sizes maps device paths to disk sizes in MiB;
+// disks without a size are listed but not simulated.  It returns the
+// resulting layouts and a report of problems, with paths and markers
+// relative to input.
+func Check(input, output []byte, ts translate.TranslationSet, sizes map[string]int) ([]Disk, report.Report, error) {
+	var source struct {
+		Variant    string `yaml:"variant"`
+		BootDevice struct {
+			Layout string `yaml:"layout"`
+		} `yaml:"boot_device"`
+	}
+	if err := yaml.Unmarshal(input, &source); err != nil {
+		return nil, report.Report{}, err
+	}
+	// only CoreOS variants have the OS partitions on the boot disk
+	var bootLayout []fcos.BootDiskPartition
+	if source.Variant == "fcos" || source.Variant == "openshift" {
+		bootLayout, _ = fcos.BootDiskLayout(source.BootDevice.Layout)
+	}
+	docs, err := cutil.SplitDocuments(output, ts)
+	if err != nil {
+		return nil, report.Report{}, err
+	}
+
+	var r report.Report
+	var ret []Disk
+	for _, doc := range docs {
+		ign, root, err := ignitionConfig(doc.Value)
+		if err != nil {
+			return nil, report.Report{}, err
+		}
+		if ign == nil {
+			continue
+		}
+		var dr report.Report
+		disksPath := root.Append("storage", "disks")
+		for i, d := range ign.Storage.Disks {
+			disk := Disk{
+				Device:  d.Device,
+				SizeMiB: sizes[d.Device],
+			}
+			if disk.SizeMiB > 0 {
+				disk.simulate(d, disksPath.Append(i), bootLayout, &dr)
+			}
+			ret = append(ret, disk)
+		}
+		r.Merge(cutil.TranslateReportPaths(dr, doc.Translations))
+	}
+
+	contextTree, err := vyaml.UnmarshalToContext(input)
+	if err != nil {
+		return nil, r, err
+	}
+	r.Correlate(contextTree)
+	return ret, r, nil
+}
+
+// ignitionConfig decodes the Ignition config in the output document doc,
+// and returns it along with its path.  It returns a nil config if the
+// document doesn't contain one.
+func ignitionConfig(doc map[string]interface{}) (*types.Config, path.ContextPath, error) {
+	// the document was decoded from YAML, which is a superset of JSON
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, path.ContextPath{}, err
+	}
+	switch doc["kind"] {
+	case nil:
+		var ign types.Config
+		if err := json.Unmarshal(raw, &ign); err != nil {
+			return nil, path.ContextPath{}, err
+		}
+		return &ign, path.New("json"), nil
+	case "MachineConfig":
+		var mc result.MachineConfig
+		if err := json.Unmarshal(raw, &mc); err != nil {
+			return nil, path.ContextPath{}, err
+		}
+		return &mc.Spec.Config, path.New("json", "spec", "config"), nil
+	default:
+		// companion objects such as KubeletConfigs
+		return nil, path.ContextPath{}, nil
+	}
+}
+
+// simulate applies the partitioning of the Ignition disk at path p.
+// bootLayout lists the OS partitions on the boot disk, if any.
+func (d *Disk) simulate(disk types.Disk, p path.ContextPath, bootLayout []fcos.BootDiskPartition, r *report.Report) {
+	wipeTable := util.IsTrue(disk.WipeTable)
+	// existing partitions that the OS grows on first boot, and whose
+	// actual size is therefore unknown
+	grows := map[int]bool{}
+	if d.Device == BootDisk && bootLayout != nil {
+		if wipeTable {
+			r.AddOnWarn(p.Append("wipeTable"), ErrBootDiskWiped)
+		} else {
+			start := firstUsableMiB
+			for _, part := range bootLayout {
+				d.Partitions = append(d.Partitions, Partition{
+					Number:   part.Number,
+					Label:    part.Label,
+					StartMiB: start,
+					SizeMiB:  part.SizeMiB,
+				})
+				grows[part.Number] = part.Grows
+				start += part.SizeMiB
+			}
+		}
+	}
+	// grown partitions that the config leaves alone
+	unchanged := map[int]bool{}
+	for number, grown := range grows {
+		unchanged[number] = grown
+	}
+
+	for i, part := range disk.Partitions {
+		pp := p.Append("partitions", i)
+		number := part.Number
+		label := stringValue(part.Label)
+		startMiB := intValue(part.StartMiB)
+		sizeMiB := intValue(part.SizeMiB)
+		shouldExist := !util.IsFalse(part.ShouldExist)
+		resize := util.IsTrue(part.Resize)
+		wipeEntry := util.IsTrue(part.WipePartitionEntry)
+		delete(unchanged, number)
+
+		if existing := d.find(number); number != 0 && existing >= 0 {
+			old := d.Partitions[existing]
+			if !shouldExist {
+				if wipeEntry {
+					r.AddOnWarn(pp, ErrDeleted)
+					d.remove(existing)
+				} else {
+					r.AddOnError(pp, ErrDeleteForbidden)
+				}
+				continue
+			}
+			resized := resize && sizeMiB != 0 && (sizeMiB != old.SizeMiB || grows[number])
+			sizeMatches := sizeMiB == 0 || (sizeMiB == old.SizeMiB && !grows[number])
+			if (label != "" && label != old.Label) ||
+				(startMiB != 0 && startMiB != old.StartMiB) ||
+				(!sizeMatches && !resized) {
+				if !wipeEntry {
+					r.AddOnError(pp, ErrMismatch)
+					continue
+				}
+				r.AddOnWarn(pp, ErrRecreated)
+				d.remove(existing)
+				d.create(number, label, startMiB, sizeMiB, Recreated, pp, r)
+				continue
+			}
+			if resized {
+				d.remove(existing)
+				if label == "" {
+					label = old.Label
+				}
+				d.create(number, label, old.StartMiB, sizeMiB, Resized, pp, r)
+			}
+			continue
+		}
+		if !shouldExist {
+			continue
+		}
+		if number == 0 {
+			number = d.nextNumber()
+			for _, old := range d.Partitions {
+				if label != "" && old.Label == label && old.Status != Created {
+					r.AddOnWarn(pp.Append("label"), fmt.Errorf("%w %d, but number is unset so partition %d will be created", ErrRenumbered, old.Number, number))
+					break
+				}
+			}
+		}
+		d.create(number, label, startMiB, sizeMiB, Created, pp, r)
+	}
+
+	for number, grown := range unchanged {
+		if i := d.find(number); grown && i >= 0 {
+			part := &d.Partitions[i]
+			end := d.lastUsableMiB()
+			if i+1 < len(d.Partitions) {
+				end = d.Partitions[i+1].StartMiB
+			}
+			if end > part.endMiB() {
+				part.SizeMiB = end - part.StartMiB
+				part.Status = Grown
+			}
+		}
+	}
+}
+
+// create adds a partition, choosing its position the way sgdisk does when
+// the start or size are zero, and reports if it doesn't fit.
+func (d *Disk) create(number int, label string, startMiB, sizeMiB int, status Status, p path.ContextPath, r *report.Report) {
+	// blame the explicit start or size, if any
+	errPath := p
+	if startMiB != 0 {
+		errPath = p.Append("startMiB")
+	} else if sizeMiB != 0 {
+		errPath = p.Append("sizeMiB")
+	}
+	if startMiB == 0 {
+		// sgdisk uses the start of the largest free block
+		start, end := d.largestFree()
+		if end <= start {
+			r.AddOnError(p, ErrNoSpace)
+			return
+		}
+		for _, part := range d.Partitions {
+			if part.SizeMiB == 0 && part.StartMiB == start {
+				// the block actually starts at the unknown end
+				// of the partition
+				r.AddOnWarn(p, ErrUnknownStart)
+				return
+			}
+		}
+		startMiB = start
+	}
+	if sizeMiB == 0 {
+		// fill the free block containing the start
+		end := d.lastUsableMiB()
+		for _, part := range d.Partitions {
+			if part.StartMiB >= startMiB {
+				end = part.StartMiB
+				break
+			}
+		}
+		sizeMiB = end - startMiB
+		if sizeMiB <= 0 {
+			r.AddOnError(p, ErrNoSpace)
+			return
+		}
+	}
+	part := Partition{
+		Number:   number,
+		Label:    label,
+		StartMiB: startMiB,
+		SizeMiB:  sizeMiB,
+		Status:   status,
+	}
+	if part.StartMiB < firstUsableMiB || part.endMiB() > d.lastUsableMiB() {
+		r.AddOnError(errPath, ErrNoFit)
+	}
+	for _, other := range d.Partitions {
+		otherEnd := other.endMiB()
+		if other.SizeMiB == 0 {
+			// only the start is known to be allocated
+			otherEnd = other.StartMiB + 1
+		}
+		if part.StartMiB < otherEnd && other.StartMiB < part.endMiB() {
+			r.AddOnError(errPath, fmt.Errorf("%w %d", ErrOverlap, other.Number))
+		}
+	}
+	d.Partitions = append(d.Partitions, part)
+	sort.SliceStable(d.Partitions, func(i, j int) bool {
+		return d.Partitions[i].StartMiB < d.Partitions[j].StartMiB
+	})
+}
+
+// largestFree returns the bounds of the largest unallocated block,
+// preferring the earliest one.
+func (d *Disk) largestFree() (int, int) {
+	var bestStart, bestEnd int
+	start := firstUsableMiB
+	for _, part := range append(d.Partitions, Partition{StartMiB: d.lastUsableMiB()}) {
+		if part.StartMiB-start > bestEnd-bestStart {
+			bestStart, bestEnd = start, part.StartMiB
+		}
+		if part.endMiB() > start {
+			start = part.endMiB()
+		}
+	}
+	return bestStart, bestEnd
+}
+
+// find returns the index of the partition with the specified number, or
+// -1.
+func (d *Disk) find(number int) int {
+	for i, part := range d.Partitions {
+		if part.Number == number {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *Disk) remove(i int) {
+	d.Partitions = append(d.Partitions[:i], d.Partitions[i+1:]...)
+}
+
+// nextNumber returns the lowest unused partition number.
+func (d *Disk) nextNumber() int {
+	for number := 1; ; number++ {
+		if d.find(number) < 0 {
+			return number
+		}
+	}
+}
+
+// Print writes a table of the simulated layouts to w.
+func Print(w io.Writer, disks []Disk) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for i, d := range disks {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		if d.SizeMiB == 0 {
+			fmt.Fprintf(tw, "%s: size unknown; specify it with --disk-size\n", d.Device)
+			continue
+		}
+		fmt.Fprintf(tw, "%s: %d MiB\n", d.Device, d.SizeMiB)
+		fmt.Fprintln(tw, "  NUMBER\tLABEL\tSTART (MiB)\tSIZE (MiB)\tSTATUS")
+		start := firstUsableMiB
+		// whether the previous partition ends at an unknown offset
+		unknownEnd := false
+		for _, part := range append(d.Partitions, Partition{StartMiB: d.lastUsableMiB()}) {
+			if part.StartMiB > start && !unknownEnd {
+				fmt.Fprintf(tw, "  \t\t%d\t%d\tfree\n", start, part.StartMiB-start)
+			}
+			if part.Number == 0 {
+				break
+			}
+			label := part.Label
+			if label == "" {
+				label = "-"
+			}
+			size := "unknown"
+			if part.SizeMiB > 0 {
+				size = fmt.Sprint(part.SizeMiB)
+			}
+			fmt.Fprintf(tw, "  %d\t%s\t%d\t%s\t%s\n", part.Number, label, part.StartMiB, size, part.Status)
+			unknownEnd = part.SizeMiB == 0
+			if part.endMiB() > start {
+				start = part.endMiB()
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+// ParseSizes parses the DEVICE=SIZE lines of a disk sizes file, ignoring
+// blank lines and comments.
+func ParseSizes(data string) (map[string]int, error) {
+	ret := map[string]int{}
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		device, size, err := ParseSize(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		ret[device] = size
+	}
+	return ret, nil
+}
+
+// ParseSize parses a DEVICE=SIZE disk size specification.
+func ParseSize(spec string) (string, int, error) {
+	device, size, ok := strings.Cut(spec, "=")
+	device = strings.TrimSpace(device)
+	if !ok || device == "" {
+		return "", 0, fmt.Errorf("disk size %q isn't of the form DEVICE=SIZE", spec)
+	}
+	sizeMiB, err := baseutil.ParseSizeMiB(strings.TrimSpace(size))
+	if err != nil {
+		return "", 0, fmt.Errorf("disk size for %s: %w", device, err)
+	}
+	if sizeMiB <= firstUsableMiB {
+		return "", 0, fmt.Errorf("disk size for %s: too small", device)
+	}
+	return device, sizeMiB, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func intValue(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
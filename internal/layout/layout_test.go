@@ -0,0 +1,457 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package layout
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/coreos/butane/config"
+	"github.com/coreos/butane/config/common"
+	"github.com/coreos/butane/translate"
+
+	"github.com/coreos/vcontext/report"
+	"github.com/stretchr/testify/assert"
+)
+
+// entry is a report entry without the path, for comparison
+type entry struct {
+	Kind    report.EntryKind
+	Message string
+	Line    int64
+}
+
+func TestCheck(t *testing.T) {
+	sizes := map[string]int{
+		BootDisk:   20480,
+		"/dev/vdb": 10240,
+	}
+	tests := []struct {
+		in      string
+		disks   []Disk
+		entries []entry
+	}{
+		// separate /var partition on the boot disk
+		{
+			`variant: fcos
+version: 1.8.0-experimental
+storage:
+  disks:
+    - device: /dev/disk/by-id/coreos-boot-disk
+      partitions:
+        - number: 4
+          label: root
+          size_mib: 8192
+          resize: true
+        - label: var`,
+			[]Disk{
+				{
+					Device:  BootDisk,
+					SizeMiB: 20480,
+					Partitions: []Partition{
+						{1, "BIOS-BOOT", 1, 1, Existing},
+						{2, "EFI-SYSTEM", 2, 127, Existing},
+						{3, "boot", 129, 384, Existing},
+						{4, "root", 513, 8192, Resized},
+						{5, "var", 8705, 11774, Created},
+					},
+				},
+			},
+			nil,
+		},
+		// aarch64 layout, root grows to the following partition
+		{
+			`variant: fcos
+version: 1.8.0-experimental
+boot_device:
+  layout: aarch64
+storage:
+  disks:
+    - device: /dev/disk/by-id/coreos-boot-disk
+      partitions:
+        - number: 5
+          label: data
+          start_mib: 12000`,
+			[]Disk{
+				{
+					Device:  BootDisk,
+					SizeMiB: 20480,
+					Partitions: []Partition{
+						{1, "reserved", 1, 1, Existing},
+						{2, "EFI-SYSTEM", 2, 127, Existing},
+						{3, "boot", 129, 384, Existing},
+						{4, "root", 513, 11487, Grown},
+						{5, "data", 12000, 8479, Created},
+					},
+				},
+			},
+			nil,
+		},
+		// partition overlapping the start of the root partition
+		{
+			`variant: fcos
+version: 1.8.0-experimental
+storage:
+  disks:
+    - device: /dev/disk/by-id/coreos-boot-disk
+      partitions:
+        - number: 5
+          label: data
+          start_mib: 257
+          size_mib: 512`,
+			[]Disk{
+				{
+					Device:  BootDisk,
+					SizeMiB: 20480,
+					Partitions: []Partition{
+						{1, "BIOS-BOOT", 1, 1, Existing},
+						{2, "EFI-SYSTEM", 2, 127, Existing},
+						{3, "boot", 129, 384, Existing},
+						{5, "data", 257, 512, Created},
+						{4, "root", 513, 19966, Grown},
+					},
+				},
+			},
+			[]entry{
+				{report.Error, "partition overlaps partition 3", 9},
+				{report.Error, "partition overlaps partition 4", 9},
+			},
+		},
+		// overlapping partitions, partitions that don't fit, and a disk
+		// of unknown size
+		{
+			`variant: fcos
+version: 1.8.0-experimental
+storage:
+  disks:
+    - device: /dev/vdb
+      wipe_table: true
+      partitions:
+        - label: a
+          size_mib: 1024
+        - label: b
+          start_mib: 512
+          size_mib: 1024
+        - label: c
+          size_mib: 100000
+    - device: /dev/vdc
+      wipe_table: true
+      partitions:
+        - label: d`,
+			[]Disk{
+				{
+					Device:  "/dev/vdb",
+					SizeMiB: 10240,
+					Partitions: []Partition{
+						{1, "a", 1, 1024, Created},
+						{2, "b", 512, 1024, Created},
+						{3, "c", 1536, 100000, Created},
+					},
+				},
+				{
+					Device: "/dev/vdc",
+				},
+			},
+			[]entry{
+				{report.Error, "partition overlaps partition 1", 11},
+				{report.Error, "partition doesn't fit on the disk", 14},
+			},
+		},
+		// mismatched, recreated, and renumbered partitions, and a
+		// partition following the root partition of unknown size
+		{
+			`variant: fcos
+version: 1.8.0-experimental
+boot_device:
+  layout: aarch64
+storage:
+  disks:
+    - device: /dev/disk/by-id/coreos-boot-disk
+      partitions:
+        - number: 2
+          size_mib: 200
+        - number: 3
+          label: boot2
+          start_mib: 129
+          size_mib: 384
+          wipe_partition_entry: true
+        - label: reserved
+          size_mib: 1024`,
+			[]Disk{
+				{
+					Device:  BootDisk,
+					SizeMiB: 20480,
+					Partitions: []Partition{
+						{1, "reserved", 1, 1, Existing},
+						{2, "EFI-SYSTEM", 2, 127, Existing},
+						{3, "boot2", 129, 384, Recreated},
+						{4, "root", 513, 19966, Grown},
+					},
+				},
+			},
+			[]entry{
+				{report.Error, ErrMismatch.Error(), 9},
+				{report.Warn, ErrRecreated.Error(), 11},
+				{report.Warn, fmt.Sprintf("%s 1, but number is unset so partition 5 will be created", ErrRenumbered), 16},
+				{report.Warn, ErrUnknownStart.Error(), 16},
+			},
+		},
+		// deleted partitions
+		{
+			`variant: fcos
+version: 1.8.0-experimental
+storage:
+  disks:
+    - device: /dev/disk/by-id/coreos-boot-disk
+      partitions:
+        - number: 1
+          should_exist: false
+          wipe_partition_entry: true
+        - number: 3
+          should_exist: false`,
+			[]Disk{
+				{
+					Device:  BootDisk,
+					SizeMiB: 20480,
+					Partitions: []Partition{
+						{2, "EFI-SYSTEM", 2, 127, Existing},
+						{3, "boot", 129, 384, Existing},
+						{4, "root", 513, 19966, Grown},
+					},
+				},
+			},
+			[]entry{
+				{report.Warn, ErrDeleted.Error(), 7},
+				{report.Error, ErrDeleteForbidden.Error(), 10},
+			},
+		},
+		// wiped boot disk
+		{
+			`variant: fcos
+version: 1.8.0-experimental
+storage:
+  disks:
+    - device: /dev/disk/by-id/coreos-boot-disk
+      wipe_table: true
+      partitions:
+        - number: 1
+          label: a`,
+			[]Disk{
+				{
+					Device:  BootDisk,
+					SizeMiB: 20480,
+					Partitions: []Partition{
+						{1, "a", 1, 20478, Created},
+					},
+				},
+			},
+			[]entry{
+				{report.Warn, ErrBootDiskWiped.Error(), 6},
+			},
+		},
+		// MachineConfig output
+		{
+			`variant: openshift
+version: 4.23.0-experimental
+metadata:
+  name: data
+  labels:
+    machineconfiguration.openshift.io/role: worker
+storage:
+  disks:
+    - device: /dev/vdb
+      wipe_table: true
+      partitions:
+        - label: a
+          start_mib: 9216
+          size_mib: 2048`,
+			[]Disk{
+				{
+					Device:  "/dev/vdb",
+					SizeMiB: 10240,
+					Partitions: []Partition{
+						{1, "a", 9216, 2048, Created},
+					},
+				},
+			},
+			[]entry{
+				{report.Error, ErrNoFit.Error(), 13},
+			},
+		},
+		// MachineConfig with a companion KubeletConfig
+		{
+			`variant: openshift
+version: 4.23.0-experimental
+metadata:
+  name: data
+  labels:
+    machineconfiguration.openshift.io/role: worker
+storage:
+  disks:
+    - device: /dev/vdb
+      wipe_table: true
+      partitions:
+        - label: a
+          start_mib: 9216
+          size_mib: 2048
+openshift:
+  kubelet:
+    max_pods: 100`,
+			[]Disk{
+				{
+					Device:  "/dev/vdb",
+					SizeMiB: 10240,
+					Partitions: []Partition{
+						{1, "a", 9216, 2048, Created},
+					},
+				},
+			},
+			[]entry{
+				{report.Error, ErrNoFit.Error(), 13},
+			},
+		},
+		// non-CoreOS variants have no OS partitions on the boot disk
+		{
+			`variant: flatcar
+version: 1.2.0-experimental
+storage:
+  disks:
+    - device: /dev/disk/by-id/coreos-boot-disk
+      partitions:
+        - label: data`,
+			[]Disk{
+				{
+					Device:  BootDisk,
+					SizeMiB: 20480,
+					Partitions: []Partition{
+						{1, "data", 1, 20478, Created},
+					},
+				},
+			},
+			nil,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("check %d", i), func(t *testing.T) {
+			var ts translate.TranslationSet
+			options := common.TranslateBytesOptions{}
+			options.Translations = &ts
+			out, _, err := config.TranslateBytes([]byte(test.in), options)
+			if !assert.NoError(t, err, "translating config") {
+				return
+			}
+			disks, r, err := Check([]byte(test.in), out, ts, sizes)
+			if !assert.NoError(t, err, "checking layout") {
+				return
+			}
+			assert.Equal(t, test.disks, disks, "bad layout")
+			var entries []entry
+			for _, e := range r.Entries {
+				line := int64(0)
+				if e.Marker.StartP != nil {
+					line = e.Marker.StartP.Line
+				}
+				entries = append(entries, entry{e.Kind, e.Message, line})
+			}
+			assert.Equal(t, test.entries, entries, "bad report")
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	disks := []Disk{
+		{
+			Device:  "/dev/vdb",
+			SizeMiB: 10240,
+			Partitions: []Partition{
+				{1, "a", 1, 1024, Created},
+				{2, "", 2048, 1024, Existing},
+			},
+		},
+		{
+			Device:  "/dev/vda",
+			SizeMiB: 10240,
+			Partitions: []Partition{
+				{3, "boot", 129, 384, Existing},
+				{4, "root", 513, 0, Existing},
+				{5, "var", 8192, 1024, Created},
+			},
+		},
+		{
+			Device: "/dev/vdc",
+		},
+	}
+	expected := `/dev/vdb: 10240 MiB
+  NUMBER  LABEL  START (MiB)  SIZE (MiB)  STATUS
+  1       a      1            1024        new
+                 1025         1023        free
+  2       -      2048         1024        existing
+                 3072         7167        free
+
+/dev/vda: 10240 MiB
+  NUMBER  LABEL  START (MiB)  SIZE (MiB)  STATUS
+                 1            128         free
+  3       boot   129          384         existing
+  4       root   513          unknown     existing
+  5       var    8192         1024        new
+                 9216         1023        free
+
+/dev/vdc: size unknown; specify it with --disk-size
+`
+	var buf bytes.Buffer
+	assert.NoError(t, Print(&buf, disks))
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestParseSizes(t *testing.T) {
+	tests := []struct {
+		in  string
+		out map[string]int
+		err string
+	}{
+		{
+			"# comment\n/dev/vda = 20GiB\n\n/dev/vdb=512MiB\n",
+			map[string]int{
+				"/dev/vda": 20480,
+				"/dev/vdb": 512,
+			},
+			"",
+		},
+		{
+			"/dev/vda 20GiB",
+			nil,
+			`line 1: disk size "/dev/vda 20GiB" isn't of the form DEVICE=SIZE`,
+		},
+		{
+			"/dev/vda=1MiB",
+			nil,
+			"line 1: disk size for /dev/vda: too small",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("parse %d", i), func(t *testing.T) {
+			out, err := ParseSizes(test.in)
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.out, out)
+		})
+	}
+}
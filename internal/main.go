@@ -23,7 +23,9 @@ import (
 
 	"github.com/coreos/butane/config"
 	"github.com/coreos/butane/config/common"
+	"github.com/coreos/butane/internal/layout"
 	"github.com/coreos/butane/internal/version"
+	"github.com/coreos/butane/translate"
 )
 
 func fail(format string, args ...interface{}) {
@@ -31,7 +33,77 @@ func fail(format string, args ...interface{}) {
 	os.Exit(1)
 }
 
+// loadVariants registers the custom variants defined in dir, if any.
+func loadVariants(dir string) {
+	if dir == "" {
+		return
+	}
+	defs, err := config.LoadVariantDir(dir)
+	if err != nil {
+		fail("Error loading variants: %v\n", err)
+	}
+	for _, def := range defs {
+		if err := config.RegisterVariant(def); err != nil {
+			fail("Error loading variant %s %s: %v\n", def.Variant, def.Version, err)
+		}
+	}
+}
+
+// readDiskSizes collects the disk sizes for layout checking from
+// --disk-size arguments and a --disk-sizes file.
+func readDiskSizes(specs []string, file string) map[string]int {
+	sizes := map[string]int{}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			fail("failed to read %s: %v\n", file, err)
+		}
+		if sizes, err = layout.ParseSizes(string(data)); err != nil {
+			fail("Error parsing %s: %v\n", file, err)
+		}
+	}
+	for _, spec := range specs {
+		device, size, err := layout.ParseSize(spec)
+		if err != nil {
+			fail("Error parsing --disk-size: %v\n", err)
+		}
+		sizes[device] = size
+	}
+	return sizes
+}
+
+// isSubcommand reports whether the first argument selects the subcommand
+// name.  An input file with the same name is still translated, as it was
+// before the subcommand existed.
+func isSubcommand(name string) bool {
+	if len(os.Args) < 2 || os.Args[1] != name {
+		return false
+	}
+	_, err := os.Stat(name)
+	return err != nil
+}
+
 func main() {
+	if isSubcommand("diff") {
+		diffMain(os.Args[2:])
+		return
+	}
+	if isSubcommand("verify-provenance") {
+		verifyProvenanceMain(os.Args[2:])
+		return
+	}
+	if isSubcommand("import-machineconfig") {
+		importMachineConfigMain(os.Args[2:])
+		return
+	}
+
+	// flattening uses the same options as translation
+	flatten := false
+	if isSubcommand("flatten") {
+		flatten = true
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
 	var (
 		input       string
 		output      string
@@ -39,8 +111,15 @@ func main() {
 		strict      bool
 		helpFlag    bool
 		versionFlag bool
+		provenance  bool
+		manifests   string
+		variantDir  string
+		diskSizes   []string
+		sizesFile   string
+		showLayout  bool
 	)
 	options := common.TranslateBytesOptions{}
+	options.Flatten = flatten
 	pflag.BoolVarP(&helpFlag, "help", "h", false, "show usage and exit")
 	pflag.BoolVarP(&versionFlag, "version", "V", false, "print the version and exit")
 	pflag.BoolVarP(&options.DebugPrintTranslations, "debug", "D", false, "log translations")
@@ -49,26 +128,42 @@ func main() {
 	pflag.BoolVarP(&strict, "strict", "s", false, "fail on any warning")
 	pflag.BoolVarP(&options.Pretty, "pretty", "p", false, "output formatted json")
 	pflag.BoolVarP(&options.Raw, "raw", "r", false, "never wrap in a MachineConfig; force Ignition output")
+	pflag.BoolVar(&options.Canonical, "canonical", false, "sort unordered lists and pretty-print, for stable diffs")
+	pflag.IntVar(&options.NoCompressUnder, "no-compress-under", 0, "keep text resources smaller than this many bytes uncompressed and readable")
+	pflag.BoolVar(&provenance, "provenance", false, "record the Butane version and hashes of the inputs in the output")
 	pflag.StringVar(&input, "input", "", "read from input file instead of stdin")
 	pflag.Lookup("input").Deprecated = "specify filename directly on command line"
 	pflag.Lookup("input").Hidden = true
 	pflag.StringVarP(&output, "output", "o", "", "write to output file instead of stdout")
 	pflag.StringVarP(&options.FilesDir, "files-dir", "d", "", "allow embedding local files from this directory")
+	pflag.StringVar(&variantDir, "variant-dir", "", "load custom variant definitions from this directory")
+	pflag.StringVar(&options.PluginPath, "plugin-path", os.Getenv("BUTANE_PLUGIN_PATH"), "search these directories for translator plugins for unknown variants")
+	pflag.StringArrayVar(&diskSizes, "disk-size", nil, "check the partition layout of a disk of this size, as DEVICE=SIZE (repeatable)")
+	pflag.StringVar(&sizesFile, "disk-sizes", "", "check the partition layouts of disks with DEVICE=SIZE lines in this file")
+	pflag.BoolVar(&showLayout, "show-layout", false, "print the simulated partition layouts")
+	pflag.StringVar(&manifests, "manifests-dir", "", "write MachineConfigs and related objects into this openshift-install manifests directory")
 
 	pflag.Usage = func() {
 		fmt.Fprintf(pflag.CommandLine.Output(), "Usage: %s [options] [input-file]\n", os.Args[0])
+		fmt.Fprintf(pflag.CommandLine.Output(), "       %s --manifests-dir dir [options] input-file...\n", os.Args[0])
+		fmt.Fprintf(pflag.CommandLine.Output(), "       %s flatten [options] [input-file]\n", os.Args[0])
+		fmt.Fprintf(pflag.CommandLine.Output(), "       %s diff [options] old-file new-file\n", os.Args[0])
+		fmt.Fprintf(pflag.CommandLine.Output(), "       %s verify-provenance [options] input-file output-file\n", os.Args[0])
+		fmt.Fprintf(pflag.CommandLine.Output(), "       %s import-machineconfig [options] input-file\n", os.Args[0])
 		fmt.Fprintf(pflag.CommandLine.Output(), "Options:\n")
 		pflag.PrintDefaults()
 	}
 	pflag.Parse()
 
 	args := pflag.Args()
-	if len(args) == 1 && input == "" {
-		input = args[0]
-	} else if len(args) > 0 {
+	// only --manifests-dir accepts multiple inputs
+	if (input != "" && len(args) > 0) || (manifests == "" && len(args) > 1) {
 		pflag.Usage()
 		os.Exit(2)
 	}
+	if len(args) == 1 {
+		input = args[0]
+	}
 
 	if helpFlag {
 		pflag.CommandLine.SetOutput(os.Stdout)
@@ -81,6 +176,34 @@ func main() {
 		os.Exit(0)
 	}
 
+	loadVariants(variantDir)
+
+	if provenance {
+		options.Provenance = &common.Provenance{
+			ButaneVersion: version.Raw,
+		}
+	}
+
+	checkLayout := len(diskSizes) > 0 || sizesFile != "" || showLayout
+	var sizes map[string]int
+	if checkLayout {
+		sizes = readDiskSizes(diskSizes, sizesFile)
+	}
+
+	if manifests != "" {
+		if output != "" {
+			fail("--manifests-dir and --output are mutually exclusive\n")
+		}
+		if checkLayout {
+			fail("--manifests-dir can't be used with --disk-size, --disk-sizes, or --show-layout\n")
+		}
+		if len(args) <= 1 {
+			args = []string{input}
+		}
+		manifestsMain(manifests, args, options, strict, check)
+		return
+	}
+
 	infile := os.Stdin
 	if input != "" {
 		var err error
@@ -96,11 +219,31 @@ func main() {
 		fail("failed to read %s: %v\n", infile.Name(), err)
 	}
 
+	var translations translate.TranslationSet
+	if checkLayout {
+		options.Translations = &translations
+	}
 	dataOut, r, err := config.TranslateBytes(dataIn, options)
 	fmt.Fprintf(os.Stderr, "%s", r.String())
 	if err != nil {
 		fail("Error translating config: %v\n", err)
 	}
+	if checkLayout {
+		disks, lr, err := layout.Check(dataIn, dataOut, translations, sizes)
+		if err != nil {
+			fail("Error checking partition layout: %v\n", err)
+		}
+		fmt.Fprintf(os.Stderr, "%s", lr.String())
+		if showLayout {
+			if err := layout.Print(os.Stderr, disks); err != nil {
+				fail("Failed to print partition layout: %v\n", err)
+			}
+		}
+		if lr.IsFatal() {
+			fail("Partition layout check failed\n")
+		}
+		r.Merge(lr)
+	}
 	if strict && len(r.Entries) > 0 {
 		fail("Config produced warnings and --strict was specified\n")
 	}
@@ -0,0 +1,79 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/coreos/butane/config"
+	"github.com/coreos/butane/config/common"
+	cutil "github.com/coreos/butane/config/util"
+)
+
+// manifestsMain translates each input and writes the resulting objects
+// into an openshift-install manifests directory.  An empty input name
+// reads from stdin.
+func manifestsMain(dir string, inputs []string, options common.TranslateBytesOptions, strict, check bool) {
+	var manifests []cutil.Manifest
+	sources := map[string]string{}
+	for _, input := range inputs {
+		name := input
+		var dataIn []byte
+		var err error
+		if input == "" {
+			name = "stdin"
+			dataIn, err = io.ReadAll(os.Stdin)
+		} else {
+			dataIn, err = os.ReadFile(input)
+		}
+		if err != nil {
+			fail("failed to read %s: %v\n", name, err)
+		}
+
+		dataOut, r, err := config.TranslateBytes(dataIn, options)
+		if len(r.Entries) > 0 {
+			fmt.Fprintf(os.Stderr, "%s:\n%s", name, r.String())
+		}
+		if err != nil {
+			fail("Error translating %s: %v\n", name, err)
+		}
+		if strict && len(r.Entries) > 0 {
+			fail("Config produced warnings and --strict was specified\n")
+		}
+		if options.Raw {
+			fail("Error translating %s: --raw output can't be written to a manifests directory\n", name)
+		}
+		objects, err := cutil.SplitManifests(dataOut)
+		if err != nil {
+			fail("Error translating %s: %v\n", name, err)
+		}
+		for _, m := range objects {
+			if other, ok := sources[m.Path()]; ok {
+				fail("%s and %s both generate %s\n", other, name, m.Path())
+			}
+			sources[m.Path()] = name
+		}
+		manifests = append(manifests, objects...)
+	}
+	if check {
+		return
+	}
+
+	if err := cutil.WriteManifests(dir, manifests); err != nil {
+		fail("Error writing manifests: %v\n", err)
+	}
+}
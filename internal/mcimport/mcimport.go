@@ -0,0 +1,437 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+// Package mcimport converts MachineConfigs to openshift Butane configs.
+package mcimport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/coreos/butane/config"
+	"github.com/coreos/butane/config/common"
+	cutil "github.com/coreos/butane/config/util"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	"github.com/coreos/vcontext/tree"
+	vyaml "github.com/coreos/vcontext/yaml"
+	"github.com/vincent-petithory/dataurl"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrNotMachineConfig = errors.New("input is not a MachineConfig")
+	ErrFieldDropped     = errors.New("field can't be expressed in a Butane config; dropped")
+	ErrNoSpecVersion    = errors.New("no openshift spec version can express the MachineConfig")
+)
+
+// MachineConfig spec fields and their counterparts in the openshift
+// section, in output order
+var openshiftFields = []struct {
+	mc     string
+	butane string
+}{
+	{"kernelType", "kernel_type"},
+	{"kernelArguments", "kernel_arguments"},
+	{"extensions", "extensions"},
+	{"fips", "fips"},
+	{"osImageURL", "os_image_url"},
+	{"baseOSExtensionsContainerImage", "extensions_image"},
+}
+
+// Ignition config sections, in output order.  Kernel arguments are moved
+// to the openshift section.
+var configSections = []string{"ignition", "passwd", "storage", "systemd"}
+
+// Import converts the MachineConfig in input, in YAML or JSON, to an
+// openshift Butane config.  The config uses the oldest spec version that
+// can express every field present.  Import returns the config and a
+// report, against the input MachineConfig, of the fields Butane would
+// warn about or reject or that were dropped.  If every spec version
+// rejects a field, it returns ErrNoSpecVersion.
+func Import(input []byte) ([]byte, report.Report, error) {
+	var mc map[string]interface{}
+	if err := yaml.Unmarshal(input, &mc); err != nil {
+		return nil, report.Report{}, err
+	}
+	if mc["kind"] != "MachineConfig" {
+		return nil, report.Report{}, ErrNotMachineConfig
+	}
+
+	var r report.Report
+	// Butane config paths and the MachineConfig paths they came from
+	sources := sourceMap{}
+	doc := newMapping()
+	doc.add("variant", "openshift")
+	// placeholder; filled in for each candidate version
+	doc.add("version", "")
+
+	metadata := newMapping()
+	meta, _ := mc["metadata"].(map[string]interface{})
+	for _, key := range sortedKeys(meta) {
+		switch key {
+		case "name", "labels":
+		default:
+			r.AddOnWarn(path.New("json", "metadata", key), ErrFieldDropped)
+		}
+	}
+	metadata.addIfSet("name", meta["name"])
+	metadata.addIfSet("labels", meta["labels"])
+	doc.add("metadata", metadata.node)
+	sources.add(path.New("yaml", "metadata"), path.New("json", "metadata"))
+	sources.add(path.New("yaml", "metadata", "name"), path.New("json", "metadata", "name"))
+	sources.add(path.New("yaml", "metadata", "labels"), path.New("json", "metadata", "labels"))
+
+	spec, _ := mc["spec"].(map[string]interface{})
+	cfg, _ := spec["config"].(map[string]interface{})
+	handled := map[string]bool{"config": true}
+	for _, section := range configSections {
+		value, ok := cfg[section]
+		if !ok {
+			continue
+		}
+		if section == "ignition" {
+			ign, _ := value.(map[string]interface{})
+			delete(ign, "version")
+			if len(ign) == 0 {
+				continue
+			}
+		}
+		doc.add(cutil.Snake(section), convert(value, []string{section}, path.New("yaml", cutil.Snake(section)), path.New("json", "spec", "config", section), sources))
+	}
+	for _, key := range sortedKeys(cfg) {
+		if !contains(configSections, key) && key != "kernelArguments" {
+			r.AddOnWarn(path.New("json", "spec", "config", key), ErrFieldDropped)
+		}
+	}
+
+	// Ignition kernel arguments can't be used in a MachineConfig, but
+	// the MCO applies the ones that should exist as if they were in
+	// spec.kernelArguments
+	kargs, _ := spec["kernelArguments"].([]interface{})
+	kargsSources := make([]path.ContextPath, len(kargs))
+	for i := range kargs {
+		kargsSources[i] = path.New("json", "spec", "kernelArguments", i)
+	}
+	ignKargs, _ := cfg["kernelArguments"].(map[string]interface{})
+	for _, key := range sortedKeys(ignKargs) {
+		switch key {
+		case "shouldExist":
+			list, _ := ignKargs[key].([]interface{})
+			for i, karg := range list {
+				kargs = append(kargs, karg)
+				kargsSources = append(kargsSources, path.New("json", "spec", "config", "kernelArguments", key, i))
+			}
+		default:
+			r.AddOnWarn(path.New("json", "spec", "config", "kernelArguments", key), ErrFieldDropped)
+		}
+	}
+
+	openshift := newMapping()
+	for _, field := range openshiftFields {
+		value := spec[field.mc]
+		if field.mc == "kernelArguments" && len(kargs) > 0 {
+			value = kargs
+			for i, source := range kargsSources {
+				sources.add(path.New("yaml", "openshift", field.butane, i), source)
+			}
+		}
+		openshift.addIfSet(field.butane, value)
+		sources.add(path.New("yaml", "openshift", field.butane), path.New("json", "spec", field.mc))
+		handled[field.mc] = true
+	}
+	for _, key := range sortedKeys(spec) {
+		if !handled[key] {
+			r.AddOnWarn(path.New("json", "spec", key), ErrFieldDropped)
+		}
+	}
+	if len(openshift.node.Content) > 0 {
+		doc.add("openshift", openshift.node)
+	}
+	formatModes(doc.node)
+
+	// pick the oldest spec version that translates cleanly, or else the
+	// oldest one with the fewest warnings.  If none translates without
+	// errors, the problems are fields no version can express, and the
+	// newest stable version explains them best.
+	var chosen, newest *candidate
+	for _, version := range config.Versions("openshift") {
+		c, err := translate(doc, version)
+		if err != nil {
+			return nil, r, err
+		}
+		if c.report.IsFatal() {
+			if version.PreRelease == "" || newest == nil {
+				newest = &c
+			}
+			continue
+		}
+		if chosen == nil || len(c.report.Entries) < len(chosen.report.Entries) {
+			chosen = &c
+		}
+		if len(c.report.Entries) == 0 {
+			break
+		}
+	}
+	var importErr error
+	if chosen == nil {
+		chosen = newest
+		importErr = ErrNoSpecVersion
+	}
+	for _, entry := range chosen.report.Entries {
+		// the marker refers to the generated config
+		entry.Context = sources.lookup(entry.Context)
+		entry.Marker = tree.Marker{}
+		r.Entries = append(r.Entries, entry)
+	}
+	if contextTree, err := vyaml.UnmarshalToContext(input); err == nil {
+		r.Correlate(contextTree)
+	}
+	if importErr != nil {
+		return nil, r, importErr
+	}
+	return chosen.config, r, nil
+}
+
+// sourceMap maps paths in the generated Butane config to the paths in the
+// MachineConfig that produced them.
+type sourceMap map[string]path.ContextPath
+
+func (m sourceMap) add(to, from path.ContextPath) {
+	// from shares its backing array with its siblings
+	m[to.String()] = from.Copy()
+}
+
+// lookup returns the MachineConfig path that produced p, falling back to
+// the closest ancestor with a known source.
+func (m sourceMap) lookup(p path.ContextPath) path.ContextPath {
+	for p.Len() > 0 {
+		if from, ok := m[p.String()]; ok {
+			return from
+		}
+		p = p.Pop()
+	}
+	return path.New("json")
+}
+
+type candidate struct {
+	config []byte
+	report report.Report
+}
+
+// translate renders doc with the specified spec version and translates
+// it to find the fields the version can't express.
+func translate(doc mapping, version semver.Version) (candidate, error) {
+	doc.set("version", version.String())
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc.node); err != nil {
+		return candidate{}, err
+	}
+	if err := encoder.Close(); err != nil {
+		return candidate{}, err
+	}
+	_, r, err := config.TranslateBytes(buf.Bytes(), common.TranslateBytesOptions{})
+	if err != nil && !errors.Is(err, common.ErrInvalidSourceConfig) && !errors.Is(err, common.ErrInvalidGeneratedConfig) {
+		return candidate{}, err
+	}
+	return candidate{
+		config: buf.Bytes(),
+		report: r,
+	}, nil
+}
+
+// convert converts an Ignition config value at path p to its Butane
+// equivalent, with snake_case keys and file contents decoded where
+// possible.  to and from are the paths of the value in the Butane config
+// and the MachineConfig, and are recorded in sources.
+func convert(value interface{}, p []string, to, from path.ContextPath, sources sourceMap) interface{} {
+	sources.add(to, from)
+	switch v := value.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			ret[cutil.Snake(key)] = convert(child, append(p, key), to.Append(cutil.Snake(key)), from.Append(key), sources)
+		}
+		if isFileResource(p) && decodeResource(ret) {
+			sources.add(to.Append("inline"), from.Append("source"))
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, child := range v {
+			ret[i] = convert(child, p, to.Append(i), from.Append(i), sources)
+		}
+		return ret
+	default:
+		return value
+	}
+}
+
+// isFileResource returns true if p is the path of a file's contents or
+// one of its appended resources.
+func isFileResource(p []string) bool {
+	s := strings.Join(p, ".")
+	return s == "storage.files.contents" || s == "storage.files.append"
+}
+
+// decodeResource replaces a data URL in the resource res with inline
+// contents if they're text, and reports whether it did.  Resources with a
+// verification hash are left alone, since the hash covers the encoded
+// form.
+func decodeResource(res map[string]interface{}) bool {
+	source, ok := res["source"].(string)
+	if !ok || !strings.HasPrefix(source, "data:") {
+		return false
+	}
+	if verification, ok := res["verification"].(map[string]interface{}); ok && verification["hash"] != nil {
+		return false
+	}
+	url, err := dataurl.DecodeString(source)
+	if err != nil {
+		return false
+	}
+	contents := url.Data
+	compression, _ := res["compression"].(string)
+	switch compression {
+	case "":
+	case "gzip":
+		reader, err := gzip.NewReader(bytes.NewReader(contents))
+		if err != nil {
+			return false
+		}
+		if contents, err = io.ReadAll(reader); err != nil {
+			return false
+		}
+	default:
+		return false
+	}
+	if !utf8.Valid(contents) {
+		return false
+	}
+	delete(res, "source")
+	delete(res, "compression")
+	delete(res, "verification")
+	res["inline"] = string(contents)
+	return true
+}
+
+// formatModes rewrites the file and directory modes in node in octal.
+func formatModes(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "mode" && value.Kind == yaml.ScalarNode && value.Tag == "!!int" {
+				var mode int
+				if err := value.Decode(&mode); err == nil {
+					value.Value = fmt.Sprintf("0%o", mode)
+				}
+			}
+		}
+	}
+	for _, child := range node.Content {
+		formatModes(child)
+	}
+}
+
+// mapping is a YAML mapping node with keys in insertion order.
+type mapping struct {
+	node *yaml.Node
+}
+
+func newMapping() mapping {
+	return mapping{&yaml.Node{Kind: yaml.MappingNode}}
+}
+
+func (m mapping) add(key string, value interface{}) {
+	valueNode, ok := value.(*yaml.Node)
+	if !ok {
+		valueNode = encode(value)
+	}
+	m.node.Content = append(m.node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, valueNode)
+}
+
+func (m mapping) addIfSet(key string, value interface{}) {
+	if value != nil {
+		m.add(key, value)
+	}
+}
+
+func (m mapping) set(key, value string) {
+	for i := 0; i+1 < len(m.node.Content); i += 2 {
+		if m.node.Content[i].Value == key {
+			m.node.Content[i+1] = encode(value)
+		}
+	}
+}
+
+// keys listed before the others in a mapping, since they identify the
+// object
+var leadingKeys = []string{"path", "name", "device", "label"}
+
+// encode converts value, which came from unmarshaled YAML, to a node.
+// Mapping keys are sorted, with identifying keys first.
+func encode(value interface{}) *yaml.Node {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := newMapping()
+		for _, key := range leadingKeys {
+			m.addIfSet(key, v[key])
+		}
+		for _, key := range sortedKeys(v) {
+			if !contains(leadingKeys, key) {
+				m.add(key, v[key])
+			}
+		}
+		return m.node
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, child := range v {
+			node.Content = append(node.Content, encode(child))
+		}
+		return node
+	default:
+		node := &yaml.Node{}
+		// scalars from unmarshaled YAML always encode
+		_ = node.Encode(value)
+		return node
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
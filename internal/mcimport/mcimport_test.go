@@ -0,0 +1,202 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package mcimport
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/coreos/butane/config/common"
+
+	"github.com/coreos/vcontext/path"
+	"github.com/coreos/vcontext/report"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestImport tests converting MachineConfigs to Butane configs.
+func TestImport(t *testing.T) {
+	tests := []struct {
+		in     string
+		out    string
+		report []report.Entry
+		err    error
+	}{
+		// idiomatic output with the oldest spec version
+		{
+			in: `apiVersion: machineconfiguration.openshift.io/v1
+kind: MachineConfig
+metadata:
+  name: 99-worker-custom
+  labels:
+    machineconfiguration.openshift.io/role: worker
+  annotations:
+    example.com/owner: me
+spec:
+  fips: true
+  kernelArguments:
+    - loglevel=7
+  config:
+    ignition:
+      version: 3.2.0
+    storage:
+      files:
+        - path: /etc/motd
+          mode: 420
+          contents:
+            source: data:,hello%20world%0A
+        - path: /etc/hello
+          contents:
+            compression: gzip
+            source: data:;base64,H4sIAAAAAAAAA8tIzcnJBwCGphA2BQAAAA==
+        - path: /etc/binary
+          contents:
+            source: data:;base64,/w==
+    systemd:
+      units:
+        - name: example.service
+          enabled: true
+          contents: |
+            [Install]
+            WantedBy=multi-user.target
+`,
+			out: `variant: openshift
+version: 4.8.0
+metadata:
+  name: 99-worker-custom
+  labels:
+    machineconfiguration.openshift.io/role: worker
+storage:
+  files:
+    - path: /etc/motd
+      contents:
+        inline: |
+          hello world
+      mode: 0644
+    - path: /etc/hello
+      contents:
+        inline: hello
+    - path: /etc/binary
+      contents:
+        source: data:;base64,/w==
+systemd:
+  units:
+    - name: example.service
+      contents: |
+        [Install]
+        WantedBy=multi-user.target
+      enabled: true
+openshift:
+  kernel_arguments:
+    - loglevel=7
+  fips: true
+`,
+			report: []report.Entry{
+				{
+					Kind:    report.Warn,
+					Message: ErrFieldDropped.Error(),
+					Context: path.New("json", "metadata", "annotations"),
+				},
+			},
+		},
+		// fields only supported by the experimental spec
+		{
+			in: `{"kind":"MachineConfig","metadata":{"name":"image","labels":{"machineconfiguration.openshift.io/role":"worker"}},"spec":{"osImageURL":"quay.io/example/os@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}}`,
+			out: `variant: openshift
+version: 4.23.0-experimental
+metadata:
+  name: image
+  labels:
+    machineconfiguration.openshift.io/role: worker
+openshift:
+  os_image_url: quay.io/example/os@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+`,
+		},
+		// Ignition kernel arguments move to the openshift section
+		{
+			in: `{"kind":"MachineConfig","metadata":{"name":"kargs","labels":{"machineconfiguration.openshift.io/role":"worker"}},"spec":{"kernelArguments":["b"],"config":{"ignition":{"version":"3.2.0"},"kernelArguments":{"shouldExist":["a"],"shouldNotExist":["c"]}}}}`,
+			out: `variant: openshift
+version: 4.8.0
+metadata:
+  name: kargs
+  labels:
+    machineconfiguration.openshift.io/role: worker
+openshift:
+  kernel_arguments:
+    - b
+    - a
+`,
+			report: []report.Entry{
+				{
+					Kind:    report.Warn,
+					Message: ErrFieldDropped.Error(),
+					Context: path.New("json", "spec", "config", "kernelArguments", "shouldNotExist"),
+				},
+			},
+		},
+		// fields rejected by every spec version, reported by the newest
+		// stable one against the MachineConfig
+		{
+			in: `{"kind":"MachineConfig","metadata":{"name":"users","labels":{"machineconfiguration.openshift.io/role":"worker"}},"spec":{"config":{"ignition":{"version":"3.2.0"},"passwd":{"users":[{"name":"bob"}]}}}}`,
+			report: []report.Entry{
+				{
+					Kind:    report.Error,
+					Message: "users other than \"core\" are not supported in this spec version",
+					Context: path.New("json", "spec", "config", "passwd", "users", 0, "name"),
+				},
+			},
+			err: ErrNoSpecVersion,
+		},
+		// warnings from every spec version keep the oldest one
+		{
+			in: `{"kind":"MachineConfig","metadata":{"name":"mode","labels":{"machineconfiguration.openshift.io/role":"worker"}},"spec":{"config":{"ignition":{"version":"3.2.0"},"storage":{"files":[{"path":"/etc/mode","mode":444}]}}}}`,
+			out: `variant: openshift
+version: 4.8.0
+metadata:
+  name: mode
+  labels:
+    machineconfiguration.openshift.io/role: worker
+storage:
+  files:
+    - path: /etc/mode
+      mode: 0674
+`,
+			report: []report.Entry{
+				{
+					Kind:    report.Warn,
+					Message: common.ErrDecimalMode.Error(),
+					Context: path.New("json", "spec", "config", "storage", "files", 0, "mode"),
+				},
+			},
+		},
+		// not a MachineConfig
+		{
+			in:  `{"kind":"KubeletConfig"}`,
+			err: ErrNotMachineConfig,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("import %d", i), func(t *testing.T) {
+			out, r, err := Import([]byte(test.in))
+			assert.Equal(t, test.err, err, "bad error")
+			assert.Equal(t, test.out, string(out), "bad output")
+			// drop markers, which depend on the input layout
+			for i := range r.Entries {
+				r.Entries[i].Marker = test.report[i].Marker
+			}
+			assert.Equal(t, test.report, r.Entries, "bad report")
+		})
+	}
+}
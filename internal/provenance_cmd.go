@@ -0,0 +1,75 @@
+// Copyright 2026 Red Hat, Inc
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.)
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	cutil "github.com/coreos/butane/config/util"
+	"github.com/coreos/butane/internal/version"
+)
+
+func verifyProvenanceMain(args []string) {
+	var helpFlag bool
+	var filesDir string
+	flags := pflag.NewFlagSet("verify-provenance", pflag.ExitOnError)
+	flags.BoolVarP(&helpFlag, "help", "h", false, "show usage and exit")
+	flags.StringVarP(&filesDir, "files-dir", "d", "", "directory containing the local files referenced by the input")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s verify-provenance [options] input-file output-file\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Options:\n")
+		flags.PrintDefaults()
+	}
+	// ExitOnError
+	_ = flags.Parse(args)
+
+	if helpFlag {
+		flags.SetOutput(os.Stdout)
+		flags.Usage()
+		os.Exit(0)
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	input, output := flags.Arg(0), flags.Arg(1)
+	source, err := os.ReadFile(input)
+	if err != nil {
+		fail("failed to read %s: %v\n", input, err)
+	}
+	generated, err := os.ReadFile(output)
+	if err != nil {
+		fail("failed to read %s: %v\n", output, err)
+	}
+	prov, err := cutil.ReadProvenance(generated)
+	if err != nil {
+		fail("Error reading provenance from %s: %v\n", output, err)
+	}
+
+	if prov.ButaneVersion != version.Raw {
+		fmt.Fprintf(os.Stderr, "note: %s was generated by Butane %s\n", output, prov.ButaneVersion)
+	}
+	problems := cutil.VerifyProvenance(prov, source, filesDir)
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		fail("%s doesn't match its recorded inputs\n", output)
+	}
+}